@@ -0,0 +1,151 @@
+package forgotpass
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"api/business/auth/otp"
+	"api/business/auth/password"
+	"api/business/auth/session"
+	"api/internal/jwt"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ForgotPasswordResponse struct {
+	SessionToken string `json:"session_token"`
+}
+
+type ResetPasswordRequest struct {
+	SessionToken string `json:"session_token" binding:"required"`
+	Code         string `json:"code" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+}
+
+// ForgotPassword gửi mã OTP đặt lại mật khẩu tới email của tài khoản
+func ForgotPassword() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][forgotpass]")
+
+	return func(c *gin.Context) {
+		var req ForgotPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		res, err := doForgotPassword(c, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to send reset password code")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+// doForgotPassword luôn trả về session token, kể cả khi email không có tài khoản, để không lộ email nào đã đăng ký.
+// Mã OTP chỉ được gửi khi tài khoản tồn tại
+func doForgotPassword(c *gin.Context, req ForgotPasswordRequest) (*ForgotPasswordResponse, error) {
+	ctx := c.Request.Context()
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	target := otp.Target{Email: email}
+
+	user, err := usercol.FindWithEmail(ctx, email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if err == nil && !user.IsDelete {
+		target = otp.Target{
+			Email:  user.Email,
+			Name:   user.FullName,
+			UserId: user.GetIDString(),
+		}
+	}
+
+	sessionToken, err := otp.SendQuietly(ctx, target, jwt.SessionTypeForgotPass, target.UserId != "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForgotPasswordResponse{SessionToken: sessionToken}, nil
+}
+
+// ResetPassword xác thực mã OTP và đặt mật khẩu mới, thu hồi toàn bộ phiên đăng nhập
+func ResetPassword() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][forgotpass][reset]")
+
+	return func(c *gin.Context) {
+		var req ResetPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if err := doResetPassword(c, req); err != nil {
+			logger.Err(err).Msg("failed to reset password")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
+
+func doResetPassword(c *gin.Context, req ResetPasswordRequest) error {
+	ctx := c.Request.Context()
+
+	hashed, err := password.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+
+	record, err := otp.Verify(ctx, req.SessionToken, req.Code, jwt.SessionTypeForgotPass)
+	if err != nil {
+		return err
+	}
+
+	// OTP của email không có tài khoản, hoặc tài khoản đã bị xoá sau khi gửi mã: trả lỗi như mã sai
+	if record.UserId == "" {
+		return errors.New("OTP_INVALID")
+	}
+
+	user, err := usercol.FindWithUserID(ctx, record.UserId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errors.New("OTP_INVALID")
+		}
+		return err
+	}
+
+	if user.IsDelete {
+		return errors.New("OTP_INVALID")
+	}
+
+	user.Password = hashed
+	user.IsSetPassword = true
+	if _, err = usercol.Update(ctx, user); err != nil {
+		return err
+	}
+
+	_, err = session.RevokeAllExcept(ctx, user.GetIDString(), "", "password_reset")
+	return err
+}
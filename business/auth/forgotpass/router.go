@@ -0,0 +1,8 @@
+package forgotpass
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.POST("", ForgotPassword())      // POST /auth/forgot-password - Gửi OTP đặt lại mật khẩu
+	r.POST("/reset", ResetPassword()) // POST /auth/forgot-password/reset - Đặt lại mật khẩu
+}
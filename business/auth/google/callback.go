@@ -3,35 +3,27 @@ package google
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
-	"os"
-	"time"
+	"strings"
 
-	"api/business/auth/login"
-	"api/internal/jwt"
-	bsonutil "api/internal/mongodb/utils"
+	"api/business/auth/oauth"
 	"api/internal/plog"
 	"api/internal/response"
-	"api/internal/timer"
-	"api/schema/usercol"
-	"api/schema/userdevicecol"
-	"api/schema/usersessioncol"
 	"api/services/oauth2"
 	"api/services/oauth2/google"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	xoauth2 "golang.org/x/oauth2"
 )
 
 type googleUser struct {
-	ID      string `json:"id"`
-	Email   string `json:"email"`
-	Name    string `json:"name"`
-	Picture string `json:"picture"`
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	VerifiedEmail bool   `json:"verified_email"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
 }
 
 func Callback() gin.HandlerFunc {
@@ -41,71 +33,41 @@ func Callback() gin.HandlerFunc {
 		ctx := c.Request.Context()
 
 		// Validate OAuth state & code
-		code, deviceID, deviceName, browserName, platform, ok := validateOAuthCallbackParams(c, logger)
+		code, state, ok := validateOAuthCallbackParams(c, logger)
 		if !ok {
 			return
 		}
 
 		// Exchange code for token & get user info
-		userInfo, err := getUserInfoFromGoogle(ctx, code, logger)
+		userInfo, err := getUserInfoFromGoogle(ctx, code, state.CodeVerifier, logger)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse(err.Error()))
 			c.Abort()
 			return
 		}
 
-		// Find or create user
-		user, err := findOrCreateUser(ctx, userInfo, logger)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to get or create user"))
-			c.Abort()
-			return
-		}
-
-		// Create session
-		_, err = createUserSession(c, user.ID.(primitive.ObjectID), deviceName, browserName, platform, logger)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, response.ErrorResponse("session creation failed"))
-			c.Abort()
-			return
-		}
-
-		// Update device info
-		err = updateDeviceInfo(ctx, c, user.GetIDString(), deviceID, deviceName, platform, logger)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, response.ErrorResponse("device update failed"))
-			c.Abort()
-			return
-		}
-
-		// Generate JWT & Redirect
-		accessToken, _ := jwt.GenerateJWTToken(
-			os.Getenv("KEY_API_KEY"),
-			user.GetIDString(),
-			"dipnet",
-			"fanxipan",
-			login.JWT_LIFETIME,
-		)
-
-		redirectURL := fmt.Sprintf("%s?access_token=%s", google.OAuthConfig.ClientRedirectURL, accessToken)
-
-		c.Redirect(http.StatusTemporaryRedirect, redirectURL)
+		// Log in, or link the identity when the flow was started from /profile
+		oauth.Complete(c, state, &oauth2.Identity{
+			Provider:      oauth2.Google,
+			Subject:       userInfo.ID,
+			Email:         strings.ToLower(userInfo.Email),
+			EmailVerified: userInfo.VerifiedEmail,
+			Name:          userInfo.Name,
+			Picture:       userInfo.Picture,
+		}, logger)
 	}
 }
 
-func validateOAuthCallbackParams(c *gin.Context, _ plog.Logger) (
-	code,
-	deviceID,
-	deviceName,
-	browserName,
-	platform string,
-	ok bool,
-) {
-	state := c.Query("state")
-	if state != google.OAuthConfig.OAuthStateString {
+func validateOAuthCallbackParams(c *gin.Context, logger plog.Logger) (code string, state *oauth.State, ok bool) {
+	// state is single use: consumed even if the rest of the callback fails
+	state, err := oauth.ConsumeState(c.Request.Context(), c.Query("state"))
+	if err != nil || state.Provider != oauth2.Google.String() {
+		if err != nil {
+			logger.Err(err).Msg("consume oauth state failed")
+		}
 		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid state"))
 		c.Abort()
-		return
+		return "", nil, false
 	}
 
 	code = c.Query("code")
@@ -117,19 +79,14 @@ func validateOAuthCallbackParams(c *gin.Context, _ plog.Logger) (
 		}
 		c.JSON(http.StatusBadRequest, err)
 		c.Abort()
-		return
+		return "", nil, false
 	}
 
-	deviceName = c.DefaultQuery("device_name", "Unknown")
-	browserName = c.DefaultQuery("browser_name", "Unknown")
-	platform = c.DefaultQuery("platform", "Unknown")
-	deviceID = c.Query("device_id")
-
-	return code, deviceID, deviceName, browserName, platform, true
+	return code, state, true
 }
 
-func getUserInfoFromGoogle(ctx context.Context, code string, logger plog.Logger) (*googleUser, error) {
-	token, err := google.OAuthConfig.Exchange(ctx, code)
+func getUserInfoFromGoogle(ctx context.Context, code, codeVerifier string, logger plog.Logger) (*googleUser, error) {
+	token, err := google.OAuthConfig.Exchange(ctx, code, xoauth2.VerifierOption(codeVerifier))
 	if err != nil {
 		logger.Err(err).Msg("token exchange error")
 		return nil, errors.New("failed to exchange token")
@@ -142,127 +99,27 @@ func getUserInfoFromGoogle(ctx context.Context, code string, logger plog.Logger)
 		return nil, errors.New("failed to get user info")
 	}
 
+	defer resp.Body.Close()
+
+	// error bodies (expired or revoked token) must not be decoded as an empty user
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logger.Error().Msgf("user info returned status %d", resp.StatusCode)
+		return nil, errors.New("failed to get user info")
+	}
+
 	var userInfo googleUser
 	if err = json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
 		logger.Err(err).Msg("failed to parse user info")
 		return nil, errors.New("failed to parse user info")
 	}
 
-	_ = resp.Body.Close()
-
-	return &userInfo, nil
-}
-
-func findOrCreateUser(ctx context.Context, info *googleUser, logger plog.Logger) (*usercol.User, error) {
-	filter := bsonutil.BsonAdd(nil, "email", info.Email)
-
-	user, err := usercol.FindWithCondition(ctx, filter, nil)
-	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		logger.Err(err).Msg("database error")
-		return nil, err
-	}
-
-	if user == nil {
-		newUser := &usercol.User{
-			Email:    info.Email,
-			FullName: info.Name,
-			Avatar:   info.Picture,
-			OAuthProvider: &usercol.OAuthProvider{
-				ProviderID:   info.ID,
-				ProviderName: oauth2.Google,
-			},
-			Role:          usercol.RoleEmployee,
-			IsVerifyEmail: true,
-			IsVerifyPhone: true,
-			IsSetPassword: false,
-			IsDelete:      false,
-			DeletedAt:     time.Time{},
-			CreatedAt:     time.Time{},
-			UpdatedAt:     time.Time{},
-		}
-		_, err = usercol.Create(ctx, newUser)
-		if err != nil {
-			logger.Err(err).Msg("failed to create user")
-			return nil, err
-		}
-		user = newUser
-
-	} else {
-		// Check if user is deleted
-		if user.IsDelete {
-			return nil, errors.New("account has been deleted")
-		}
-	}
-
-	return user, nil
-}
-
-func createUserSession(
-	c *gin.Context,
-	userID primitive.ObjectID,
-	deviceName,
-	browserName,
-	platform string,
-	logger plog.Logger,
-) (*usersessioncol.UserSession, error) {
-	session := &usersessioncol.UserSession{
-		CreatedAt:   timer.Now(),
-		ActiveAt:    timer.Now(),
-		UserId:      userID.Hex(),
-		IsDelete:    false,
-		BrowserName: browserName,
-		IP:          c.ClientIP(),
-		DeviceName:  c.GetHeader("User-Agent"),
-		Platform:    platform,
-	}
-	_, err := usersessioncol.Create(c.Request.Context(), session)
-	if err != nil {
-		logger.Err(err).Msg("create session failed")
-		return nil, err
-	}
-
-	return session, nil
-}
-
-func updateDeviceInfo(ctx context.Context, c *gin.Context, userID, deviceID, deviceName, platform string, logger plog.Logger) error {
-	if err := userdevicecol.DisableAllDevice(ctx, userID); err != nil {
-		logger.Err(err).Msg("disable all devices failed")
-		return err
-	}
-
-	device, err := userdevicecol.FindWithDeviceId(ctx, userID, deviceID)
-	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		logger.Err(err).Msg("find device failed")
-		return err
+	if userInfo.ID == "" || userInfo.Email == "" {
+		logger.Error().Msg("user info has no id or email")
+		return nil, errors.New("failed to get user info")
 	}
-
-	if device == nil {
-		newDevice := &userdevicecol.UserDevice{
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-			IP:          c.ClientIP(),
-			UserId:      userID,
-			DeviceID:    deviceID,
-			DeviceName:  deviceName,
-			Platform:    platform,
-			IsEnable:    true,
-			IsCurrent:   true,
-			LastLoginAt: time.Now(),
-		}
-		_, err = userdevicecol.Create(ctx, newDevice)
-		if err != nil {
-			logger.Err(err).Msg("create device failed")
-			return err
-		}
-	} else {
-		device.IsCurrent = true
-		device.LastLoginAt = timer.Now()
-		_, err = userdevicecol.Update(ctx, device)
-		if err != nil {
-			logger.Err(err).Msg("update device failed")
-			return err
-		}
+	if !userInfo.VerifiedEmail {
+		return nil, errors.New("google account email is not verified")
 	}
 
-	return nil
+	return &userInfo, nil
 }
@@ -3,24 +3,19 @@ package login
 import (
 	"errors"
 	"net/http"
-	"os"
 
-	"api/internal/jwt"
+	"api/business/auth/mfa"
+	"api/business/auth/session"
 	"api/internal/plog"
 	"api/internal/response"
-	"api/internal/timer"
 	"api/internal/utils"
 	"api/schema/usercol"
-	"api/schema/userdevicecol"
-	"api/schema/usersessioncol"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const JWT_LIFETIME = 7 * 24 * 60 * 60
-
 type LoginRequestData struct {
 	Email       string `json:"email" binding:"required"`
 	Password    string `json:"password" binding:"required"`
@@ -30,9 +25,11 @@ type LoginRequestData struct {
 	Platform    string `json:"platform"`
 }
 
+// LoginResponseData chứa token khi đăng nhập xong, hoặc chỉ challenge khi cần xác thực 2 lớp
 type LoginResponseData struct {
-	AccessToken string       `json:"access_token"`
-	User        usercol.User `json:"user"`
+	*session.Tokens
+	*mfa.Challenge
+	User *usercol.User `json:"user,omitempty"`
 }
 
 func Login() gin.HandlerFunc {
@@ -95,84 +92,38 @@ func doLogin(c *gin.Context, data LoginRequestData) (*LoginResponseData, error)
 		return nil, errors.New("USER_IS_DELETED")
 	}
 
+	// accounts created via OAuth have no password until they set one
+	if !user.IsSetPassword {
+		return nil, errors.New("PASSWORD_NOT_SET")
+	}
+
 	// compare password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(data.Password))
 	if err != nil {
 		return nil, errors.New("PASSWORD_NOT_MATCH")
 	}
 
-	// remove all token
-	err = usersessioncol.RemoveAllSession(c.Request.Context(), user.GetIDString())
+	// second factor required: hand back a short-lived mfa token instead of a session
+	challenge, err := mfa.NewChallenge(ctx, user)
 	if err != nil {
 		return nil, err
 	}
-
-	// create session
-	newSession := &usersessioncol.UserSession{
-		CreatedAt:  timer.Now(),
-		ActiveAt:   timer.Now(),
-		UserId:     user.GetIDString(),
-		IsDelete:   false,
-		IP:         c.ClientIP(),
-		DeviceName: c.GetHeader("User-Agent"),
-		Platform:   data.Platform,
-	}
-	_, err = usersessioncol.Create(c.Request.Context(), newSession)
-	if err != nil {
-		return nil, err
+	if challenge != nil {
+		return &LoginResponseData{Challenge: challenge}, nil
 	}
 
-	err = userdevicecol.DisableAllDevice(c.Request.Context(), user.GetIDString())
+	tokens, err := session.Issue(c, user, session.DeviceInfo{
+		DeviceID:    data.DeviceId,
+		DeviceName:  data.DeviceName,
+		DeviceToken: data.DeviceToken,
+		Platform:    data.Platform,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// find device if exists
-	device, err := userdevicecol.FindWithDeviceId(c.Request.Context(), user.GetIDString(), data.DeviceId)
-	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		return nil, err
-	}
-	// if device not exists, create new device
-	if device == nil {
-		newDevice := &userdevicecol.UserDevice{
-			CreatedAt:   timer.Now(),
-			UpdatedAt:   timer.Now(),
-			IP:          c.ClientIP(),
-			UserId:      user.GetIDString(),
-			DeviceID:    data.DeviceId,
-			DeviceName:  data.DeviceName,
-			DeviceToken: data.DeviceToken,
-			Platform:    data.Platform,
-			IsEnable:    true,
-			IsCurrent:   true,
-			LastLoginAt: timer.Now(),
-		}
-
-		_, err = userdevicecol.Create(c.Request.Context(), newDevice)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-
-		device.IsCurrent = true
-		device.LastLoginAt = timer.Now()
-
-		_, err = userdevicecol.Update(c.Request.Context(), device)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	apiToken, _ := jwt.GenerateJWTToken(
-		os.Getenv("KEY_API_KEY"),
-		user.GetIDString(),
-		"",
-		"btcland",
-		JWT_LIFETIME,
-	)
-
 	return &LoginResponseData{
-		User:        *user,
-		AccessToken: apiToken,
+		Tokens: tokens,
+		User:   user,
 	}, nil
 }
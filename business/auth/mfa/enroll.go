@@ -0,0 +1,181 @@
+package mfa
+
+import (
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/usercol"
+	"api/schema/usermfacol"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+}
+
+type CodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Enroll tạo secret TOTP mới và trả về otpauth URL để quét bằng ứng dụng xác thực
+func Enroll() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][mfa][enroll]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		secret, otpauthURL, err := startEnrollment(c.Request.Context(), user)
+		if err != nil {
+			logger.Err(err).Msg("failed to start mfa enrollment")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(EnrollResponse{
+			Secret:     secret,
+			OtpauthURL: otpauthURL,
+		}))
+	}
+}
+
+// ConfirmEnroll xác nhận đăng ký bằng mã đầu tiên và trả về mã khôi phục (chỉ hiển thị một lần)
+func ConfirmEnroll() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][mfa][confirm_enroll]")
+
+	return func(c *gin.Context) {
+		var req CodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		codes, err := confirmEnrollment(c.Request.Context(), user, req.Code)
+		if err != nil {
+			logger.Err(err).Msg("failed to confirm mfa enrollment")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(RecoveryCodesResponse{RecoveryCodes: codes}))
+	}
+}
+
+// RegenerateRecoveryCodes sinh lại bộ mã khôi phục, các mã cũ không còn hiệu lực
+func RegenerateRecoveryCodes() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][mfa][regenerate_recovery_codes]")
+
+	return func(c *gin.Context) {
+		var req CodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		codes, err := doRegenerate(c, user, req.Code)
+		if err != nil {
+			logger.Err(err).Msg("failed to regenerate recovery codes")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(RecoveryCodesResponse{RecoveryCodes: codes}))
+	}
+}
+
+func doRegenerate(c *gin.Context, user *usercol.User, code string) ([]string, error) {
+	ctx := c.Request.Context()
+
+	record, err := checkCode(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, stored := generateRecoveryCodes()
+	record.RecoveryCodes = stored
+	if _, err = usermfacol.Update(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// Disable tắt xác thực 2 lớp, không áp dụng cho vai trò bắt buộc 2FA
+func Disable() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][mfa][disable]")
+
+	return func(c *gin.Context) {
+		var req CodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		if err := doDisable(c, user, req.Code); err != nil {
+			logger.Err(err).Msg("failed to disable mfa")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
+
+func doDisable(c *gin.Context, user *usercol.User, code string) error {
+	ctx := c.Request.Context()
+
+	if IsRequired(user) {
+		return errors.New("MFA_REQUIRED_FOR_ROLE")
+	}
+
+	if _, err := checkCode(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := usermfacol.DeleteByUserID(ctx, user.GetIDString()); err != nil {
+		return err
+	}
+
+	user.IsMFAEnabled = false
+	_, err := usercol.Update(ctx, user)
+	return err
+}
@@ -0,0 +1,263 @@
+package mfa
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"api/internal/encrypt"
+	"api/internal/timer"
+	"api/internal/totp"
+	"api/internal/utils"
+	"api/schema/usercol"
+	"api/schema/usermfacol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	Issuer               = "BTC Land"
+	TokenIssuer          = "btcland"
+	PendingTokenLifetime = 5 * 60 // 5 minutes
+
+	totpDigits = 6
+
+	RecoveryCodeCount  = 10
+	recoveryCodeLength = 10
+
+	maxFailedAttempts = 5
+	lockDuration      = 5 * time.Minute
+)
+
+// mandatoryRoles là các vai trò bắt buộc bật xác thực 2 lớp
+var mandatoryRoles = map[usercol.Role]bool{
+	usercol.RoleLeader:            true,
+	usercol.RoleAssistantDirector: true,
+}
+
+// Challenge được trả về ở bước đăng nhập thứ nhất khi user cần xác thực 2 lớp
+type Challenge struct {
+	MFARequired        bool   `json:"mfa_required"`
+	MFAToken           string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"mfa_enrollment_required"`
+}
+
+// IsRequired kiểm tra vai trò của user có bắt buộc 2FA không
+func IsRequired(user *usercol.User) bool {
+	return mandatoryRoles[user.Role]
+}
+
+// NewChallenge trả về challenge nếu user cần bước xác thực thứ hai, nil nếu không cần
+func NewChallenge(ctx context.Context, user *usercol.User) (*Challenge, error) {
+	if !user.IsMFAEnabled && !IsRequired(user) {
+		return nil, nil
+	}
+
+	token, err := issuePendingToken(ctx, user.GetIDString())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Challenge{
+		MFARequired:        true,
+		MFAToken:           token,
+		EnrollmentRequired: !user.IsMFAEnabled,
+	}, nil
+}
+
+// startEnrollment tạo secret mới (chưa xác nhận) cho user
+func startEnrollment(ctx context.Context, user *usercol.User) (secret string, otpauthURL string, err error) {
+	if user.IsMFAEnabled {
+		return "", "", errors.New("MFA_ALREADY_ENABLED")
+	}
+
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	encrypted, err := encryptSecret(secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	// drop any earlier unconfirmed attempt
+	if err = usermfacol.DeleteByUserID(ctx, user.GetIDString()); err != nil {
+		return "", "", err
+	}
+
+	_, err = usermfacol.Create(ctx, &usermfacol.UserMFA{
+		UserId:          user.GetIDString(),
+		SecretEncrypted: encrypted,
+		IsConfirmed:     false,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.GenerateQRCodeURL(Issuer, user.Email, secret), nil
+}
+
+// confirmEnrollment xác nhận secret bằng mã đầu tiên, bật 2FA và sinh mã khôi phục
+func confirmEnrollment(ctx context.Context, user *usercol.User, code string) ([]string, error) {
+	record, err := usermfacol.FindByUserID(ctx, user.GetIDString())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("MFA_NOT_ENROLLED")
+		}
+		return nil, err
+	}
+
+	if record.IsConfirmed {
+		return nil, errors.New("MFA_ALREADY_ENABLED")
+	}
+
+	if err = verifyTOTP(ctx, record, code); err != nil {
+		return nil, err
+	}
+
+	plain, stored := generateRecoveryCodes()
+	record.IsConfirmed = true
+	record.ConfirmedAt = timer.Now()
+	record.RecoveryCodes = stored
+	if _, err = usermfacol.Update(ctx, record); err != nil {
+		return nil, err
+	}
+
+	user.IsMFAEnabled = true
+	if _, err = usercol.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// checkCode xác thực mã TOTP hoặc mã khôi phục của user đã bật 2FA
+func checkCode(ctx context.Context, user *usercol.User, code string) (*usermfacol.UserMFA, error) {
+	record, err := usermfacol.FindByUserID(ctx, user.GetIDString())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("MFA_NOT_ENROLLED")
+		}
+		return nil, err
+	}
+
+	if !record.IsConfirmed {
+		return nil, errors.New("MFA_NOT_ENROLLED")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totpDigits {
+		return record, verifyTOTP(ctx, record, code)
+	}
+
+	return record, useRecoveryCode(ctx, record, code)
+}
+
+func verifyTOTP(ctx context.Context, record *usermfacol.UserMFA, code string) error {
+	if timer.Now().Before(record.LockedUntil) {
+		return errors.New("MFA_LOCKED")
+	}
+
+	secret, err := decryptSecret(record.SecretEncrypted)
+	if err != nil {
+		return err
+	}
+
+	counter, ok := totp.NewTOTP(secret).VerifyCodeCounter(strings.TrimSpace(code), timer.Now())
+	if !ok || int64(counter) <= record.LastUsedCounter {
+		return registerFailure(ctx, record)
+	}
+
+	record.LastUsedCounter = int64(counter)
+	record.FailedAttempts = 0
+	record.LockedUntil = time.Time{}
+	_, err = usermfacol.Update(ctx, record)
+	return err
+}
+
+func useRecoveryCode(ctx context.Context, record *usermfacol.UserMFA, code string) error {
+	if timer.Now().Before(record.LockedUntil) {
+		return errors.New("MFA_LOCKED")
+	}
+
+	hashed := hashRecoveryCode(code)
+	for i := range record.RecoveryCodes {
+		rc := &record.RecoveryCodes[i]
+		if !rc.UsedAt.IsZero() {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(rc.Hash), []byte(hashed)) == 1 {
+			rc.UsedAt = timer.Now()
+			record.FailedAttempts = 0
+			record.LockedUntil = time.Time{}
+			_, err := usermfacol.Update(ctx, record)
+			return err
+		}
+	}
+
+	return registerFailure(ctx, record)
+}
+
+func registerFailure(ctx context.Context, record *usermfacol.UserMFA) error {
+	record.FailedAttempts++
+	if record.FailedAttempts >= maxFailedAttempts {
+		record.FailedAttempts = 0
+		record.LockedUntil = timer.Now().Add(lockDuration)
+	}
+
+	if _, err := usermfacol.Update(ctx, record); err != nil {
+		return err
+	}
+
+	return errors.New("MFA_CODE_INVALID")
+}
+
+// generateRecoveryCodes sinh mã khôi phục dạng XXXXX-XXXXX, chỉ trả về plaintext một lần
+func generateRecoveryCodes() ([]string, []usermfacol.RecoveryCode) {
+	plain := make([]string, 0, RecoveryCodeCount)
+	stored := make([]usermfacol.RecoveryCode, 0, RecoveryCodeCount)
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		raw := strings.ToUpper(utils.RandomString(recoveryCodeLength))
+		plain = append(plain, raw[:recoveryCodeLength/2]+"-"+raw[recoveryCodeLength/2:])
+		stored = append(stored, usermfacol.RecoveryCode{Hash: hashRecoveryCode(raw)})
+	}
+
+	return plain, stored
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func secretKey() ([]byte, error) {
+	secret := os.Getenv("MFA_SECRET_KEY")
+	if secret == "" {
+		return nil, errors.New("SERVER_ERROR: MFA_SECRET_KEY is not configured")
+	}
+	return encrypt.DeriveAESKey(secret), nil
+}
+
+func encryptSecret(secret string) (string, error) {
+	key, err := secretKey()
+	if err != nil {
+		return "", err
+	}
+	return encrypt.AESGCMEncrypt(key, secret)
+}
+
+func decryptSecret(encrypted string) (string, error) {
+	key, err := secretKey()
+	if err != nil {
+		return "", err
+	}
+	return encrypt.AESGCMDecrypt(key, encrypted)
+}
@@ -0,0 +1,127 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"api/internal/jwt"
+	searedis "api/internal/redis"
+	"api/internal/utils"
+	"api/schema/usercol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	pendingKeyPrefix   = "mfa:pending:"
+	pendingTokenLength = 32
+
+	// maxPendingAttempts là số lần nhập sai tối đa với một mfa_token, sau đó token bị huỷ
+	maxPendingAttempts = 5
+)
+
+// pendingToken được lưu trong Redis theo jti của mfa_token, token chỉ dùng được khi còn bản ghi
+type pendingToken struct {
+	UserId string `json:"user_id"`
+}
+
+// issuePendingToken tạo mfa_token cho user và lưu jti của token trong Redis
+func issuePendingToken(ctx context.Context, userId string) (string, error) {
+	if searedis.GetClient() == nil {
+		return "", errors.New("SERVICE_UNAVAILABLE: redis is not configured")
+	}
+
+	tokenId := utils.RandomString(pendingTokenLength)
+	if err := searedis.SetObject(ctx, pendingKeyPrefix+tokenId, &pendingToken{UserId: userId}, PendingTokenLifetime); err != nil {
+		return "", err
+	}
+
+	return jwt.GenerateSessionTokenWithID(
+		os.Getenv("KEY_API_KEY"),
+		userId,
+		tokenId,
+		jwt.SessionTypeMFAPending,
+		TokenIssuer,
+		PendingTokenLifetime,
+	)
+}
+
+// userFromPendingToken lấy user và jti từ mfa_token còn hiệu lực, token đã dùng hoặc đã bị huỷ bị từ chối
+func userFromPendingToken(ctx context.Context, token string) (*usercol.User, string, error) {
+	claim, err := jwt.VerifySessionToken(os.Getenv("KEY_API_KEY"), token)
+	if err != nil {
+		return nil, "", errors.New("MFA_TOKEN_INVALID")
+	}
+
+	if claim.Type != jwt.SessionTypeMFAPending || claim.ID == "" {
+		return nil, "", errors.New("MFA_TOKEN_INVALID")
+	}
+
+	if searedis.GetClient() == nil {
+		return nil, "", errors.New("SERVICE_UNAVAILABLE: redis is not configured")
+	}
+
+	pending := &pendingToken{}
+	found, err := searedis.GetObject(ctx, pendingKeyPrefix+claim.ID, pending)
+	if err != nil {
+		return nil, "", err
+	}
+	if !found || pending.UserId != claim.SessionId {
+		return nil, "", errors.New("MFA_TOKEN_INVALID")
+	}
+
+	user, err := usercol.FindWithUserID(ctx, claim.SessionId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, "", errors.New("MFA_TOKEN_INVALID")
+		}
+		return nil, "", err
+	}
+
+	if user.IsDelete {
+		return nil, "", errors.New("USER_IS_DELETED")
+	}
+
+	return user, claim.ID, nil
+}
+
+// consumePendingToken xoá mfa_token sau khi xác thực thành công, lần dùng lại sau đó bị từ chối.
+// Trả về lỗi nếu token đã được dùng bởi request khác
+func consumePendingToken(ctx context.Context, tokenId string) error {
+	found, err := searedis.PopObject(ctx, pendingKeyPrefix+tokenId, &pendingToken{})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("MFA_TOKEN_INVALID")
+	}
+
+	return searedis.DeleteObject(ctx, pendingKeyPrefix+tokenId+":attempts")
+}
+
+// failPendingToken đếm lần nhập sai với mfa_token, huỷ token khi sai quá maxPendingAttempts lần
+func failPendingToken(ctx context.Context, tokenId string) error {
+	attemptsKey := pendingKeyPrefix + tokenId + ":attempts"
+
+	client := searedis.GetClient()
+	attempts, err := client.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		return err
+	}
+	if attempts == 1 {
+		if err = client.Expire(ctx, attemptsKey, PendingTokenLifetime*time.Second).Err(); err != nil {
+			return err
+		}
+	}
+
+	if attempts < maxPendingAttempts {
+		return nil
+	}
+
+	if err = searedis.DeleteObject(ctx, pendingKeyPrefix+tokenId); err != nil {
+		return err
+	}
+	return errors.New("MFA_LOCKED")
+}
@@ -0,0 +1,21 @@
+package mfa
+
+import (
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Bước đăng nhập thứ hai, xác thực bằng mfa_token
+	r.POST("/setup", Setup())   // POST /auth/mfa/setup - Đăng ký 2FA bắt buộc khi đăng nhập
+	r.POST("/verify", Verify()) // POST /auth/mfa/verify - Xác thực mã và cấp token
+
+	// Quản lý 2FA cho user đã đăng nhập
+	authorized := r.Group("")
+	authorized.Use(middleware.AuthMiddleware())
+	authorized.POST("/enroll", Enroll())                          // POST /auth/mfa/enroll - Tạo secret TOTP
+	authorized.POST("/enroll/confirm", ConfirmEnroll())           // POST /auth/mfa/enroll/confirm - Xác nhận và bật 2FA
+	authorized.POST("/recovery-codes", RegenerateRecoveryCodes()) // POST /auth/mfa/recovery-codes - Sinh lại mã khôi phục
+	authorized.POST("/disable", Disable())                        // POST /auth/mfa/disable - Tắt 2FA
+}
@@ -0,0 +1,145 @@
+package mfa
+
+import (
+	"net/http"
+
+	"api/business/auth/session"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetupRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type VerifyRequest struct {
+	MFAToken    string `json:"mfa_token" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	DeviceId    string `json:"device_id"`
+	DeviceName  string `json:"device_name"`
+	DeviceToken string `json:"device_token"`
+	BrowserName string `json:"browser_name"`
+	Platform    string `json:"platform"`
+}
+
+type VerifyResponse struct {
+	session.Tokens
+	User          usercol.User `json:"user"`
+	RecoveryCodes []string     `json:"recovery_codes,omitempty"`
+}
+
+// Setup dùng cho user bắt buộc 2FA nhưng chưa đăng ký: tạo secret từ mfa_token của bước đăng nhập
+func Setup() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][mfa][setup]")
+
+	return func(c *gin.Context) {
+		var req SetupRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		user, _, err := userFromPendingToken(ctx, req.MFAToken)
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		secret, otpauthURL, err := startEnrollment(ctx, user)
+		if err != nil {
+			logger.Err(err).Msg("failed to start mfa enrollment")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(EnrollResponse{
+			Secret:     secret,
+			OtpauthURL: otpauthURL,
+		}))
+	}
+}
+
+// Verify là bước đăng nhập thứ hai: xác thực mã TOTP hoặc mã khôi phục rồi cấp access token
+func Verify() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][mfa][verify]")
+
+	return func(c *gin.Context) {
+		var req VerifyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		res, err := doVerify(c, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to verify mfa")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doVerify(c *gin.Context, req VerifyRequest) (*VerifyResponse, error) {
+	ctx := c.Request.Context()
+
+	user, tokenId, err := userFromPendingToken(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if user.IsMFAEnabled {
+		_, err = checkCode(ctx, user, req.Code)
+	} else {
+		// first code after a forced setup also confirms the enrollment
+		recoveryCodes, err = confirmEnrollment(ctx, user, req.Code)
+	}
+	if err != nil {
+		// wrong codes are also counted per mfa_token, the token is dropped after too many
+		if err.Error() == "MFA_CODE_INVALID" {
+			if failErr := failPendingToken(ctx, tokenId); failErr != nil {
+				return nil, failErr
+			}
+		}
+		return nil, err
+	}
+
+	// the mfa_token is single use
+	if err = consumePendingToken(ctx, tokenId); err != nil {
+		return nil, err
+	}
+
+	tokens, err := session.Issue(c, user, session.DeviceInfo{
+		DeviceID:    req.DeviceId,
+		DeviceName:  req.DeviceName,
+		DeviceToken: req.DeviceToken,
+		BrowserName: req.BrowserName,
+		Platform:    req.Platform,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerifyResponse{
+		Tokens:        *tokens,
+		User:          *user,
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
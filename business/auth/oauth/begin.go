@@ -0,0 +1,64 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+
+	"api/internal/utils"
+	"api/services/oauth2"
+	"api/services/oauth2/google"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	xoauth2 "golang.org/x/oauth2"
+)
+
+const nonceLength = 32
+
+// BeginAuth lưu state (kèm PKCE verifier, nonce với OIDC) và trả về URL chuyển tới provider.
+// requestedRedirect rỗng thì dùng client redirect mặc định của provider
+func BeginAuth(ctx context.Context, providerName, requestedRedirect string, state *State) (string, error) {
+	state.Provider = providerName
+	// PKCE verifier stays server side, only the S256 challenge goes to the provider
+	state.CodeVerifier = xoauth2.GenerateVerifier()
+
+	if providerName == oauth2.Google.String() {
+		if google.OAuthConfig == nil {
+			return "", errors.New("SERVICE_UNAVAILABLE: google login is not configured")
+		}
+
+		redirectURL, err := ResolveRedirect(requestedRedirect, google.OAuthConfig.ClientRedirectURL)
+		if err != nil {
+			return "", err
+		}
+		state.RedirectURL = redirectURL
+
+		key, err := SaveState(ctx, state)
+		if err != nil {
+			return "", err
+		}
+
+		return google.OAuthConfig.AuthCodeURL(key, xoauth2.S256ChallengeOption(state.CodeVerifier)), nil
+	}
+
+	provider, err := oauth2.GetOIDCProvider(ctx, providerName)
+	if err != nil {
+		return "", err
+	}
+
+	redirectURL, err := ResolveRedirect(requestedRedirect, provider.ClientRedirectURL)
+	if err != nil {
+		return "", err
+	}
+	state.RedirectURL = redirectURL
+	state.Nonce = utils.RandomString(nonceLength)
+
+	key, err := SaveState(ctx, state)
+	if err != nil {
+		return "", err
+	}
+
+	return provider.Config.AuthCodeURL(key,
+		xoauth2.S256ChallengeOption(state.CodeVerifier),
+		gooidc.Nonce(state.Nonce),
+	), nil
+}
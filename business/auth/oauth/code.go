@@ -0,0 +1,125 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+
+	"api/business/auth/login"
+	"api/business/auth/mfa"
+	"api/business/auth/session"
+	"api/internal/plog"
+	searedis "api/internal/redis"
+	"api/internal/response"
+	"api/internal/utils"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	LoginCodeLifetime = 60 // 1 minute
+	loginCodeLength   = 48
+	codeKeyPrefix     = "oauth:code:"
+)
+
+// LoginCode là mã dùng một lần trả về client sau callback, token chỉ được cấp khi đổi mã
+type LoginCode struct {
+	UserId string             `json:"user_id"`
+	Device session.DeviceInfo `json:"device"`
+}
+
+type ExchangeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// IssueLoginCode tạo mã dùng một lần cho user đã xác thực với provider
+func IssueLoginCode(c *gin.Context, userId string, device session.DeviceInfo) (string, error) {
+	if searedis.GetClient() == nil {
+		return "", errors.New("SERVICE_UNAVAILABLE: redis is not configured")
+	}
+
+	code := utils.RandomString(loginCodeLength)
+	err := searedis.SetObject(c.Request.Context(), codeKeyPrefix+code, &LoginCode{
+		UserId: userId,
+		Device: device,
+	}, LoginCodeLifetime)
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Exchange đổi mã dùng một lần lấy access/refresh token (hoặc challenge 2FA)
+func Exchange() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][oauth][exchange]")
+
+	return func(c *gin.Context) {
+		var req ExchangeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		res, err := doExchange(c, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to exchange login code")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doExchange(c *gin.Context, req ExchangeRequest) (*login.LoginResponseData, error) {
+	ctx := c.Request.Context()
+
+	if searedis.GetClient() == nil {
+		return nil, errors.New("SERVICE_UNAVAILABLE: redis is not configured")
+	}
+
+	loginCode := &LoginCode{}
+	found, err := searedis.PopObject(ctx, codeKeyPrefix+req.Code, loginCode)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("OAUTH_CODE_INVALID")
+	}
+
+	user, err := usercol.FindWithUserID(ctx, loginCode.UserId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("OAUTH_CODE_INVALID")
+		}
+		return nil, err
+	}
+
+	if user.IsDelete {
+		return nil, errors.New("USER_IS_DELETED")
+	}
+
+	challenge, err := mfa.NewChallenge(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &login.LoginResponseData{Challenge: challenge}, nil
+	}
+
+	tokens, err := session.Issue(c, user, loginCode.Device)
+	if err != nil {
+		return nil, err
+	}
+
+	return &login.LoginResponseData{
+		Tokens: tokens,
+		User:   user,
+	}, nil
+}
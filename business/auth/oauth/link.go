@@ -0,0 +1,119 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/schema/usercol"
+	"api/schema/usersessioncol"
+	"api/services/oauth2"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AutoLinkEnabled cho phép tự liên kết identity mới vào user có cùng email đã được provider xác minh
+// (env OAUTH_AUTO_LINK_VERIFIED_EMAIL, mặc định tắt)
+func AutoLinkEnabled() bool {
+	return strings.EqualFold(os.Getenv("OAUTH_AUTO_LINK_VERIFIED_EMAIL"), "true")
+}
+
+// LinkIdentity gắn identity vào user đã bắt đầu liên kết từ /profile.
+// Phiên đăng nhập lúc bắt đầu phải còn hiệu lực
+func LinkIdentity(ctx context.Context, state *State, identity *oauth2.Identity) error {
+	if identity.Subject == "" {
+		return errors.New("OAUTH_SUBJECT_MISSING")
+	}
+
+	userSession, err := usersessioncol.FindWithId(ctx, state.LinkSessionId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errors.New("SESSION_REVOKED")
+		}
+		return err
+	}
+	if userSession.IsDelete || !userSession.IsEnable || userSession.UserId != state.LinkUserId {
+		return errors.New("SESSION_REVOKED")
+	}
+
+	owner, err := usercol.FindWithIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if owner != nil {
+		if owner.GetIDString() != state.LinkUserId {
+			return errors.New("IDENTITY_ALREADY_LINKED")
+		}
+		return nil
+	}
+
+	_, err = usercol.AddIdentity(ctx, state.LinkUserId, newIdentity(identity))
+	return err
+}
+
+// Complete hoàn tất callback của provider: liên kết identity nếu state được tạo từ /profile,
+// ngược lại tìm/tạo user và chuyển về client kèm mã đăng nhập dùng một lần
+func Complete(c *gin.Context, state *State, identity *oauth2.Identity, logger plog.Logger) {
+	ctx := c.Request.Context()
+
+	if state.LinkUserId != "" {
+		if err := LinkIdentity(ctx, state, identity); err != nil {
+			logger.Err(err).Str("provider", identity.Provider.String()).Msg("failed to link identity")
+			res := response.ErrorResponse(err.Error())
+			c.JSON(res.Code, res)
+			c.Abort()
+			return
+		}
+
+		redirectURL, err := AppendQuery(state.RedirectURL, map[string]string{"linked": identity.Provider.String()})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("invalid redirect url"))
+			c.Abort()
+			return
+		}
+
+		c.Redirect(http.StatusTemporaryRedirect, redirectURL)
+		return
+	}
+
+	user, err := FindOrCreateUser(ctx, identity, logger)
+	if err != nil {
+		res := response.ErrorResponse(err.Error())
+		c.JSON(res.Code, res)
+		c.Abort()
+		return
+	}
+
+	// Issue a one-time code; tokens are only handed out by /auth/oauth/exchange
+	loginCode, err := IssueLoginCode(c, user.GetIDString(), state.Device)
+	if err != nil {
+		logger.Err(err).Msg("issue login code failed")
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("session creation failed"))
+		c.Abort()
+		return
+	}
+
+	redirectURL, err := AppendQuery(state.RedirectURL, map[string]string{"code": loginCode})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("invalid redirect url"))
+		c.Abort()
+		return
+	}
+
+	c.Redirect(http.StatusTemporaryRedirect, redirectURL)
+}
+
+func newIdentity(identity *oauth2.Identity) usercol.Identity {
+	return usercol.Identity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: timer.Now(),
+	}
+}
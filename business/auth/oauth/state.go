@@ -0,0 +1,121 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+
+	"api/business/auth/session"
+	searedis "api/internal/redis"
+	"api/internal/utils"
+)
+
+const (
+	StateLifetime  = 10 * 60 // 10 minutes
+	stateLength    = 32
+	stateKeyPrefix = "oauth:state:"
+)
+
+// State được lưu trong Redis cho mỗi lần đăng nhập OAuth, dùng một lần ở callback
+type State struct {
+	Provider     string             `json:"provider"`
+	CodeVerifier string             `json:"code_verifier"`
+	Nonce        string             `json:"nonce,omitempty"`
+	RedirectURL  string             `json:"redirect_url"`
+	Device       session.DeviceInfo `json:"device"`
+
+	// Khi liên kết tài khoản từ /profile: callback gắn identity vào user này thay vì đăng nhập
+	LinkUserId    string `json:"link_user_id,omitempty"`
+	LinkSessionId string `json:"link_session_id,omitempty"`
+}
+
+// SaveState lưu state và trả về giá trị ngẫu nhiên dùng làm tham số state gửi tới provider
+func SaveState(ctx context.Context, state *State) (string, error) {
+	if searedis.GetClient() == nil {
+		return "", errors.New("SERVICE_UNAVAILABLE: redis is not configured")
+	}
+
+	key := utils.RandomString(stateLength)
+	if err := searedis.SetObject(ctx, stateKeyPrefix+key, state, StateLifetime); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// ConsumeState lấy và xoá state, state không tồn tại hoặc đã dùng sẽ bị từ chối
+func ConsumeState(ctx context.Context, key string) (*State, error) {
+	if key == "" {
+		return nil, errors.New("OAUTH_STATE_INVALID")
+	}
+
+	if searedis.GetClient() == nil {
+		return nil, errors.New("SERVICE_UNAVAILABLE: redis is not configured")
+	}
+
+	state := &State{}
+	found, err := searedis.PopObject(ctx, stateKeyPrefix+key, state)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("OAUTH_STATE_INVALID")
+	}
+
+	return state, nil
+}
+
+// ResolveRedirect kiểm tra redirect target client yêu cầu với danh sách cho phép (env OAUTH_ALLOWED_REDIRECTS).
+// Không truyền redirect thì dùng defaultURL
+func ResolveRedirect(requested, defaultURL string) (string, error) {
+	if requested == "" {
+		return defaultURL, nil
+	}
+
+	target, err := url.Parse(requested)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return "", errors.New("INVALID_PARAM: redirect_uri is invalid")
+	}
+
+	normalized := normalizeRedirect(target)
+	allowed := strings.Split(os.Getenv("OAUTH_ALLOWED_REDIRECTS"), ",")
+	allowed = append(allowed, defaultURL)
+	for _, item := range allowed {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		u, err := url.Parse(item)
+		if err != nil {
+			continue
+		}
+		if normalizeRedirect(u) == normalized {
+			return requested, nil
+		}
+	}
+
+	return "", errors.New("OAUTH_REDIRECT_NOT_ALLOWED")
+}
+
+// normalizeRedirect so khớp theo scheme, host và path, bỏ qua query
+func normalizeRedirect(u *url.URL) string {
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + strings.TrimRight(u.Path, "/")
+}
+
+// AppendQuery thêm tham số vào redirect URL, giữ nguyên query đã có
+func AppendQuery(rawURL string, params map[string]string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
@@ -0,0 +1,117 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+
+	"api/business/auth/register"
+	"api/business/invitations"
+	"api/internal/plog"
+	"api/schema/usercol"
+	"api/services/oauth2"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// FindOrCreateUser tìm user theo identity (provider, subject). Email trùng với tài khoản có sẵn
+// chỉ được tự liên kết khi provider xác minh email và tổ chức cho phép, ngược lại user phải
+// đăng nhập rồi liên kết từ /profile. Chưa có tài khoản thì tạo mới với vai trò nhân viên,
+// lời mời đang chờ của email (nếu có) được áp dụng ngay
+func FindOrCreateUser(ctx context.Context, identity *oauth2.Identity, logger plog.Logger) (*usercol.User, error) {
+	if identity.Subject == "" {
+		return nil, errors.New("OAUTH_SUBJECT_MISSING")
+	}
+
+	user, err := usercol.FindWithIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.Err(err).Msg("database error")
+		return nil, err
+	}
+	if user != nil {
+		return checkUser(user)
+	}
+
+	// accounts created before identities existed only carry oauth_provider
+	user, err = usercol.FindWithLegacyProvider(ctx, identity.Provider, identity.Subject)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.Err(err).Msg("database error")
+		return nil, err
+	}
+	if user != nil {
+		if _, err = usercol.AddIdentity(ctx, user.GetIDString(), newIdentity(identity)); err != nil {
+			logger.Err(err).Msg("failed to migrate oauth provider")
+			return nil, err
+		}
+		return checkUser(user)
+	}
+
+	if identity.Email == "" {
+		return nil, errors.New("OAUTH_EMAIL_MISSING")
+	}
+
+	user, err = usercol.FindWithEmail(ctx, identity.Email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.Err(err).Msg("database error")
+		return nil, err
+	}
+	if user != nil {
+		if !identity.EmailVerified || !AutoLinkEnabled() {
+			return nil, errors.New("ACCOUNT_LINK_REQUIRED")
+		}
+
+		if _, err = usercol.AddIdentity(ctx, user.GetIDString(), newIdentity(identity)); err != nil {
+			logger.Err(err).Msg("failed to link identity")
+			return nil, err
+		}
+		return checkUser(user)
+	}
+
+	switch register.Mode() {
+	case register.ModeOpen:
+	case register.ModeInvite:
+		// first sign-in of an invitee, only trusted when the provider verified the email
+		pending := false
+		if identity.EmailVerified {
+			if pending, err = invitations.HasPending(ctx, identity.Email); err != nil {
+				return nil, err
+			}
+		}
+		if !pending {
+			return nil, errors.New("REGISTRATION_CLOSED")
+		}
+	default:
+		return nil, errors.New("REGISTRATION_CLOSED")
+	}
+
+	newUser := &usercol.User{
+		Email:         identity.Email,
+		FullName:      identity.Name,
+		Avatar:        identity.Picture,
+		Identities:    []usercol.Identity{newIdentity(identity)},
+		Role:          usercol.RoleEmployee,
+		IsVerifyEmail: identity.EmailVerified,
+		IsSetPassword: false,
+		IsDelete:      false,
+	}
+	_, err = usercol.Create(ctx, newUser)
+	if err != nil {
+		logger.Err(err).Msg("failed to create user")
+		return nil, err
+	}
+
+	if identity.EmailVerified {
+		if err = invitations.Accept(ctx, newUser); err != nil {
+			logger.Err(err).Str("email", newUser.Email).Msg("failed to apply invitation")
+			return nil, err
+		}
+	}
+
+	return newUser, nil
+}
+
+func checkUser(user *usercol.User) (*usercol.User, error) {
+	if user.IsDelete {
+		return nil, errors.New("USER_IS_DELETED")
+	}
+	return user, nil
+}
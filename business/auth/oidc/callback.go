@@ -0,0 +1,59 @@
+package oidc
+
+import (
+	"net/http"
+
+	"api/business/auth/oauth"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/services/oauth2"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Callback nhận authorization code từ provider OIDC, xác thực ID token rồi đăng nhập hoặc liên kết tài khoản
+func Callback() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][oidc][callback]")
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		providerName := c.Param("provider")
+
+		state, err := oauth.ConsumeState(ctx, c.Query("state"))
+		if err != nil || state.Provider != providerName {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid state"))
+			c.Abort()
+			return
+		}
+
+		code := c.Query("code")
+		if code == "" {
+			message := "code is missing"
+			if reason := c.Query("error_description"); reason != "" {
+				message = reason
+			}
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(message))
+			c.Abort()
+			return
+		}
+
+		provider, err := oauth2.GetOIDCProvider(ctx, providerName)
+		if err != nil {
+			logger.Err(err).Str("provider", providerName).Msg("failed to get provider")
+			res := response.ErrorResponse(err.Error())
+			c.JSON(res.Code, res)
+			c.Abort()
+			return
+		}
+
+		identity, err := provider.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+		if err != nil {
+			logger.Err(err).Str("provider", providerName).Msg("failed to verify identity")
+			c.JSON(http.StatusUnauthorized, response.ErrorResponse("failed to verify identity"))
+			c.Abort()
+			return
+		}
+
+		oauth.Complete(c, state, identity, logger)
+	}
+}
@@ -0,0 +1,39 @@
+package oidc
+
+import (
+	"net/http"
+
+	"api/business/auth/oauth"
+	"api/business/auth/session"
+	"api/internal/plog"
+	"api/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Login tạo URL đăng nhập cho provider OIDC đã cấu hình (Entra ID, Keycloak, ...)
+func Login() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][oidc][login]")
+
+	return func(c *gin.Context) {
+		oauthURL, err := oauth.BeginAuth(c.Request.Context(), c.Param("provider"), c.Query("redirect_uri"), &oauth.State{
+			Device: session.DeviceInfo{
+				DeviceID:    c.Query("device_id"),
+				DeviceName:  c.Query("device_name"),
+				BrowserName: c.Query("browser_name"),
+				Platform:    c.Query("platform"),
+			},
+		})
+		if err != nil {
+			logger.Err(err).Str("provider", c.Param("provider")).Msg("failed to start oidc login")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(map[string]string{
+			"oauth_url": oauthURL,
+		}))
+	}
+}
@@ -0,0 +1,8 @@
+package oidc
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.GET("/:provider/login", Login())       // GET /auth/:provider/login - URL đăng nhập OIDC
+	r.GET("/:provider/callback", Callback()) // GET /auth/:provider/callback - Callback từ provider
+}
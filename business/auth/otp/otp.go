@@ -0,0 +1,169 @@
+package otp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"api/internal/jwt"
+	"api/internal/plog"
+	"api/internal/timer"
+	"api/internal/utils"
+	"api/schema/otpcol"
+	"api/services/mail"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	CodeLength       = 6
+	CodeLifetime     = 5 * 60  // 5 minutes
+	SessionLifetime  = 15 * 60 // 15 minutes
+	MaxVerifyAttempt = 5
+	TokenIssuer      = "btcland"
+
+	mailTimeout = 30 * time.Second
+)
+
+type Target struct {
+	Email  string
+	Name   string
+	UserId string
+}
+
+var subjects = map[string]string{
+	jwt.SessionTypeRegister:   "Mã xác thực đăng ký tài khoản",
+	jwt.SessionTypeForgotPass: "Mã xác thực đặt lại mật khẩu",
+	jwt.SessionTypeChangePass: "Mã xác thực đổi mật khẩu",
+}
+
+// Send tạo mã OTP mới cho target, gửi qua email và trả về session token dùng để xác thực
+func Send(ctx context.Context, target Target, otpType string) (string, error) {
+	record, err := create(ctx, target, otpType)
+	if err != nil {
+		return "", err
+	}
+
+	if err = sendMail(ctx, target, record); err != nil {
+		return "", err
+	}
+
+	return sessionToken(record)
+}
+
+// SendQuietly tạo mã OTP như Send nhưng chỉ gửi email khi deliver, việc gửi chạy ở background
+// và lỗi gửi chỉ được ghi log. Phản hồi không cho biết email có tài khoản hay không
+func SendQuietly(ctx context.Context, target Target, otpType string, deliver bool) (string, error) {
+	record, err := create(ctx, target, otpType)
+	if err != nil {
+		return "", err
+	}
+
+	if deliver {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+			defer cancel()
+			_ = sendMail(ctx, target, record)
+		}()
+	}
+
+	return sessionToken(record)
+}
+
+func create(ctx context.Context, target Target, otpType string) (*otpcol.OTP, error) {
+	newOTP := &otpcol.OTP{
+		Code:     utils.RandomDigits(CodeLength),
+		Email:    target.Email,
+		Name:     target.Name,
+		UserId:   target.UserId,
+		Type:     otpType,
+		ExpireAt: timer.Now().Add(CodeLifetime * time.Second),
+	}
+	if _, err := otpcol.Create(ctx, newOTP); err != nil {
+		return nil, err
+	}
+	return newOTP, nil
+}
+
+func sendMail(ctx context.Context, target Target, record *otpcol.OTP) error {
+	logger := plog.NewBizLogger("[auth][otp][send]")
+
+	subject, ok := subjects[record.Type]
+	if !ok {
+		subject = "Mã xác thực"
+	}
+	text := fmt.Sprintf("Mã xác thực của bạn là: %s\nMã có hiệu lực trong %d phút.", record.Code, CodeLifetime/60)
+
+	if err := mail.Send(ctx, target.Email, subject, text, record.Type); err != nil {
+		logger.Err(err).Str("email", target.Email).Msg("failed to send otp email")
+		return errors.New("SERVICE_UNAVAILABLE: failed to send verification email")
+	}
+	return nil
+}
+
+func sessionToken(record *otpcol.OTP) (string, error) {
+	return jwt.GenerateSessionToken(
+		os.Getenv("KEY_API_KEY"),
+		record.GetIDString(),
+		record.Type,
+		TokenIssuer,
+		SessionLifetime,
+	)
+}
+
+// Verify kiểm tra session token và mã OTP, đánh dấu OTP đã được sử dụng nếu hợp lệ
+func Verify(ctx context.Context, sessionToken, code, otpType string) (*otpcol.OTP, error) {
+	claim, err := jwt.VerifySessionToken(os.Getenv("KEY_API_KEY"), sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claim.Type != otpType {
+		return nil, errors.New("SESSION_TYPE_INVALID")
+	}
+
+	record, err := otpcol.FindOTP(ctx, claim.SessionId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("OTP_NOT_FOUND")
+		}
+		return nil, err
+	}
+
+	if record.Type != otpType {
+		return nil, errors.New("OTP_NOT_FOUND")
+	}
+
+	if record.IsVerified {
+		return nil, errors.New("OTP_ALREADY_USED")
+	}
+
+	if timer.Now().After(record.ExpireAt) {
+		return nil, errors.New("OTP_EXPIRED")
+	}
+
+	if record.Attempts >= MaxVerifyAttempt {
+		return nil, errors.New("OTP_TOO_MANY_ATTEMPTS")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(record.Code), []byte(code)) != 1 {
+		if err = otpcol.IncrAttempts(ctx, record.GetIDString()); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("OTP_INVALID")
+	}
+
+	// conditional update so two concurrent requests cannot both use the same code
+	record, err = otpcol.MarkVerified(ctx, record.GetIDString(), code, MaxVerifyAttempt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("OTP_ALREADY_USED")
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
@@ -0,0 +1,143 @@
+package password
+
+import (
+	"errors"
+	"net/http"
+
+	"api/business/auth/otp"
+	"api/business/auth/session"
+	"api/internal/encrypt"
+	"api/internal/jwt"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChangeRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+}
+
+type ChangeResponse struct {
+	SessionToken string `json:"session_token"`
+}
+
+type ConfirmChangeRequest struct {
+	SessionToken string `json:"session_token" binding:"required"`
+	Code         string `json:"code" binding:"required"`
+	NewPassword  string `json:"new_password" binding:"required"`
+}
+
+// Change xác thực mật khẩu hiện tại và gửi mã OTP tới email để xác nhận đổi mật khẩu
+func Change() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][password][change]")
+
+	return func(c *gin.Context) {
+		var req ChangeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		res, err := doChange(c, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to request password change")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doChange(c *gin.Context, req ChangeRequest) (*ChangeResponse, error) {
+	currentUser, exists := c.Get("current_user")
+	if !exists {
+		return nil, errors.New("UNAUTHORIZED")
+	}
+	user := currentUser.(*usercol.User)
+
+	if !user.IsSetPassword {
+		return nil, errors.New("PASSWORD_NOT_SET")
+	}
+
+	if err := encrypt.VerifyHashBcrypt(user.Password, req.OldPassword); err != nil {
+		return nil, errors.New("PASSWORD_NOT_MATCH")
+	}
+
+	sessionToken, err := otp.Send(c.Request.Context(), otp.Target{
+		Email:  user.Email,
+		Name:   user.FullName,
+		UserId: user.GetIDString(),
+	}, jwt.SessionTypeChangePass)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChangeResponse{SessionToken: sessionToken}, nil
+}
+
+// ConfirmChange xác thực mã OTP và cập nhật mật khẩu mới, các phiên đăng nhập khác sẽ bị thu hồi
+func ConfirmChange() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][password][confirm_change]")
+
+	return func(c *gin.Context) {
+		var req ConfirmChangeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if err := doConfirmChange(c, req); err != nil {
+			logger.Err(err).Msg("failed to confirm password change")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
+
+func doConfirmChange(c *gin.Context, req ConfirmChangeRequest) error {
+	ctx := c.Request.Context()
+
+	currentUser, exists := c.Get("current_user")
+	if !exists {
+		return errors.New("UNAUTHORIZED")
+	}
+	user := currentUser.(*usercol.User)
+
+	// validate before consuming the code so a weak password doesn't burn the OTP
+	hashed, err := Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	record, err := otp.Verify(ctx, req.SessionToken, req.Code, jwt.SessionTypeChangePass)
+	if err != nil {
+		return err
+	}
+
+	if record.UserId != user.GetIDString() {
+		return errors.New("OTP_NOT_FOUND")
+	}
+
+	user.Password = hashed
+	user.IsSetPassword = true
+	if _, err = usercol.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// keep the session that confirmed the change, sign out everywhere else
+	_, err = session.RevokeAllExcept(ctx, user.GetIDString(), c.GetString("session_id"), "password_changed")
+	return err
+}
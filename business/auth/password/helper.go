@@ -0,0 +1,32 @@
+package password
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"api/internal/encrypt"
+)
+
+const (
+	MinLength = 8
+	MaxLength = 72 // bcrypt ignores bytes after 72
+)
+
+// Validate kiểm tra độ dài mật khẩu
+func Validate(password string) error {
+	if utf8.RuneCountInString(password) < MinLength {
+		return errors.New("PASSWORD_TOO_SHORT: password must be at least 8 characters")
+	}
+	if len(password) > MaxLength {
+		return errors.New("PASSWORD_TOO_LONG: password must be at most 72 bytes")
+	}
+	return nil
+}
+
+// Hash kiểm tra và băm mật khẩu bằng bcrypt
+func Hash(password string) (string, error) {
+	if err := Validate(password); err != nil {
+		return "", err
+	}
+	return encrypt.HashBcrypt(password)
+}
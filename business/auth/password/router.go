@@ -0,0 +1,15 @@
+package password
+
+import (
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	r.Use(middleware.AuthMiddleware())
+
+	r.POST("/set", Set())                      // POST /auth/password/set - Đặt mật khẩu cho tài khoản OAuth
+	r.POST("/change", Change())                // POST /auth/password/change - Gửi OTP đổi mật khẩu
+	r.POST("/change/confirm", ConfirmChange()) // POST /auth/password/change/confirm - Xác nhận đổi mật khẩu
+}
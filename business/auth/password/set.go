@@ -0,0 +1,63 @@
+package password
+
+import (
+	"errors"
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Set đặt mật khẩu lần đầu cho tài khoản được tạo qua OAuth (IsSetPassword = false)
+func Set() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][password][set]")
+
+	return func(c *gin.Context) {
+		var req SetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if err := doSet(c, req); err != nil {
+			logger.Err(err).Msg("failed to set password")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
+
+func doSet(c *gin.Context, req SetRequest) error {
+	currentUser, exists := c.Get("current_user")
+	if !exists {
+		return errors.New("UNAUTHORIZED")
+	}
+	user := currentUser.(*usercol.User)
+
+	if user.IsSetPassword {
+		return errors.New("PASSWORD_ALREADY_SET")
+	}
+
+	hashed, err := Hash(req.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashed
+	user.IsSetPassword = true
+	_, err = usercol.Update(c.Request.Context(), user)
+	return err
+}
@@ -0,0 +1,119 @@
+package register
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"api/business/auth/otp"
+	"api/business/invitations"
+	"api/internal/jwt"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/utils"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	ModeOpen   = "open"
+	ModeClosed = "closed"
+	ModeInvite = "invite" // chỉ người có lời mời mới được đăng ký
+)
+
+type RegisterRequest struct {
+	Email       string `json:"email" binding:"required"`
+	FullName    string `json:"full_name" binding:"required"`
+	InviteToken string `json:"invite_token"`
+}
+
+type RegisterResponse struct {
+	SessionToken string `json:"session_token"`
+}
+
+// Mode trả về chế độ đăng ký hiện tại (open, invite, closed), mặc định là open
+func Mode() string {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_REGISTRATION_MODE")))
+	if mode == "" {
+		return ModeOpen
+	}
+	return mode
+}
+
+// Register gửi mã OTP xác thực email để đăng ký tài khoản mới, phản hồi không cho biết email đã có tài khoản hay chưa
+func Register() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][register]")
+
+	return func(c *gin.Context) {
+		var req RegisterRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		res, err := doRegister(c, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to register")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doRegister(c *gin.Context, req RegisterRequest) (*RegisterResponse, error) {
+	ctx := c.Request.Context()
+
+	mode := Mode()
+	if mode != ModeOpen && mode != ModeInvite {
+		return nil, errors.New("REGISTRATION_CLOSED")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !utils.IsEmailValid(email) {
+		return nil, errors.New("INVALID_PARAM: email is invalid")
+	}
+
+	if mode == ModeInvite || req.InviteToken != "" {
+		if req.InviteToken == "" {
+			return nil, errors.New("REGISTRATION_CLOSED: an invitation is required")
+		}
+
+		invitation, err := invitations.VerifyToken(ctx, req.InviteToken)
+		if err != nil {
+			return nil, err
+		}
+		if invitation.Email != email {
+			return nil, errors.New("INVITATION_EMAIL_MISMATCH")
+		}
+	}
+
+	fullName := strings.TrimSpace(req.FullName)
+	if fullName == "" {
+		return nil, errors.New("INVALID_PARAM: full_name is required")
+	}
+
+	// same response whether or not the email already has an account, the code is only mailed to new emails
+	_, err := usercol.FindWithEmail(ctx, email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	sessionToken, err := otp.SendQuietly(ctx, otp.Target{
+		Email: email,
+		Name:  fullName,
+	}, jwt.SessionTypeRegister, err != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegisterResponse{SessionToken: sessionToken}, nil
+}
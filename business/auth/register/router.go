@@ -0,0 +1,8 @@
+package register
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.POST("", Register())      // POST /auth/register - Gửi OTP đăng ký
+	r.POST("/verify", Verify()) // POST /auth/register/verify - Xác thực OTP và tạo tài khoản
+}
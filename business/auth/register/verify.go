@@ -0,0 +1,137 @@
+package register
+
+import (
+	"errors"
+	"net/http"
+
+	"api/business/auth/otp"
+	"api/business/auth/password"
+	"api/business/auth/session"
+	"api/business/invitations"
+	"api/internal/jwt"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/invitationcol"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type VerifyRequest struct {
+	SessionToken string `json:"session_token" binding:"required"`
+	Code         string `json:"code" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+	InviteToken  string `json:"invite_token"`
+	DeviceId     string `json:"device_id"`
+	DeviceName   string `json:"device_name"`
+	DeviceToken  string `json:"device_token"`
+	Platform     string `json:"platform"`
+}
+
+type VerifyResponse struct {
+	session.Tokens
+	User usercol.User `json:"user"`
+}
+
+// Verify xác thực mã OTP đăng ký, tạo tài khoản với vai trò nhân viên và đăng nhập luôn
+func Verify() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][register][verify]")
+
+	return func(c *gin.Context) {
+		var req VerifyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		res, err := doVerify(c, req, logger)
+		if err != nil {
+			logger.Err(err).Msg("failed to verify registration")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doVerify(c *gin.Context, req VerifyRequest, logger plog.Logger) (*VerifyResponse, error) {
+	ctx := c.Request.Context()
+
+	hashed, err := password.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// invite-only: the invitation token is checked again, it may have been revoked while the code was pending
+	var invitation *invitationcol.Invitation
+	if Mode() == ModeInvite || req.InviteToken != "" {
+		if req.InviteToken == "" {
+			return nil, errors.New("REGISTRATION_CLOSED: an invitation is required")
+		}
+		if invitation, err = invitations.VerifyToken(ctx, req.InviteToken); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := otp.Verify(ctx, req.SessionToken, req.Code, jwt.SessionTypeRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation != nil && invitation.Email != record.Email {
+		return nil, errors.New("INVITATION_EMAIL_MISMATCH")
+	}
+
+	// the email may have been taken while the code was pending
+	_, err = usercol.FindWithEmail(ctx, record.Email)
+	if err == nil {
+		return nil, errors.New("ACCOUNT_EXIST")
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	newUser := &usercol.User{
+		Email:         record.Email,
+		FullName:      record.Name,
+		Role:          usercol.RoleEmployee,
+		Password:      hashed,
+		IsSetPassword: true,
+		IsVerifyEmail: true,
+	}
+	if _, err = usercol.Create(ctx, newUser); err != nil {
+		return nil, err
+	}
+
+	// the email was just verified by OTP, apply the invitation (role, team)
+	if invitation != nil {
+		err = invitations.AcceptInvitation(ctx, newUser, invitation)
+	} else {
+		err = invitations.Accept(ctx, newUser)
+	}
+	if err != nil {
+		logger.Err(err).Str("email", newUser.Email).Msg("failed to apply invitation")
+		return nil, err
+	}
+
+	tokens, err := session.Issue(c, newUser, session.DeviceInfo{
+		DeviceID:    req.DeviceId,
+		DeviceName:  req.DeviceName,
+		DeviceToken: req.DeviceToken,
+		Platform:    req.Platform,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerifyResponse{
+		Tokens: *tokens,
+		User:   *newUser,
+	}, nil
+}
@@ -1,15 +1,45 @@
 package auth
 
 import (
+	"api/business/auth/forgotpass"
 	"api/business/auth/google"
+	"api/business/auth/login"
+	"api/business/auth/mfa"
+	"api/business/auth/oauth"
+	"api/business/auth/oidc"
+	"api/business/auth/password"
+	"api/business/auth/register"
+	"api/business/auth/session"
+	"api/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func AddRouter(r *gin.RouterGroup) {
 
+	r.POST("/login", login.Login())
+	r.POST("/refresh", session.RefreshToken())
+	r.POST("/logout", middleware.AuthMiddleware(), session.Logout())
+
+	registerGroup := r.Group("register")
+	register.Router(registerGroup)
+
+	forgotPassGroup := r.Group("forgot-password")
+	forgotpass.Router(forgotPassGroup)
+
+	mfaGroup := r.Group("mfa")
+	mfa.Router(mfaGroup)
+
+	passwordGroup := r.Group("password")
+	password.Router(passwordGroup)
+
+	r.POST("/oauth/exchange", oauth.Exchange())
+
 	googleGroup := r.Group("google")
 	google.Router(googleGroup)
 
+	// Generic OIDC providers (Entra ID, Keycloak, ...), static routes above take precedence
+	oidc.Router(r)
+
 	// QR login - removed
 }
@@ -0,0 +1,124 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	"api/internal/timer"
+	"api/schema/userdevicecol"
+	"api/schema/usersessioncol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RegisterDevice ghi nhận thiết bị đăng nhập của user, các thiết bị khác vẫn giữ nguyên trạng thái
+func RegisterDevice(c *gin.Context, userId string, device DeviceInfo) error {
+	ctx := c.Request.Context()
+
+	if device.DeviceID == "" {
+		return nil
+	}
+
+	existing, err := userdevicecol.FindWithDeviceId(ctx, userId, device.DeviceID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	if existing == nil {
+		newDevice := &userdevicecol.UserDevice{
+			CreatedAt:   timer.Now(),
+			UpdatedAt:   timer.Now(),
+			IP:          c.ClientIP(),
+			UserId:      userId,
+			DeviceID:    device.DeviceID,
+			DeviceName:  device.DeviceName,
+			DeviceToken: device.DeviceToken,
+			Platform:    device.Platform,
+			IsEnable:    true,
+			IsCurrent:   true,
+			LastLoginAt: timer.Now(),
+		}
+		_, err = userdevicecol.Create(ctx, newDevice)
+		return err
+	}
+
+	existing.IP = c.ClientIP()
+	existing.IsEnable = true
+	existing.IsCurrent = true
+	existing.LastLoginAt = timer.Now()
+	existing.UpdatedAt = timer.Now()
+	if device.DeviceName != "" {
+		existing.DeviceName = device.DeviceName
+	}
+	if device.DeviceToken != "" {
+		existing.DeviceToken = device.DeviceToken
+	}
+	if device.Platform != "" {
+		existing.Platform = device.Platform
+	}
+
+	_, err = userdevicecol.Update(ctx, existing)
+	return err
+}
+
+// Revoke thu hồi một phiên đăng nhập và tắt thiết bị của phiên khi thiết bị không còn phiên nào khác
+func Revoke(ctx context.Context, sessionId, reason string) error {
+	userSession, err := usersessioncol.FindWithId(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+
+	if err = usersessioncol.Revoke(ctx, sessionId, reason); err != nil {
+		return err
+	}
+
+	return disableDevices(ctx, userSession.UserId, []*usersessioncol.UserSession{userSession})
+}
+
+// RevokeAllExcept thu hồi mọi phiên của user trừ phiên keepId và tắt các thiết bị không còn phiên nào
+func RevokeAllExcept(ctx context.Context, userId, keepId, reason string) (int64, error) {
+	sessions, err := usersessioncol.FindActiveByUser(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := usersessioncol.RevokeAllExcept(ctx, userId, keepId, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	targets := make([]*usersessioncol.UserSession, 0, len(sessions))
+	for _, s := range sessions {
+		if s.GetIDString() != keepId {
+			targets = append(targets, s)
+		}
+	}
+
+	return revoked, disableDevices(ctx, userId, targets)
+}
+
+// disableDevices tắt thiết bị của các phiên vừa thu hồi, trừ thiết bị vẫn còn phiên đang hoạt động
+func disableDevices(ctx context.Context, userId string, revoked []*usersessioncol.UserSession) error {
+	active, err := usersessioncol.FindActiveByUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	inUse := make(map[string]bool, len(active))
+	for _, s := range active {
+		inUse[s.DeviceID] = true
+	}
+
+	for _, s := range revoked {
+		if s.DeviceID == "" || inUse[s.DeviceID] {
+			continue
+		}
+		if err = userdevicecol.Disable(ctx, userId, s.DeviceID); err != nil {
+			return err
+		}
+		inUse[s.DeviceID] = true
+	}
+
+	return nil
+}
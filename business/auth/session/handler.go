@@ -0,0 +1,65 @@
+package session
+
+import (
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken cấp access token mới từ refresh token
+func RefreshToken() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][session][refresh]")
+
+	return func(c *gin.Context) {
+		var req RefreshRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		res, err := Refresh(c.Request.Context(), req.RefreshToken)
+		if err != nil {
+			logger.Err(err).Msg("failed to refresh token")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+// Logout thu hồi phiên đăng nhập hiện tại
+func Logout() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][auth][session][logout]")
+
+	return func(c *gin.Context) {
+		sessionId := c.GetString("session_id")
+		if sessionId == "" {
+			code := response.ErrorResponse("SESSION_REVOKED")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if err := Revoke(c.Request.Context(), sessionId, RevokeReasonLogout); err != nil {
+			logger.Err(err).Msg("failed to revoke session")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
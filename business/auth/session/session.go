@@ -0,0 +1,202 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"api/internal/jwt"
+	"api/internal/timer"
+	"api/internal/utils"
+	"api/schema/usercol"
+	"api/schema/usersessioncol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	AccessTokenLifetime  = 15 * 60           // 15 minutes
+	RefreshTokenLifetime = 30 * 24 * 60 * 60 // 30 days
+	TokenIssuer          = "btcland"
+
+	refreshTokenIdLength = 32
+
+	defaultMaxSessionsPerUser = 5
+)
+
+const (
+	RevokeReasonLogout       = "logout"
+	RevokeReasonRefreshReuse = "refresh_token_reuse"
+	RevokeReasonSessionLimit = "session_limit"
+	RevokeReasonUser         = "revoked_by_user"
+)
+
+type DeviceInfo struct {
+	DeviceID    string `json:"device_id"`
+	DeviceName  string `json:"device_name"`
+	DeviceToken string `json:"device_token"`
+	BrowserName string `json:"browser_name"`
+	Platform    string `json:"platform"`
+}
+
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// MaxSessionsPerUser trả về số phiên đăng nhập đồng thời tối đa của một user (env MAX_SESSIONS_PER_USER)
+func MaxSessionsPerUser() int {
+	limit, err := strconv.Atoi(os.Getenv("MAX_SESSIONS_PER_USER"))
+	if err != nil || limit <= 0 {
+		return defaultMaxSessionsPerUser
+	}
+	return limit
+}
+
+// Issue tạo phiên đăng nhập mới cho user và cấp cặp access/refresh token.
+// Khi vượt quá số phiên cho phép, các phiên ít hoạt động nhất sẽ bị thu hồi
+func Issue(c *gin.Context, user *usercol.User, device DeviceInfo) (*Tokens, error) {
+	if err := enforceSessionLimit(c.Request.Context(), user.GetIDString()); err != nil {
+		return nil, err
+	}
+
+	if err := RegisterDevice(c, user.GetIDString(), device); err != nil {
+		return nil, err
+	}
+
+	tokenId := utils.RandomString(refreshTokenIdLength)
+
+	newSession := &usersessioncol.UserSession{
+		ActiveAt:         timer.Now(),
+		UserId:           user.GetIDString(),
+		IsDelete:         false,
+		IsEnable:         true,
+		IP:               c.ClientIP(),
+		DeviceID:         device.DeviceID,
+		DeviceName:       device.DeviceName,
+		DeviceToken:      device.DeviceToken,
+		BrowserName:      device.BrowserName,
+		Platform:         device.Platform,
+		RefreshTokenHash: hashTokenId(tokenId),
+		RefreshExpireAt:  timer.Now().Add(RefreshTokenLifetime * time.Second),
+	}
+	if newSession.DeviceName == "" {
+		newSession.DeviceName = c.GetHeader("User-Agent")
+	}
+
+	_, err := usersessioncol.Create(c.Request.Context(), newSession)
+	if err != nil {
+		return nil, err
+	}
+
+	return generateTokens(user.GetIDString(), newSession.GetIDString(), tokenId)
+}
+
+// Refresh xoay vòng refresh token. Nếu một refresh token cũ bị dùng lại, toàn bộ phiên sẽ bị thu hồi
+func Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	claim, err := jwt.VerifySessionToken(os.Getenv("KEY_API_KEY"), refreshToken)
+	if err != nil {
+		return nil, errors.New("REFRESH_TOKEN_INVALID")
+	}
+
+	if claim.Type != jwt.SessionTypeRefresh || claim.ID == "" {
+		return nil, errors.New("REFRESH_TOKEN_INVALID")
+	}
+
+	userSession, err := usersessioncol.FindWithId(ctx, claim.SessionId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("SESSION_REVOKED")
+		}
+		return nil, err
+	}
+
+	if userSession.IsDelete || !userSession.IsEnable {
+		return nil, errors.New("SESSION_REVOKED")
+	}
+
+	if !userSession.RefreshExpireAt.IsZero() && timer.Now().After(userSession.RefreshExpireAt) {
+		return nil, errors.New("REFRESH_TOKEN_EXPIRED")
+	}
+
+	user, err := usercol.FindWithUserID(ctx, userSession.UserId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("SESSION_REVOKED")
+		}
+		return nil, err
+	}
+
+	if user.IsDelete {
+		return nil, errors.New("USER_IS_DELETED")
+	}
+
+	newTokenId := utils.RandomString(refreshTokenIdLength)
+	rotated, err := usersessioncol.RotateRefreshToken(
+		ctx,
+		userSession.GetIDString(),
+		hashTokenId(claim.ID),
+		hashTokenId(newTokenId),
+		timer.Now().Add(RefreshTokenLifetime*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the presented token is not the current one: it was already rotated, so treat it as stolen
+	if !rotated {
+		if err = Revoke(ctx, userSession.GetIDString(), RevokeReasonRefreshReuse); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("REFRESH_TOKEN_REUSED")
+	}
+
+	return generateTokens(user.GetIDString(), userSession.GetIDString(), newTokenId)
+}
+
+func enforceSessionLimit(ctx context.Context, userId string) error {
+	sessions, err := usersessioncol.FindActiveByUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	// keep room for the session about to be created
+	for i := MaxSessionsPerUser() - 1; i < len(sessions); i++ {
+		if err = Revoke(ctx, sessions[i].GetIDString(), RevokeReasonSessionLimit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func generateTokens(userId, sessionId, tokenId string) (*Tokens, error) {
+	key := os.Getenv("KEY_API_KEY")
+
+	accessToken, err := jwt.GenerateJWTToken(key, userId, sessionId, "", TokenIssuer, AccessTokenLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jwt.GenerateRefreshToken(key, sessionId, tokenId, TokenIssuer, RefreshTokenLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    AccessTokenLifetime,
+	}, nil
+}
+
+func hashTokenId(tokenId string) string {
+	sum := sha256.Sum256([]byte(tokenId))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,7 @@
 package dashboard
 
 import (
+	"api/business/rbac"
 	"api/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -9,8 +10,9 @@ import (
 func Router(r *gin.RouterGroup) {
 	// Tất cả routes đều yêu cầu authentication
 	r.Use(middleware.AuthMiddleware())
+	r.Use(middleware.Require(rbac.DashboardView))
 
-	// Routes cho Lãnh đạo (Leader) - Dashboard và thống kê
+	// Routes cho Lãnh đạo và Trợ lý giám đốc - Dashboard và thống kê
 	r.GET("stats", Stats())                                     // GET /dashboard/stats - Thống kê tổng quan
 	r.GET("work-confirmations-stats", WorkConfirmationsStats()) // GET /dashboard/work-confirmations-stats - Thống kê đơn xác nhận
 	r.GET("teams-stats", TeamsStats())                          // GET /dashboard/teams-stats - Thống kê theo phòng ban/team
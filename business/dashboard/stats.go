@@ -17,31 +17,6 @@ func Stats() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][dashboard][stats]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can view dashboard stats")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		ctx := c.Request.Context()
 
 		// Đếm tổng số phòng ban/team
@@ -97,6 +72,13 @@ func Stats() gin.HandlerFunc {
 		}
 		pendingLeaderCount, _ := workConfirmationsColl.CountWithCtx(ctx, pendingLeaderFilter)
 
+		// Tổng đơn chờ duyệt ở mọi bước, kể cả các bước của quy trình tuỳ chỉnh
+		pendingFilter := primitive.D{
+			{Key: "status", Value: primitive.Regex{Pattern: "^" + workconfirmationcol.StatusPendingPrefix}},
+			{Key: "is_delete", Value: false},
+		}
+		pendingCount, _ := workConfirmationsColl.CountWithCtx(ctx, pendingFilter)
+
 		approvedFilter := primitive.D{
 			{Key: "status", Value: workconfirmationcol.StatusApproved},
 			{Key: "is_delete", Value: false},
@@ -109,6 +91,12 @@ func Stats() gin.HandlerFunc {
 		}
 		rejectedCount, _ := workConfirmationsColl.CountWithCtx(ctx, rejectedFilter)
 
+		cancelledFilter := primitive.D{
+			{Key: "status", Value: workconfirmationcol.StatusCancelled},
+			{Key: "is_delete", Value: false},
+		}
+		cancelledCount, _ := workConfirmationsColl.CountWithCtx(ctx, cancelledFilter)
+
 		responseData := map[string]interface{}{
 			"teams": map[string]interface{}{
 				"total": totalTeams,
@@ -121,8 +109,10 @@ func Stats() gin.HandlerFunc {
 				"total":            totalWorkConfirmations,
 				"pending_manager":  pendingManagerCount,
 				"pending_leader":   pendingLeaderCount,
+				"pending":          pendingCount,
 				"approved":         approvedCount,
 				"rejected":         rejectedCount,
+				"cancelled":        cancelledCount,
 			},
 		}
 
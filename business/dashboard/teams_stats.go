@@ -18,31 +18,6 @@ func TeamsStats() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][dashboard][teams_stats]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can view teams stats")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		ctx := c.Request.Context()
 
 		// Lấy tất cả teams
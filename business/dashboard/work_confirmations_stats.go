@@ -7,7 +7,6 @@ import (
 	"api/internal/response"
 	"api/schema/teamcol"
 	"api/schema/teammembercol"
-	"api/schema/usercol"
 	"api/schema/workconfirmationcol"
 
 	"github.com/gin-gonic/gin"
@@ -18,31 +17,6 @@ func WorkConfirmationsStats() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][dashboard][work_confirmations_stats]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can view work confirmations stats")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		ctx := c.Request.Context()
 		workConfirmationsColl := workconfirmationcol.Collection()
 
@@ -82,8 +56,10 @@ func WorkConfirmationsStats() gin.HandlerFunc {
 					"by_status": map[string]int64{
 						"pending_manager": 0,
 						"pending_leader":  0,
+						"pending":         0,
 						"approved":        0,
 						"rejected":        0,
+						"cancelled":       0,
 					},
 				}
 				c.JSON(http.StatusOK, response.SuccessResponse(responseData))
@@ -105,19 +81,28 @@ func WorkConfirmationsStats() gin.HandlerFunc {
 		pendingLeaderFilter := append(baseFilter, primitive.E{Key: "status", Value: workconfirmationcol.StatusPendingLeader})
 		pendingLeaderCount, _ := workConfirmationsColl.CountWithCtx(ctx, pendingLeaderFilter)
 
+		// Tổng đơn chờ duyệt ở mọi bước, kể cả các bước của quy trình tuỳ chỉnh
+		pendingFilter := append(baseFilter, primitive.E{Key: "status", Value: primitive.Regex{Pattern: "^" + workconfirmationcol.StatusPendingPrefix}})
+		pendingCount, _ := workConfirmationsColl.CountWithCtx(ctx, pendingFilter)
+
 		approvedFilter := append(baseFilter, primitive.E{Key: "status", Value: workconfirmationcol.StatusApproved})
 		approvedCount, _ := workConfirmationsColl.CountWithCtx(ctx, approvedFilter)
 
 		rejectedFilter := append(baseFilter, primitive.E{Key: "status", Value: workconfirmationcol.StatusRejected})
 		rejectedCount, _ := workConfirmationsColl.CountWithCtx(ctx, rejectedFilter)
 
+		cancelledFilter := append(baseFilter, primitive.E{Key: "status", Value: workconfirmationcol.StatusCancelled})
+		cancelledCount, _ := workConfirmationsColl.CountWithCtx(ctx, cancelledFilter)
+
 		responseData := map[string]interface{}{
 			"total": total,
 			"by_status": map[string]int64{
 				"pending_manager": pendingManagerCount,
 				"pending_leader":  pendingLeaderCount,
+				"pending":         pendingCount,
 				"approved":        approvedCount,
 				"rejected":        rejectedCount,
+				"cancelled":       cancelledCount,
 			},
 		}
 
@@ -0,0 +1,108 @@
+package delegations
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"api/business/rbac"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/delegationcol"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRequest struct {
+	DelegatorID string   `json:"delegator_id"` // Chỉ người quản lý tất cả team được uỷ quyền thay người khác
+	DelegateID  string   `json:"delegate_id" binding:"required"`
+	TeamIDs     []string `json:"team_ids"`
+	StartDate   string   `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate     string   `json:"end_date" binding:"required"`   // YYYY-MM-DD
+	Reason      string   `json:"reason"`
+}
+
+// Create uỷ quyền duyệt đơn cho người khác trong một khoảng ngày, có thể giới hạn theo team
+func Create() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][delegations][create]")
+
+	return func(c *gin.Context) {
+		var req CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		delegation, err := doCreate(c, user, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to create delegation")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(delegation))
+	}
+}
+
+func doCreate(c *gin.Context, user *usercol.User, req CreateRequest) (*delegationcol.Delegation, error) {
+	ctx := c.Request.Context()
+
+	delegator := user
+	if req.DelegatorID != "" && req.DelegatorID != user.GetIDString() {
+		if !rbac.Can(user, rbac.TeamManage) {
+			return nil, errors.New("PERMISSION_DENIED: you can only delegate your own approvals")
+		}
+
+		var err error
+		if delegator, err = findActiveUser(ctx, req.DelegatorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if !canDelegate(delegator) {
+		return nil, errors.New("INVALID_PARAM: delegator has no approval permission")
+	}
+
+	if req.DelegateID == delegator.GetIDString() {
+		return nil, errors.New("INVALID_PARAM: cannot delegate to yourself")
+	}
+
+	if _, err := findActiveUser(ctx, req.DelegateID); err != nil {
+		return nil, err
+	}
+
+	if err := validateDates(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	if err := validateTeams(ctx, user, delegator.GetIDString(), req.TeamIDs); err != nil {
+		return nil, err
+	}
+
+	delegation := &delegationcol.Delegation{
+		DelegatorID: delegator.GetIDString(),
+		DelegateID:  req.DelegateID,
+		TeamIDs:     req.TeamIDs,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Reason:      strings.TrimSpace(req.Reason),
+		CreatedBy:   user.GetIDString(),
+	}
+
+	if _, err := delegationcol.Create(ctx, delegation); err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
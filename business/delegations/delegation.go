@@ -0,0 +1,103 @@
+package delegations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"api/business/rbac"
+	"api/internal/timer"
+	"api/schema/delegationcol"
+	"api/schema/teamcol"
+	"api/schema/usercol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const dateLayout = "2006-01-02"
+
+// canDelegate kiểm tra user có quyền duyệt để uỷ quyền lại không
+func canDelegate(user *usercol.User) bool {
+	return rbac.CanAny(user, rbac.WorkConfirmationApprove, rbac.WorkConfirmationApproveFinal)
+}
+
+// canManage kiểm tra user có được sửa/thu hồi uỷ quyền không: người uỷ quyền, người tạo hoặc người quản lý tất cả team
+func canManage(user *usercol.User, d *delegationcol.Delegation) bool {
+	id := user.GetIDString()
+	return d.DelegatorID == id || d.CreatedBy == id || rbac.Can(user, rbac.TeamManage)
+}
+
+// canView kiểm tra user có được xem uỷ quyền không, người được uỷ quyền cũng xem được
+func canView(user *usercol.User, d *delegationcol.Delegation) bool {
+	return canManage(user, d) || d.DelegateID == user.GetIDString()
+}
+
+func findDelegation(ctx context.Context, id string) (*delegationcol.Delegation, error) {
+	delegation, err := delegationcol.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("DELEGATION_NOT_FOUND")
+		}
+		return nil, err
+	}
+	return delegation, nil
+}
+
+// findActiveUser tìm user còn hoạt động theo id
+func findActiveUser(ctx context.Context, id string) (*usercol.User, error) {
+	user, err := usercol.FindWithUserID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("ACCOUNT_NOT_FOUND")
+		}
+		return nil, err
+	}
+	if user.IsDelete {
+		return nil, errors.New("ACCOUNT_NOT_FOUND")
+	}
+	return user, nil
+}
+
+// validateDates kiểm tra khoảng ngày hợp lệ và chưa kết thúc
+func validateDates(startDate, endDate string) error {
+	start, err := time.Parse(dateLayout, startDate)
+	if err != nil {
+		return errors.New("INVALID_PARAM: start_date must be in YYYY-MM-DD format")
+	}
+
+	end, err := time.Parse(dateLayout, endDate)
+	if err != nil {
+		return errors.New("INVALID_PARAM: end_date must be in YYYY-MM-DD format")
+	}
+
+	if end.Before(start) {
+		return errors.New("INVALID_PARAM: end_date must not be before start_date")
+	}
+
+	if endDate < timer.Now().Format(dateLayout) {
+		return errors.New("INVALID_PARAM: end_date must not be in the past")
+	}
+
+	return nil
+}
+
+// validateTeams kiểm tra các team tồn tại. Người không quản lý tất cả team chỉ được giới hạn theo team của người uỷ quyền
+func validateTeams(ctx context.Context, user *usercol.User, delegatorId string, teamIds []string) error {
+	for _, id := range teamIds {
+		team, err := teamcol.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return errors.New("TEAM_NOT_FOUND")
+			}
+			return err
+		}
+		if team.IsDelete {
+			return errors.New("TEAM_NOT_FOUND")
+		}
+
+		if !rbac.Can(user, rbac.TeamManage) && team.ManagerID != delegatorId {
+			return errors.New("PERMISSION_DENIED: you do not manage this team")
+		}
+	}
+	return nil
+}
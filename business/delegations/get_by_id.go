@@ -0,0 +1,36 @@
+package delegations
+
+import (
+	"net/http"
+
+	"api/internal/response"
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetByID xem chi tiết uỷ quyền, chỉ người liên quan hoặc người quản lý tất cả team xem được
+func GetByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		delegation, err := findDelegation(c.Request.Context(), c.Param("id"))
+		if err == nil && !canView(user, delegation) {
+			code := response.ErrorResponse("DELEGATION_NOT_FOUND")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(delegation))
+	}
+}
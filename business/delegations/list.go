@@ -0,0 +1,93 @@
+package delegations
+
+import (
+	"net/http"
+	"strconv"
+
+	"api/business/rbac"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/delegationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// List lấy danh sách uỷ quyền. type=given: uỷ quyền đã cấp, type=received: uỷ quyền được nhận,
+// bỏ trống: cả hai (người quản lý tất cả team xem được tất cả). active=true chỉ lấy uỷ quyền đang có hiệu lực
+func List() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][delegations][list]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		skip := (page - 1) * limit
+		findOptions := options.Find().
+			SetSkip(int64(skip)).
+			SetLimit(int64(limit)).
+			SetSort(primitive.D{{Key: "created_at", Value: -1}})
+
+		userID := user.GetIDString()
+		filter := primitive.D{}
+		switch c.Query("type") {
+		case "given":
+			filter = bsonutil.BsonAdd(filter, "delegator_id", userID)
+		case "received":
+			filter = bsonutil.BsonAdd(filter, "delegate_id", userID)
+		default:
+			if !rbac.Can(user, rbac.TeamManage) {
+				filter = bsonutil.BsonAdd(filter, "$or", []primitive.M{
+					{"delegator_id": userID},
+					{"delegate_id": userID},
+					{"created_by": userID},
+				})
+			}
+		}
+
+		if c.Query("active") == "true" {
+			today := timer.Now().Format(dateLayout)
+			filter = bsonutil.BsonAdd(filter, "revoked_at", primitive.M{"$exists": false})
+			filter = bsonutil.BsonLessThanEqual(filter, "start_date", today)
+			filter = bsonutil.BsonGreaterThanEqual(filter, "end_date", today)
+		}
+
+		delegations, count, err := delegationcol.FindWithFilter(c.Request.Context(), filter, findOptions)
+		if err != nil {
+			logger.Err(err).Msg("failed to list delegations")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list delegations"))
+			c.Abort()
+			return
+		}
+
+		if delegations == nil {
+			delegations = []*delegationcol.Delegation{}
+		}
+
+		responseData := map[string]interface{}{
+			"data":       delegations,
+			"total":      count,
+			"page":       page,
+			"limit":      limit,
+			"total_page": (count + int64(limit) - 1) / int64(limit),
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(responseData))
+	}
+}
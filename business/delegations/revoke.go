@@ -0,0 +1,51 @@
+package delegations
+
+import (
+	"errors"
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/delegationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Revoke thu hồi uỷ quyền, người được uỷ quyền không còn duyệt thay được
+func Revoke() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][delegations][revoke]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		delegation, err := findDelegation(c.Request.Context(), c.Param("id"))
+		if err == nil && !canManage(user, delegation) {
+			err = errors.New("DELEGATION_NOT_FOUND")
+		}
+		if err == nil && !delegation.RevokedAt.IsZero() {
+			err = errors.New("INVALID_PARAM: delegation has already been revoked")
+		}
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		delegation.RevokedAt = timer.Now()
+		delegation.RevokedBy = user.GetIDString()
+		if _, err = delegationcol.Update(c.Request.Context(), delegation); err != nil {
+			logger.Err(err).Msg("failed to revoke delegation")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to revoke delegation"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(delegation))
+	}
+}
@@ -0,0 +1,22 @@
+package delegations
+
+import (
+	"api/business/rbac"
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Tất cả routes đều yêu cầu authentication
+	r.Use(middleware.AuthMiddleware())
+
+	// Người được uỷ quyền cũng cần xem được uỷ quyền của mình
+	r.GET("", List())       // GET /delegations - Danh sách uỷ quyền
+	r.GET(":id", GetByID()) // GET /delegations/:id - Chi tiết uỷ quyền
+
+	// Routes cho Quản lý và Lãnh đạo - uỷ quyền duyệt đơn khi vắng mặt
+	r.POST("", middleware.Require(rbac.WorkConfirmationApprove, rbac.WorkConfirmationApproveFinal), Create()) // POST /delegations - Tạo uỷ quyền
+	r.PUT(":id", Update())                                                                                    // PUT /delegations/:id - Sửa uỷ quyền
+	r.DELETE(":id", Revoke())                                                                                 // DELETE /delegations/:id - Thu hồi uỷ quyền
+}
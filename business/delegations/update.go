@@ -0,0 +1,97 @@
+package delegations
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/delegationcol"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateRequest struct {
+	TeamIDs   *[]string `json:"team_ids"`
+	StartDate *string   `json:"start_date"`
+	EndDate   *string   `json:"end_date"`
+	Reason    *string   `json:"reason"`
+}
+
+// Update sửa khoảng ngày, team hoặc lý do của uỷ quyền chưa bị thu hồi
+func Update() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][delegations][update]")
+
+	return func(c *gin.Context) {
+		var req UpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		delegation, err := doUpdate(c, user, req)
+		if err != nil {
+			logger.Err(err).Str("id", c.Param("id")).Msg("failed to update delegation")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(delegation))
+	}
+}
+
+func doUpdate(c *gin.Context, user *usercol.User, req UpdateRequest) (*delegationcol.Delegation, error) {
+	ctx := c.Request.Context()
+
+	delegation, err := findDelegation(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canManage(user, delegation) {
+		return nil, errors.New("DELEGATION_NOT_FOUND")
+	}
+
+	if !delegation.RevokedAt.IsZero() {
+		return nil, errors.New("INVALID_PARAM: delegation has been revoked")
+	}
+
+	if req.StartDate != nil {
+		delegation.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		delegation.EndDate = *req.EndDate
+	}
+	if err = validateDates(delegation.StartDate, delegation.EndDate); err != nil {
+		return nil, err
+	}
+
+	if req.TeamIDs != nil {
+		if err = validateTeams(ctx, user, delegation.DelegatorID, *req.TeamIDs); err != nil {
+			return nil, err
+		}
+		delegation.TeamIDs = *req.TeamIDs
+	}
+
+	if req.Reason != nil {
+		delegation.Reason = strings.TrimSpace(*req.Reason)
+	}
+
+	if _, err = delegationcol.Update(ctx, delegation); err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
@@ -39,31 +39,6 @@ func AddEmployee() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can add employees to teams")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Tìm team
 		team, err := teamcol.FindByID(c.Request.Context(), id)
 		if err != nil {
@@ -92,7 +67,7 @@ func AddEmployee() gin.HandlerFunc {
 		employee, err := usercol.FindWithEmail(c.Request.Context(), req.Email)
 		if err != nil {
 			if errors.Is(err, mongo.ErrNoDocuments) {
-				code := response.ErrorResponse("User with this email not found")
+				code := response.ErrorResponse("User with this email not found, send an invitation instead")
 				c.JSON(http.StatusNotFound, code)
 				c.Abort()
 				return
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"api/business/rbac"
 	"api/internal/plog"
 	"api/internal/response"
 	"api/schema/teamcol"
@@ -37,31 +38,6 @@ func AssignManager() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can assign managers")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Kiểm tra team có tồn tại không
 		_, err := teamcol.FindByID(c.Request.Context(), id)
 		if err != nil {
@@ -94,7 +70,7 @@ func AssignManager() gin.HandlerFunc {
 			return
 		}
 
-		if manager.Role != usercol.RoleManager {
+		if !rbac.Can(manager, rbac.TeamManageOwn) {
 			code := response.ErrorResponse("User must be a manager")
 			c.JSON(http.StatusBadRequest, code)
 			c.Abort()
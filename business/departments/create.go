@@ -3,6 +3,7 @@ package departments
 import (
 	"net/http"
 
+	"api/business/rbac"
 	"api/internal/plog"
 	"api/internal/response"
 	"api/schema/teamcol"
@@ -29,31 +30,6 @@ func Create() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can create teams")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Kiểm tra manager_id nếu có
 		if req.ManagerID != "" {
 			manager, err := usercol.FindWithUserID(c.Request.Context(), req.ManagerID)
@@ -65,7 +41,7 @@ func Create() gin.HandlerFunc {
 			}
 
 			// Kiểm tra manager có role là manager không
-			if manager.Role != usercol.RoleManager {
+			if !rbac.Can(manager, rbac.TeamManageOwn) {
 				code := response.ErrorResponse("User must be a manager")
 				c.JSON(http.StatusBadRequest, code)
 				c.Abort()
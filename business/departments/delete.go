@@ -7,7 +7,6 @@ import (
 	"api/internal/plog"
 	"api/internal/response"
 	"api/schema/teamcol"
-	"api/schema/usercol"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -25,31 +24,6 @@ func Delete() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can delete teams")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Kiểm tra team có tồn tại không
 		team, err := teamcol.FindByID(c.Request.Context(), id)
 		if err != nil {
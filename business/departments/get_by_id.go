@@ -26,31 +26,6 @@ func GetByID() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can view team details")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Tìm team
 		team, err := teamcol.FindByID(c.Request.Context(), id)
 		if err != nil {
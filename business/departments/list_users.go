@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"api/business/rbac"
 	"api/internal/plog"
 	"api/internal/response"
 	"api/schema/usercol"
@@ -17,31 +18,6 @@ func ListUsers() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][departments][list_users]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can view all users")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Parse query parameters
 		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100")) // Default 100 để lấy nhiều users
@@ -58,17 +34,8 @@ func ListUsers() gin.HandlerFunc {
 		filter := primitive.D{}
 		if roleFilter != "" {
 			// Validate role
-			var role usercol.Role
-			switch roleFilter {
-			case "manager":
-				role = usercol.RoleManager
-			case "employee":
-				role = usercol.RoleEmployee
-			case "leader":
-				role = usercol.RoleLeader
-			case "assistant_director":
-				role = usercol.RoleAssistantDirector
-			default:
+			role := usercol.Role(roleFilter)
+			if !rbac.RoleExists(role) {
 				code := response.ErrorResponse("Invalid role filter")
 				c.JSON(http.StatusBadRequest, code)
 				c.Abort()
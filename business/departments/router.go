@@ -1,6 +1,7 @@
 package departments
 
 import (
+	"api/business/rbac"
 	"api/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -10,16 +11,18 @@ func Router(r *gin.RouterGroup) {
 	// Tất cả routes đều yêu cầu authentication
 	r.Use(middleware.AuthMiddleware())
 
+	manage := middleware.Require(rbac.TeamManage)
+
 	// Routes cho Lãnh đạo (Leader) - quản lý tất cả teams/departments
-	r.GET("", List())                                              // GET /teams - Lấy danh sách tất cả team (chỉ lãnh đạo)
-	r.GET("users", ListUsers())                                    // GET /teams/users - Lấy danh sách tất cả users (chỉ lãnh đạo)
-	r.PUT("users/:user_id/role", UpdateUserRole())                 // PUT /teams/users/:user_id/role - Cập nhật vai trò của user (chỉ lãnh đạo)
-	r.GET(":id", GetByID())                                        // GET /teams/:id - Lấy chi tiết team
-	r.POST("", Create())                                           // POST /teams - Tạo team mới
-	r.PUT(":id", Update())                                         // PUT /teams/:id - Cập nhật team
-	r.DELETE(":id", Delete())                                      // DELETE /teams/:id - Xóa team
-	r.POST(":id/assign-manager", AssignManager())                  // POST /teams/:id/assign-manager - Gán quản lý
-	r.GET(":id/employees", ListEmployees())                        // GET /teams/:id/employees - Lấy danh sách nhân viên
-	r.POST(":id/add-employee", AddEmployee())                      // POST /teams/:id/add-employee - Thêm nhân viên
-	r.DELETE(":id/remove-employee/:employee_id", RemoveEmployee()) // DELETE /teams/:id/remove-employee/:employee_id - Xóa nhân viên
+	r.GET("", manage, List())                                                               // GET /teams - Lấy danh sách tất cả team
+	r.GET("users", middleware.Require(rbac.UserView), ListUsers())                          // GET /teams/users - Lấy danh sách tất cả users
+	r.PUT("users/:user_id/role", middleware.Require(rbac.UserAssignRole), UpdateUserRole()) // PUT /teams/users/:user_id/role - Cập nhật vai trò của user
+	r.GET(":id", manage, GetByID())                                                         // GET /teams/:id - Lấy chi tiết team
+	r.POST("", manage, Create())                                                            // POST /teams - Tạo team mới
+	r.PUT(":id", manage, Update())                                                          // PUT /teams/:id - Cập nhật team
+	r.DELETE(":id", manage, Delete())                                                       // DELETE /teams/:id - Xóa team
+	r.POST(":id/assign-manager", manage, AssignManager())                                   // POST /teams/:id/assign-manager - Gán quản lý
+	r.GET(":id/employees", manage, ListEmployees())                                         // GET /teams/:id/employees - Lấy danh sách nhân viên
+	r.POST(":id/add-employee", manage, AddEmployee())                                       // POST /teams/:id/add-employee - Thêm nhân viên
+	r.DELETE(":id/remove-employee/:employee_id", manage, RemoveEmployee())                  // DELETE /teams/:id/remove-employee/:employee_id - Xóa nhân viên
 }
@@ -1,11 +1,14 @@
 package departments
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
+	"api/schema/rolecol"
 	"api/schema/usercol"
 
 	"github.com/gin-gonic/gin"
@@ -38,45 +41,19 @@ func UpdateUserRole() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
-		// Kiểm tra role - chỉ leader mới có quyền
-		if user.Role != usercol.RoleLeader {
-			code := response.ErrorResponse("Only leaders can update user roles")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
-		// Validate role
-		var newRole usercol.Role
-		switch req.Role {
-		case "employee":
-			newRole = usercol.RoleEmployee
-		case "manager":
-			newRole = usercol.RoleManager
-		case "leader":
-			newRole = usercol.RoleLeader
-		case "assistant_director":
-			newRole = usercol.RoleAssistantDirector
-		default:
-			code := response.ErrorResponse("Invalid role. Must be one of: employee, manager, leader, assistant_director")
-			c.JSON(http.StatusBadRequest, code)
+		// Validate role theo collection vai trò, cache quyền của instance có thể chưa thấy vai trò vừa tạo/xoá
+		newRole := usercol.Role(req.Role)
+		if exists, err := roleExists(c.Request.Context(), newRole); err != nil || !exists {
+			if err != nil {
+				logger.Err(err).Msg("failed to get role")
+			}
+			code := response.ErrorResponse("ROLE_NOT_FOUND")
+			c.JSON(code.Code, code)
 			c.Abort()
 			return
 		}
@@ -127,6 +104,17 @@ func UpdateUserRole() gin.HandlerFunc {
 			return
 		}
 
+		// Vai trò có thể vừa bị xoá giữa lúc kiểm tra và lúc cập nhật, khi đó trả lại vai trò cũ
+		if exists, err := roleExists(c.Request.Context(), newRole); err == nil && !exists {
+			if _, err = usercol.UpdateByID(c.Request.Context(), objID, bson.M{"role": targetUser.Role}); err != nil {
+				logger.Err(err).Msg("failed to restore user role")
+			}
+			code := response.ErrorResponse("ROLE_NOT_FOUND")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
 		// Format response
 		responseData := map[string]interface{}{
 			"id":        updatedUser.GetIDString(),
@@ -140,3 +128,14 @@ func UpdateUserRole() gin.HandlerFunc {
 	}
 }
 
+// roleExists kiểm tra vai trò (hệ thống hoặc tuỳ chỉnh) còn trong collection vai trò
+func roleExists(ctx context.Context, role usercol.Role) (bool, error) {
+	_, err := rolecol.FindByName(ctx, string(role))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
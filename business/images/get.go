@@ -1,16 +1,24 @@
 package images
 
 import (
+	"bytes"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/internal/thumbnail"
 	"api/services/minio"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	variantBucket       = "images"
+	variantSourcePrefix = "work-confirmations/" // Ảnh của đơn công tác, nơi trả URL kèm ?size=
+)
+
 func Get() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][images][get]")
 
@@ -44,6 +52,40 @@ func Get() gin.HandlerFunc {
 		bucket := parts[0]
 		objectKey := parts[1]
 
+		// Ảnh thu nhỏ: ?size=thumb|medium, bỏ trống là ảnh gốc
+		if size := c.Query("size"); size != "" {
+			if !thumbnail.Valid(size) {
+				code := response.ErrorResponse("INVALID_PARAM: size must be thumb or medium")
+				c.JSON(code.Code, code)
+				c.Abort()
+				return
+			}
+
+			// Route công khai: chỉ tạo ảnh thu nhỏ cho ảnh gốc của đơn công tác, không tạo từ ảnh thu nhỏ khác
+			if !variantSource(bucket, objectKey) {
+				code := response.ErrorResponse("INVALID_PARAM: size is not available for this image")
+				c.JSON(code.Code, code)
+				c.Abort()
+				return
+			}
+
+			// WebP cho client chấp nhận, JPEG cho client còn lại. Cache phải tách theo Accept
+			format := thumbnail.JPEG
+			if acceptsWebP(c.GetHeader("Accept")) {
+				format = thumbnail.WebP
+			}
+			c.Header("Vary", "Accept")
+
+			if data, ok := variant(bucket, objectKey, size, format, logger); ok {
+				contentType := thumbnail.ContentType(format)
+				c.Header("Content-Type", contentType)
+				c.Header("Cache-Control", "public, max-age=31536000") // Cache for 1 year
+				c.Data(http.StatusOK, contentType, data)
+				return
+			}
+			// Không tạo được ảnh thu nhỏ (ví dụ HEIC), trả về ảnh gốc
+		}
+
 		// Download file from MinIO
 		fileData, err := minio.DownloadFile(bucket, objectKey)
 		if err != nil {
@@ -71,3 +113,56 @@ func Get() gin.HandlerFunc {
 	}
 }
 
+// variantSource kiểm tra object có được tạo ảnh thu nhỏ: ảnh gốc của đơn công tác trong bucket ảnh
+func variantSource(bucket, objectKey string) bool {
+	return bucket == variantBucket &&
+		strings.HasPrefix(objectKey, variantSourcePrefix) &&
+		!thumbnail.IsVariant(objectKey)
+}
+
+// variant lấy ảnh thu nhỏ đã lưu trên MinIO, chưa có thì tạo từ ảnh gốc rồi lưu lại cho các lần sau.
+// Trả về false nếu không có ảnh gốc hoặc không giải mã được ảnh gốc
+func variant(bucket, objectKey, size, format string, logger plog.Logger) ([]byte, bool) {
+	variantKey := thumbnail.Key(objectKey, size, format)
+	if data, err := minio.DownloadFile(bucket, variantKey); err == nil && len(data) > 0 {
+		return data, true
+	}
+
+	original, err := minio.DownloadFile(bucket, objectKey)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := thumbnail.Generate(original, size, format)
+	if err != nil {
+		logger.Warn().Msgf("cannot generate %s variant: bucket=%s, key=%s: %v", size, bucket, objectKey, err)
+		return nil, false
+	}
+
+	// Lưu lỗi chỉ làm lần sau phải tạo lại
+	if _, err = minio.PutObject(bucket, variantKey, bytes.NewReader(data)); err != nil {
+		logger.Err(err).Msgf("failed to store %s variant: bucket=%s, key=%s", size, bucket, variantKey)
+	}
+
+	return data, true
+}
+
+// acceptsWebP kiểm tra header Accept có nhận image/webp không (bỏ qua q=0)
+func acceptsWebP(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), "image/webp") {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package invitations
+
+import (
+	"api/business/rbac"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/internal/utils"
+	"api/middleware"
+	"api/schema/invitationcol"
+	"api/schema/teamcol"
+	"api/schema/usercol"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CreateRequest struct {
+	Email  string `json:"email" binding:"required"`
+	Role   string `json:"role"`
+	TeamID string `json:"team_id"`
+}
+
+// Create mời một người chưa có tài khoản tham gia với vai trò và team chỉ định.
+// Người có quyền quản lý tất cả team mời mọi vai trò, quản lý chỉ mời nhân viên vào team của mình
+func Create() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][invitations][create]")
+
+	return func(c *gin.Context) {
+		var req CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		res, err := doCreate(c, user, req)
+		if err != nil {
+			logger.Err(err).Str("email", req.Email).Msg("failed to create invitation")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doCreate(c *gin.Context, user *usercol.User, req CreateRequest) (*invitationcol.Invitation, error) {
+	ctx := c.Request.Context()
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !utils.IsEmailValid(email) {
+		return nil, errors.New("INVALID_PARAM: email is invalid")
+	}
+
+	role := usercol.RoleEmployee
+	if req.Role != "" {
+		role = usercol.Role(req.Role)
+		if !rbac.RoleExists(role) {
+			return nil, errors.New("ROLE_NOT_FOUND")
+		}
+	}
+
+	// existing accounts are added directly through add-employee
+	_, err := usercol.FindWithEmail(ctx, email)
+	if err == nil {
+		return nil, errors.New("ACCOUNT_EXIST")
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	invitation := &invitationcol.Invitation{
+		Email:     email,
+		Role:      role,
+		TeamID:    req.TeamID,
+		InvitedBy: user.GetIDString(),
+		Status:    invitationcol.StatusPending,
+	}
+
+	if err = resolveTarget(c, user, invitation); err != nil {
+		return nil, err
+	}
+
+	// a new invite replaces any pending one for the same email
+	if err = invitationcol.RevokePendingByEmail(ctx, email); err != nil {
+		return nil, err
+	}
+
+	if _, err = invitationcol.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	token, err := issueToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = invitationcol.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	if err = send(ctx, invitation, token, user); err != nil {
+		// the invitee never got the link, do not leave a pending invite behind
+		invitation.Status = invitationcol.StatusRevoked
+		invitation.RevokedAt = timer.Now()
+		if _, revokeErr := invitationcol.Update(ctx, invitation); revokeErr != nil {
+			return nil, revokeErr
+		}
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// resolveTarget kiểm tra quyền mời theo vai trò/team và xác định quản lý trực tiếp
+func resolveTarget(c *gin.Context, user *usercol.User, invitation *invitationcol.Invitation) error {
+	// without team.manage the invite is limited to employees of the inviter's own team
+	if !rbac.Can(user, rbac.TeamManage) {
+		if invitation.Role != usercol.RoleEmployee {
+			return errors.New("PERMISSION_DENIED: managers can only invite employees")
+		}
+
+		if invitation.TeamID != "" {
+			team, err := findTeam(c, invitation.TeamID)
+			if err != nil {
+				return err
+			}
+			if team.ManagerID != user.GetIDString() {
+				return errors.New("PERMISSION_DENIED: you do not manage this team")
+			}
+		}
+
+		invitation.ManagerID = user.GetIDString()
+		return nil
+	}
+
+	if invitation.TeamID == "" {
+		return nil
+	}
+
+	team, err := findTeam(c, invitation.TeamID)
+	if err != nil {
+		return err
+	}
+
+	switch invitation.Role {
+	case usercol.RoleEmployee:
+		if team.ManagerID == "" {
+			return errors.New("INVALID_PARAM: team must have a manager before inviting employees")
+		}
+		invitation.ManagerID = team.ManagerID
+	case usercol.RoleManager:
+		if team.ManagerID != "" {
+			return errors.New("INVALID_PARAM: team already has a manager")
+		}
+	default:
+		return errors.New("INVALID_PARAM: team_id only applies to employee and manager invitations")
+	}
+
+	return nil
+}
+
+func findTeam(c *gin.Context, id string) (*teamcol.Team, error) {
+	team, err := teamcol.FindByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("TEAM_NOT_FOUND")
+		}
+		return nil, err
+	}
+	if team.IsDelete {
+		return nil, errors.New("TEAM_NOT_FOUND")
+	}
+	return team, nil
+}
@@ -0,0 +1,220 @@
+package invitations
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"api/business/rbac"
+	"api/internal/jwt"
+	"api/internal/plog"
+	"api/internal/timer"
+	"api/schema/invitationcol"
+	"api/schema/teamcol"
+	"api/schema/teammembercol"
+	"api/schema/usercol"
+	"api/services/mail"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	InviteLifetime     = 7 * 24 * 60 * 60 // 7 days
+	ResendInterval     = 60               // seconds between two emails of the same invitation
+	TokenIssuer        = "btcland"
+	mailCategoryInvite = "invite"
+)
+
+// issueToken tạo token đã ký cho lời mời và lưu hash, token cũ không còn dùng được
+func issueToken(invitation *invitationcol.Invitation) (string, error) {
+	token, err := jwt.GenerateSessionToken(
+		os.Getenv("KEY_API_KEY"),
+		invitation.GetIDString(),
+		jwt.SessionTypeInvite,
+		TokenIssuer,
+		InviteLifetime,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	now := timer.Now()
+	invitation.TokenHash = hashToken(token)
+	invitation.ExpireAt = now.Add(InviteLifetime * time.Second)
+	invitation.SentAt = now
+	invitation.SendCount++
+
+	return token, nil
+}
+
+// send gửi email lời mời kèm link chứa token (env INVITATION_CLIENT_URL)
+func send(ctx context.Context, invitation *invitationcol.Invitation, token string, inviter *usercol.User) error {
+	link := os.Getenv("INVITATION_CLIENT_URL")
+	if u, err := url.Parse(link); err == nil && link != "" {
+		query := u.Query()
+		query.Set("token", token)
+		u.RawQuery = query.Encode()
+		link = u.String()
+	} else {
+		link = token
+	}
+
+	text := fmt.Sprintf(
+		"%s đã mời bạn tham gia với vai trò %s.\nNhấn vào liên kết sau để tạo tài khoản: %s\nLời mời có hiệu lực trong %d ngày.",
+		inviter.FullName,
+		rbac.RoleText(invitation.Role),
+		link,
+		InviteLifetime/(24*60*60),
+	)
+
+	if err := mail.Send(ctx, invitation.Email, "Lời mời tham gia hệ thống", text, mailCategoryInvite); err != nil {
+		return errors.New("SERVICE_UNAVAILABLE: failed to send invitation email")
+	}
+
+	return nil
+}
+
+// VerifyToken kiểm tra token lời mời và trả về lời mời còn hiệu lực
+func VerifyToken(ctx context.Context, token string) (*invitationcol.Invitation, error) {
+	claims, err := jwt.VerifySessionToken(os.Getenv("KEY_API_KEY"), token)
+	if err != nil || claims.Type != jwt.SessionTypeInvite {
+		return nil, errors.New("INVITATION_INVALID")
+	}
+
+	invitation, err := invitationcol.FindByID(ctx, claims.SessionId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("INVITATION_INVALID")
+		}
+		return nil, err
+	}
+
+	// only the most recently sent token is valid
+	if subtle.ConstantTimeCompare([]byte(invitation.TokenHash), []byte(hashToken(token))) != 1 {
+		return nil, errors.New("INVITATION_INVALID")
+	}
+
+	if !invitation.IsUsable(timer.Now()) {
+		return nil, errors.New("INVITATION_EXPIRED")
+	}
+
+	return invitation, nil
+}
+
+// HasPending kiểm tra email có lời mời đang chờ hay không
+func HasPending(ctx context.Context, email string) (bool, error) {
+	_, err := invitationcol.FindPendingByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Accept áp dụng lời mời đang chờ của email user: gán vai trò và team. Chỉ gọi khi email
+// đã được xác minh (OTP hoặc provider). Không có lời mời thì không làm gì
+func Accept(ctx context.Context, user *usercol.User) error {
+	invitation, err := invitationcol.FindPendingByEmail(ctx, user.Email)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+
+	return AcceptInvitation(ctx, user, invitation)
+}
+
+// AcceptInvitation gán vai trò và team của lời mời cho user rồi mới đánh dấu lời mời đã dùng,
+// lỗi ở bước gán giữ lời mời ở trạng thái chờ để có thể áp dụng lại
+func AcceptInvitation(ctx context.Context, user *usercol.User, invitation *invitationcol.Invitation) error {
+	if invitation.Role != "" && invitation.Role != user.Role {
+		objID, err := primitive.ObjectIDFromHex(user.GetIDString())
+		if err != nil {
+			return err
+		}
+		if _, err = usercol.UpdateByID(ctx, objID, bson.M{"role": invitation.Role}); err != nil {
+			return err
+		}
+		user.Role = invitation.Role
+	}
+
+	if err := assignTeam(ctx, user, invitation); err != nil {
+		return err
+	}
+
+	accepted, err := invitationcol.MarkAccepted(ctx, invitation.GetIDString(), user.GetIDString())
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		// revoked or consumed concurrently after the token was checked
+		return errors.New("INVITATION_INVALID")
+	}
+
+	return nil
+}
+
+// assignTeam đưa user vào team của lời mời: nhân viên vào team của quản lý, quản lý nhận team chưa có quản lý
+func assignTeam(ctx context.Context, user *usercol.User, invitation *invitationcol.Invitation) error {
+	logger := plog.NewBizLogger("[business][invitations][accept]")
+
+	switch invitation.Role {
+	case usercol.RoleEmployee:
+		if invitation.ManagerID == "" {
+			return nil
+		}
+
+		belongs, err := teammembercol.CheckEmployeeBelongsToManager(ctx, invitation.ManagerID, user.GetIDString())
+		if err != nil || belongs {
+			return err
+		}
+
+		_, err = teammembercol.Create(ctx, &teammembercol.TeamMember{
+			ManagerID:  invitation.ManagerID,
+			EmployeeID: user.GetIDString(),
+			JoinedAt:   timer.Now(),
+		})
+		return err
+
+	case usercol.RoleManager:
+		if invitation.TeamID == "" {
+			return nil
+		}
+
+		team, err := teamcol.FindByID(ctx, invitation.TeamID)
+		if err != nil {
+			return err
+		}
+
+		// the team may have been given another manager since the invite was sent
+		if team.ManagerID != "" && team.ManagerID != user.GetIDString() {
+			logger.Warn().Str("team_id", invitation.TeamID).Msg("team already has a manager, skip assignment")
+			return nil
+		}
+
+		return teamcol.UpdateManagerID(ctx, invitation.TeamID, user.GetIDString())
+	}
+
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// canManage kiểm tra user có quyền thao tác lời mời: quyền team.manage với mọi lời mời, ngược lại chỉ lời mời của mình
+func canManage(user *usercol.User, invitation *invitationcol.Invitation) bool {
+	return rbac.Can(user, rbac.TeamManage) || invitation.InvitedBy == user.GetIDString()
+}
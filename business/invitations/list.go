@@ -0,0 +1,96 @@
+package invitations
+
+import (
+	"api/business/rbac"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/invitationcol"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// List lấy danh sách lời mời: người quản lý tất cả team xem tất cả, người khác xem lời mời do mình gửi.
+// Lọc theo status (pending, accepted, revoked, expired) và email
+func List() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][invitations][list]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		skip := (page - 1) * limit
+		findOptions := options.Find().
+			SetSkip(int64(skip)).
+			SetLimit(int64(limit)).
+			SetSort(primitive.D{{Key: "created_at", Value: -1}})
+
+		filter := primitive.D{}
+		if !rbac.Can(user, rbac.TeamManage) {
+			filter = bsonutil.BsonAdd(filter, "invited_by", user.GetIDString())
+		}
+		if email := strings.ToLower(strings.TrimSpace(c.Query("email"))); email != "" {
+			filter = bsonutil.BsonAdd(filter, "email", email)
+		}
+
+		now := timer.Now()
+		switch status := c.Query("status"); status {
+		case "":
+		case invitationcol.StatusExpired:
+			filter = bsonutil.BsonAdd(filter, "status", invitationcol.StatusPending)
+			filter = bsonutil.BsonLessThanEqual(filter, "expire_at", now)
+		case invitationcol.StatusPending:
+			filter = bsonutil.BsonAdd(filter, "status", invitationcol.StatusPending)
+			filter = bsonutil.BsonGreaterThan(filter, "expire_at", now)
+		default:
+			filter = bsonutil.BsonAdd(filter, "status", status)
+		}
+
+		invitations, count, err := invitationcol.FindWithFilter(c.Request.Context(), filter, findOptions)
+		if err != nil {
+			logger.Err(err).Msg("failed to list invitations")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list invitations"))
+			c.Abort()
+			return
+		}
+
+		for _, invitation := range invitations {
+			if invitation.Status == invitationcol.StatusPending && !invitation.IsUsable(now) {
+				invitation.Status = invitationcol.StatusExpired
+			}
+		}
+
+		if invitations == nil {
+			invitations = []*invitationcol.Invitation{}
+		}
+
+		responseData := map[string]interface{}{
+			"data":       invitations,
+			"total":      count,
+			"page":       page,
+			"limit":      limit,
+			"total_page": (count + int64(limit) - 1) / int64(limit),
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(responseData))
+	}
+}
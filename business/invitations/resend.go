@@ -0,0 +1,89 @@
+package invitations
+
+import (
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/invitationcol"
+	"api/schema/usercol"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Resend gửi lại email lời mời với token mới và gia hạn hiệu lực, token cũ bị vô hiệu
+func Resend() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][invitations][resend]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		res, err := doResend(c, user, c.Param("id"))
+		if err != nil {
+			logger.Err(err).Str("invitation_id", c.Param("id")).Msg("failed to resend invitation")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doResend(c *gin.Context, user *usercol.User, id string) (*invitationcol.Invitation, error) {
+	ctx := c.Request.Context()
+
+	invitation, err := findManageable(c, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// expired invitations can be resent, accepted or revoked ones cannot
+	if invitation.Status != invitationcol.StatusPending {
+		return nil, errors.New("INVITATION_NOT_PENDING")
+	}
+
+	if timer.Now().Sub(invitation.SentAt) < ResendInterval*time.Second {
+		return nil, errors.New("INVITATION_RESEND_TOO_SOON")
+	}
+
+	token, err := issueToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = invitationcol.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	if err = send(ctx, invitation, token, user); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// findManageable tìm lời mời mà user được phép thao tác
+func findManageable(c *gin.Context, user *usercol.User, id string) (*invitationcol.Invitation, error) {
+	invitation, err := invitationcol.FindByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("INVITATION_NOT_FOUND")
+		}
+		return nil, err
+	}
+
+	if !canManage(user, invitation) {
+		return nil, errors.New("INVITATION_NOT_FOUND")
+	}
+
+	return invitation, nil
+}
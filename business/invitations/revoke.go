@@ -0,0 +1,47 @@
+package invitations
+
+import (
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/invitationcol"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Revoke thu hồi lời mời đang chờ, token đã gửi không còn dùng được
+func Revoke() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][invitations][revoke]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		invitation, err := findManageable(c, user, c.Param("id"))
+		if err == nil && invitation.Status != invitationcol.StatusPending {
+			err = errors.New("INVITATION_NOT_PENDING")
+		}
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		invitation.Status = invitationcol.StatusRevoked
+		invitation.RevokedAt = timer.Now()
+		if _, err = invitationcol.Update(c.Request.Context(), invitation); err != nil {
+			logger.Err(err).Msg("failed to revoke invitation")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to revoke invitation"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(invitation))
+	}
+}
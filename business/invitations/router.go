@@ -0,0 +1,20 @@
+package invitations
+
+import (
+	"api/business/rbac"
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Tất cả routes đều yêu cầu authentication
+	r.Use(middleware.AuthMiddleware())
+	r.Use(middleware.Require(rbac.InvitationCreate))
+
+	// Routes cho Lãnh đạo và Quản lý - mời người chưa có tài khoản
+	r.GET("", List())              // GET /invitations - Danh sách lời mời
+	r.POST("", Create())           // POST /invitations - Tạo và gửi lời mời
+	r.POST(":id/resend", Resend()) // POST /invitations/:id/resend - Gửi lại lời mời
+	r.DELETE(":id", Revoke())      // DELETE /invitations/:id - Thu hồi lời mời
+}
@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"net/http"
+	"strconv"
+
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/notificationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// List lấy thông báo của user hiện tại, mới nhất trước. unread=true chỉ lấy thông báo chưa đọc
+func List() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][notifications][list]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		skip := (page - 1) * limit
+		findOptions := options.Find().
+			SetSkip(int64(skip)).
+			SetLimit(int64(limit)).
+			SetSort(primitive.D{{Key: "created_at", Value: -1}})
+
+		filter := bsonutil.BsonAdd(nil, "user_id", user.GetIDString())
+		if c.Query("unread") == "true" {
+			filter = bsonutil.BsonAdd(filter, "read_at", primitive.M{"$exists": false})
+		}
+
+		notifications, count, err := notificationcol.FindWithFilter(c.Request.Context(), filter, findOptions)
+		if err != nil {
+			logger.Err(err).Msg("failed to list notifications")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list notifications"))
+			c.Abort()
+			return
+		}
+
+		if notifications == nil {
+			notifications = []*notificationcol.Notification{}
+		}
+
+		unread, err := notificationcol.CountUnread(c.Request.Context(), user.GetIDString())
+		if err != nil {
+			logger.Err(err).Msg("failed to count unread notifications")
+		}
+
+		responseData := map[string]interface{}{
+			"data":       notifications,
+			"total":      count,
+			"unread":     unread,
+			"page":       page,
+			"limit":      limit,
+			"total_page": (count + int64(limit) - 1) / int64(limit),
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(responseData))
+	}
+}
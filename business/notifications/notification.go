@@ -0,0 +1,46 @@
+package notifications
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"api/internal/plog"
+	"api/schema/notificationcol"
+	"api/schema/usercol"
+	"api/services/mail"
+)
+
+const mailCategoryNotification = "notification"
+
+// Notify lưu thông báo trong ứng dụng cho từng người nhận, gửi kèm email khi bật NOTIFICATION_EMAIL.
+// Email được gửi nền, lỗi gửi email chỉ ghi log
+func Notify(ctx context.Context, list []*notificationcol.Notification) error {
+	if err := notificationcol.CreateMany(ctx, list); err != nil {
+		return err
+	}
+
+	if emailEnabled() && len(list) > 0 {
+		go sendEmails(list)
+	}
+	return nil
+}
+
+func emailEnabled() bool {
+	return mail.MailSender != nil && strings.EqualFold(os.Getenv("NOTIFICATION_EMAIL"), "true")
+}
+
+func sendEmails(list []*notificationcol.Notification) {
+	logger := plog.NewBizLogger("[business][notifications][email]")
+	ctx := context.Background()
+	for _, n := range list {
+		user, err := usercol.FindWithUserID(ctx, n.UserID)
+		if err != nil || user.IsDelete || user.Email == "" {
+			continue
+		}
+
+		if err := mail.Send(ctx, user.Email, n.Title, n.Body, mailCategoryNotification); err != nil {
+			logger.Err(err).Str("user_id", n.UserID).Msg("failed to send notification email")
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/notificationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReadRequest struct {
+	IDs []string `json:"ids"` // Bỏ trống để đánh dấu tất cả
+}
+
+// Read đánh dấu đã đọc các thông báo của user hiện tại
+func Read() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][notifications][read]")
+
+	return func(c *gin.Context) {
+		var req ReadRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		updated, err := notificationcol.MarkRead(c.Request.Context(), user.GetIDString(), req.IDs)
+		if err != nil {
+			logger.Err(err).Msg("failed to mark notifications as read")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to mark notifications as read"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(map[string]int64{
+			"updated": updated,
+		}))
+	}
+}
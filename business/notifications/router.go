@@ -0,0 +1,15 @@
+package notifications
+
+import (
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Tất cả routes đều yêu cầu authentication
+	r.Use(middleware.AuthMiddleware())
+
+	r.GET("", List())      // GET /notifications - Thông báo của tôi
+	r.POST("read", Read()) // POST /notifications/read - Đánh dấu đã đọc
+}
@@ -5,6 +5,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/usercol"
 
 	"github.com/gin-gonic/gin"
@@ -14,20 +15,8 @@ func Get() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][profile][get]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
@@ -51,6 +40,7 @@ func Get() gin.HandlerFunc {
 			"role":            currentUser.Role,
 			"is_verify_phone": currentUser.IsVerifyPhone,
 			"is_verify_email": currentUser.IsVerifyEmail,
+			"is_mfa_enabled":  currentUser.IsMFAEnabled,
 			"created_at":      currentUser.CreatedAt,
 			"updated_at":      currentUser.UpdatedAt,
 		}
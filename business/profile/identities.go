@@ -0,0 +1,96 @@
+package profile
+
+import (
+	"api/business/auth/oauth"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/usercol"
+	"api/services/oauth2"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListIdentities lấy danh sách tài khoản OAuth/OIDC đã liên kết của user hiện tại
+func ListIdentities() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		identities := user.Identities
+		if identities == nil {
+			identities = []usercol.Identity{}
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(identities))
+	}
+}
+
+// LinkIdentity tạo URL đăng nhập provider để liên kết vào user hiện tại.
+// Callback chỉ liên kết khi phiên đăng nhập tạo yêu cầu vẫn còn hiệu lực
+func LinkIdentity() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][profile][link_identity]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		oauthURL, err := oauth.BeginAuth(c.Request.Context(), c.Param("provider"), c.Query("redirect_uri"), &oauth.State{
+			LinkUserId:    user.GetIDString(),
+			LinkSessionId: c.GetString("session_id"),
+		})
+		if err != nil {
+			logger.Err(err).Str("provider", c.Param("provider")).Msg("failed to start identity linking")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(map[string]string{
+			"oauth_url": oauthURL,
+		}))
+	}
+}
+
+// UnlinkIdentity huỷ liên kết một tài khoản OAuth/OIDC, không cho gỡ phương thức đăng nhập cuối cùng
+func UnlinkIdentity() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][profile][unlink_identity]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		provider := oauth2.Provider(c.Param("provider"))
+		subject := c.Param("subject")
+
+		if !user.HasIdentity(provider, subject) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse("Identity not found"))
+			c.Abort()
+			return
+		}
+
+		if !user.IsSetPassword && len(user.Identities) <= 1 {
+			code := response.ErrorResponse("LAST_LOGIN_METHOD: set a password or link another account first")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if _, err := usercol.RemoveIdentity(c.Request.Context(), user.GetIDString(), provider, subject); err != nil {
+			logger.Err(err).Msg("failed to unlink identity")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to unlink identity"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
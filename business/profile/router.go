@@ -14,4 +14,15 @@ func Router(r *gin.RouterGroup) {
 	r.GET("", Get())                 // GET /profile - Lấy thông tin profile
 	r.PUT("", Update())              // PUT /profile - Cập nhật thông tin profile
 	r.POST("avatar", UpdateAvatar()) // POST /profile/avatar - Cập nhật avatar
+
+	// Routes quản lý phiên đăng nhập và thiết bị
+	r.GET("sessions", ListSessions())           // GET /profile/sessions - Danh sách phiên đăng nhập
+	r.DELETE("sessions", RevokeOtherSessions()) // DELETE /profile/sessions - Đăng xuất mọi phiên khác
+	r.DELETE("sessions/:id", RevokeSession())   // DELETE /profile/sessions/:id - Đăng xuất một phiên
+	r.GET("devices", ListDevices())             // GET /profile/devices - Danh sách thiết bị
+
+	// Routes liên kết tài khoản OAuth/OIDC
+	r.GET("identities", ListIdentities())                       // GET /profile/identities - Danh sách tài khoản đã liên kết
+	r.POST("identities/:provider", LinkIdentity())              // POST /profile/identities/:provider - URL liên kết provider
+	r.DELETE("identities/:provider/:subject", UnlinkIdentity()) // DELETE /profile/identities/:provider/:subject - Huỷ liên kết
 }
@@ -0,0 +1,200 @@
+package profile
+
+import (
+	"api/business/auth/session"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/userdevicecol"
+	"api/schema/usersessioncol"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionResponse struct {
+	ID          string    `json:"id"`
+	IP          string    `json:"ip"`
+	DeviceID    string    `json:"device_id"`
+	DeviceName  string    `json:"device_name"`
+	BrowserName string    `json:"browser_name"`
+	Platform    string    `json:"platform"`
+	CreatedAt   time.Time `json:"created_at"`
+	ActiveAt    time.Time `json:"active_at"`
+	IsCurrent   bool      `json:"is_current"`
+}
+
+type DeviceResponse struct {
+	ID          string    `json:"id"`
+	IP          string    `json:"ip"`
+	DeviceID    string    `json:"device_id"`
+	DeviceName  string    `json:"device_name"`
+	Platform    string    `json:"platform"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	ActiveAt    time.Time `json:"active_at,omitempty"`
+	IsCurrent   bool      `json:"is_current"`
+}
+
+// ListSessions lấy danh sách phiên đăng nhập đang hoạt động của user hiện tại
+func ListSessions() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][profile][list_sessions]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		sessions, err := usersessioncol.FindActiveByUser(c.Request.Context(), user.GetIDString())
+		if err != nil {
+			logger.Err(err).Msg("failed to list sessions")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list sessions"))
+			c.Abort()
+			return
+		}
+
+		currentSessionId := c.GetString("session_id")
+		result := make([]SessionResponse, 0, len(sessions))
+		for _, s := range sessions {
+			result = append(result, SessionResponse{
+				ID:          s.GetIDString(),
+				IP:          s.IP,
+				DeviceID:    s.DeviceID,
+				DeviceName:  s.DeviceName,
+				BrowserName: s.BrowserName,
+				Platform:    s.Platform,
+				CreatedAt:   s.CreatedAt,
+				ActiveAt:    s.ActiveAt,
+				IsCurrent:   s.GetIDString() == currentSessionId,
+			})
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(result))
+	}
+}
+
+// RevokeSession thu hồi một phiên đăng nhập của user hiện tại
+func RevokeSession() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][profile][revoke_session]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		target, err := usersessioncol.FindWithId(c.Request.Context(), c.Param("id"))
+		if err != nil || target.UserId != user.GetIDString() || target.IsDelete {
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				logger.Err(err).Msg("failed to find session")
+			}
+			c.JSON(http.StatusNotFound, response.ErrorResponse("Session not found"))
+			c.Abort()
+			return
+		}
+
+		err = session.Revoke(c.Request.Context(), target.GetIDString(), session.RevokeReasonUser)
+		if err != nil {
+			logger.Err(err).Msg("failed to revoke session")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to revoke session"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
+
+// RevokeOtherSessions thu hồi mọi phiên đăng nhập khác ngoài phiên hiện tại
+func RevokeOtherSessions() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][profile][revoke_other_sessions]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		revoked, err := session.RevokeAllExcept(
+			c.Request.Context(),
+			user.GetIDString(),
+			c.GetString("session_id"),
+			session.RevokeReasonUser,
+		)
+		if err != nil {
+			logger.Err(err).Msg("failed to revoke sessions")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to revoke sessions"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(map[string]interface{}{
+			"revoked": revoked,
+		}))
+	}
+}
+
+// ListDevices lấy danh sách thiết bị đã đăng nhập của user hiện tại
+func ListDevices() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][profile][list_devices]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		devices, err := userdevicecol.FindEnabledByUser(ctx, user.GetIDString())
+		if err != nil {
+			logger.Err(err).Msg("failed to list devices")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list devices"))
+			c.Abort()
+			return
+		}
+
+		sessions, err := usersessioncol.FindActiveByUser(ctx, user.GetIDString())
+		if err != nil {
+			logger.Err(err).Msg("failed to list sessions")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list devices"))
+			c.Abort()
+			return
+		}
+
+		// latest activity and current flag come from the device's live sessions
+		currentSessionId := c.GetString("session_id")
+		activeAt := make(map[string]time.Time)
+		currentDeviceId := ""
+		for _, s := range sessions {
+			if s.DeviceID == "" {
+				continue
+			}
+			if s.ActiveAt.After(activeAt[s.DeviceID]) {
+				activeAt[s.DeviceID] = s.ActiveAt
+			}
+			if s.GetIDString() == currentSessionId {
+				currentDeviceId = s.DeviceID
+			}
+		}
+
+		result := make([]DeviceResponse, 0, len(devices))
+		for _, d := range devices {
+			result = append(result, DeviceResponse{
+				ID:          d.GetIDString(),
+				IP:          d.IP,
+				DeviceID:    d.DeviceID,
+				DeviceName:  d.DeviceName,
+				Platform:    d.Platform,
+				LastLoginAt: d.LastLoginAt,
+				ActiveAt:    activeAt[d.DeviceID],
+				IsCurrent:   currentDeviceId != "" && d.DeviceID == currentDeviceId,
+			})
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(result))
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/usercol"
 
 	"github.com/gin-gonic/gin"
@@ -27,20 +28,8 @@ func Update() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
@@ -8,6 +8,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/usercol"
 	"api/services/minio"
 
@@ -18,20 +19,8 @@ func UpdateAvatar() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][profile][update_avatar]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
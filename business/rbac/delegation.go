@@ -0,0 +1,114 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"api/schema/delegationcol"
+	"api/schema/teamcol"
+	"api/schema/teammembercol"
+	"api/schema/usercol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DelegatorCheck kiểm tra người uỷ quyền có tự thực hiện được hành động hay không
+type DelegatorCheck func(delegator *usercol.User) (bool, error)
+
+// ActingFor tìm người uỷ quyền mà user đang được thay mặt để thao tác trên dữ liệu của ownerId.
+// Chỉ xét uỷ quyền còn hiệu lực hôm nay, đúng team của ownerId, và người uỷ quyền thoả check.
+// Trả về nil nếu không có uỷ quyền phù hợp
+func ActingFor(ctx context.Context, user *usercol.User, ownerId string, check DelegatorCheck) (*usercol.User, error) {
+	if user == nil || ownerId == "" || ownerId == user.GetIDString() {
+		return nil, nil
+	}
+
+	delegations, err := delegationcol.FindActiveForDelegate(ctx, user.GetIDString(), timer.Now().Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	var ownerTeams []string
+	teamsLoaded := false
+
+	for _, d := range delegations {
+		if d.DelegatorID == ownerId {
+			continue
+		}
+
+		if len(d.TeamIDs) > 0 {
+			if !teamsLoaded {
+				if ownerTeams, err = TeamIDsOf(ctx, ownerId); err != nil {
+					return nil, err
+				}
+				teamsLoaded = true
+			}
+			if !coversAnyTeam(d, ownerTeams) {
+				continue
+			}
+		}
+
+		delegator, err := usercol.FindWithUserID(ctx, d.DelegatorID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				continue
+			}
+			return nil, err
+		}
+		if delegator.IsDelete {
+			continue
+		}
+
+		ok, err := check(delegator)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return delegator, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// TeamsOf trả về các team của user: team do user quản lý và team của quản lý trực tiếp
+func TeamsOf(ctx context.Context, userId string) ([]*teamcol.Team, error) {
+	managerIds := []string{userId}
+
+	member, err := teammembercol.FindByEmployeeID(ctx, userId)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if member != nil && member.ManagerID != "" {
+		managerIds = append(managerIds, member.ManagerID)
+	}
+
+	filter := bsonutil.BsonIn(nil, "manager_id", managerIds)
+	teams, _, err := teamcol.FindWithFilter(ctx, filter, nil)
+	return teams, err
+}
+
+// TeamIDsOf trả về id các team của user, xem TeamsOf
+func TeamIDsOf(ctx context.Context, userId string) ([]string, error) {
+	teams, err := TeamsOf(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(teams))
+	for _, t := range teams {
+		ids = append(ids, t.GetIDString())
+	}
+	return ids, nil
+}
+
+func coversAnyTeam(d *delegationcol.Delegation, teamIds []string) bool {
+	for _, id := range teamIds {
+		if d.CoversTeam(id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package rbac
+
+import (
+	"api/schema/usercol"
+)
+
+// Permission là một hành động được phân quyền, đặt tên theo dạng <tài nguyên>.<hành động>
+type Permission string
+
+const (
+	// Đơn xác nhận công việc
+	WorkConfirmationCreate       Permission = "work_confirmation.create"        // Tạo đơn của chính mình
+	WorkConfirmationViewTeam     Permission = "work_confirmation.view_team"     // Xem đơn của nhân viên trong team
+	WorkConfirmationViewAll      Permission = "work_confirmation.view_all"      // Xem tất cả đơn
+	WorkConfirmationApprove      Permission = "work_confirmation.approve"       // Duyệt/từ chối bước quản lý (nhân viên trong team)
+	WorkConfirmationApproveFinal Permission = "work_confirmation.approve_final" // Duyệt/từ chối bước lãnh đạo
+	WorkConfirmationCancel       Permission = "work_confirmation.cancel"        // Huỷ đơn đã duyệt
+	WorkConfirmationOverlaps     Permission = "work_confirmation.overlaps"      // Xem báo cáo đơn trùng giờ toàn tổ chức
+
+	// Báo cáo, thống kê
+	ReportDownload Permission = "report.download" // Tải file Excel đơn xác nhận
+	DashboardView  Permission = "dashboard.view"  // Xem dashboard thống kê
+
+	// Team và nhân sự
+	TeamManage       Permission = "team.manage"       // Quản lý tất cả team/phòng ban
+	TeamManageOwn    Permission = "team.manage_own"   // Quản lý nhân viên trong team của mình
+	UserView         Permission = "user.view"         // Xem danh sách tất cả user
+	UserAssignRole   Permission = "user.assign_role"  // Đổi vai trò user
+	InvitationCreate Permission = "invitation.create" // Mời người chưa có tài khoản
+	RoleManage       Permission = "role.manage"       // Tạo/sửa/xoá vai trò tuỳ chỉnh
+	WorkflowManage   Permission = "workflow.manage"   // Cấu hình quy trình duyệt đơn
+	WorkTimeManage   Permission = "work_time.manage"  // Cấu hình giờ làm việc và lịch nghỉ lễ
+	WorkSiteManage   Permission = "work_site.manage"  // Quản lý địa điểm làm việc
+)
+
+// allPermissions là danh sách quyền hợp lệ để gán cho vai trò
+var allPermissions = []Permission{
+	WorkConfirmationCreate,
+	WorkConfirmationViewTeam,
+	WorkConfirmationViewAll,
+	WorkConfirmationApprove,
+	WorkConfirmationApproveFinal,
+	WorkConfirmationCancel,
+	WorkConfirmationOverlaps,
+	ReportDownload,
+	DashboardView,
+	TeamManage,
+	TeamManageOwn,
+	UserView,
+	UserAssignRole,
+	InvitationCreate,
+	RoleManage,
+	WorkflowManage,
+	WorkTimeManage,
+	WorkSiteManage,
+}
+
+// SystemRole là vai trò có sẵn, được seed vào collection role khi khởi động
+type SystemRole struct {
+	Name        usercol.Role
+	TextVi      string
+	TextEn      string
+	Permissions []Permission
+}
+
+// systemRoles giữ quyền của bốn vai trò gốc, code là nguồn sự thật cho các vai trò này
+var systemRoles = []SystemRole{
+	{
+		Name:   usercol.RoleEmployee,
+		TextVi: "Nhân viên",
+		TextEn: "Employee",
+		Permissions: []Permission{
+			WorkConfirmationCreate,
+		},
+	},
+	{
+		Name:   usercol.RoleManager,
+		TextVi: "Quản lý",
+		TextEn: "Manager",
+		Permissions: []Permission{
+			WorkConfirmationCreate,
+			WorkConfirmationViewTeam,
+			WorkConfirmationApprove,
+			TeamManageOwn,
+			InvitationCreate,
+		},
+	},
+	{
+		Name:   usercol.RoleLeader,
+		TextVi: "Lãnh đạo",
+		TextEn: "Leader",
+		Permissions: []Permission{
+			WorkConfirmationCreate,
+			WorkConfirmationViewAll,
+			WorkConfirmationApproveFinal,
+			WorkConfirmationCancel,
+			WorkConfirmationOverlaps,
+			ReportDownload,
+			DashboardView,
+			TeamManage,
+			UserView,
+			UserAssignRole,
+			InvitationCreate,
+			RoleManage,
+			WorkflowManage,
+			WorkTimeManage,
+			WorkSiteManage,
+		},
+	},
+	{
+		Name:   usercol.RoleAssistantDirector,
+		TextVi: "Trợ lý giám đốc",
+		TextEn: "Assistant director",
+		Permissions: []Permission{
+			WorkConfirmationCreate,
+			WorkConfirmationViewAll,
+			ReportDownload,
+			DashboardView,
+		},
+	},
+}
+
+// AllPermissions trả về danh sách quyền có thể gán cho vai trò
+func AllPermissions() []Permission {
+	return append([]Permission(nil), allPermissions...)
+}
+
+// IsKnownPermission kiểm tra tên quyền có hợp lệ không
+func IsKnownPermission(permission Permission) bool {
+	for _, p := range allPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSystemRole kiểm tra vai trò có phải vai trò hệ thống không
+func IsSystemRole(role usercol.Role) bool {
+	for _, r := range systemRoles {
+		if r.Name == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions trả về danh sách quyền của vai trò, user chưa có vai trò được xem là nhân viên
+func Permissions(role usercol.Role) []Permission {
+	if role == "" {
+		role = usercol.RoleEmployee
+	}
+
+	if r := lookup(role); r != nil {
+		return r.permissions
+	}
+	return nil
+}
+
+// Can kiểm tra user có quyền hay không
+func Can(user *usercol.User, permission Permission) bool {
+	if user == nil {
+		return false
+	}
+	for _, p := range Permissions(user.Role) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAny kiểm tra user có ít nhất một trong các quyền
+func CanAny(user *usercol.User, permissions ...Permission) bool {
+	for _, p := range permissions {
+		if Can(user, p) {
+			return true
+		}
+	}
+	return false
+}
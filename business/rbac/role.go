@@ -0,0 +1,147 @@
+package rbac
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api/internal/plog"
+	"api/schema/rolecol"
+	"api/schema/usercol"
+)
+
+type cachedRole struct {
+	textVi      string
+	textEn      string
+	permissions []Permission
+}
+
+var (
+	rolesMu sync.RWMutex
+	roles   = defaultRoles()
+)
+
+// defaultRoles dựng cache từ vai trò hệ thống, dùng trước khi đọc được collection role
+func defaultRoles() map[usercol.Role]*cachedRole {
+	result := make(map[usercol.Role]*cachedRole, len(systemRoles))
+	for _, r := range systemRoles {
+		result[r.Name] = &cachedRole{
+			textVi:      r.TextVi,
+			textEn:      r.TextEn,
+			permissions: r.Permissions,
+		}
+	}
+	return result
+}
+
+// SeedSystemRoles tạo (hoặc đồng bộ quyền) bốn vai trò hệ thống trong collection role
+func SeedSystemRoles(ctx context.Context) error {
+	for _, r := range systemRoles {
+		permissions := make([]string, 0, len(r.Permissions))
+		for _, p := range r.Permissions {
+			permissions = append(permissions, string(p))
+		}
+
+		err := rolecol.UpsertSystem(ctx, &rolecol.Role{
+			Name:        r.Name.String(),
+			TextVi:      r.TextVi,
+			TextEn:      r.TextEn,
+			Permissions: permissions,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load đọc lại toàn bộ vai trò từ DB vào cache. Quyền của vai trò hệ thống luôn lấy theo code
+func Load(ctx context.Context) error {
+	stored, err := rolecol.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := defaultRoles()
+	for _, r := range stored {
+		name := usercol.Role(r.Name)
+		if existing, ok := result[name]; ok {
+			existing.textVi = r.TextVi
+			existing.textEn = r.TextEn
+			continue
+		}
+
+		permissions := make([]Permission, 0, len(r.Permissions))
+		for _, p := range r.Permissions {
+			if IsKnownPermission(Permission(p)) {
+				permissions = append(permissions, Permission(p))
+			}
+		}
+
+		result[name] = &cachedRole{
+			textVi:      r.TextVi,
+			textEn:      r.TextEn,
+			permissions: permissions,
+		}
+	}
+
+	rolesMu.Lock()
+	roles = result
+	rolesMu.Unlock()
+
+	return nil
+}
+
+// StartRefresher định kỳ đọc lại vai trò để các instance khác nhận thay đổi
+func StartRefresher(interval time.Duration) {
+	logger := plog.NewBizLogger("[business][rbac][refresher]")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := Load(ctx); err != nil {
+				logger.Err(err).Msg("failed to reload roles")
+			}
+			cancel()
+		}
+	}()
+}
+
+// RoleExists kiểm tra vai trò có tồn tại (hệ thống hoặc tuỳ chỉnh)
+func RoleExists(role usercol.Role) bool {
+	return lookup(role) != nil
+}
+
+// RoleText trả về tên hiển thị tiếng Việt của vai trò
+func RoleText(role usercol.Role) string {
+	if r := lookup(role); r != nil {
+		return r.textVi
+	}
+	return role.Text()
+}
+
+// RolesWith trả về các vai trò (hệ thống và tuỳ chỉnh) có quyền permission
+func RolesWith(permission Permission) []usercol.Role {
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+
+	result := make([]usercol.Role, 0)
+	for name, r := range roles {
+		for _, p := range r.permissions {
+			if p == permission {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func lookup(role usercol.Role) *cachedRole {
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+	return roles[role]
+}
@@ -0,0 +1,59 @@
+package rbac
+
+import (
+	"context"
+
+	"api/schema/teammembercol"
+	"api/schema/usercol"
+)
+
+// IsManagerOf kiểm tra managerId có phải quản lý trực tiếp của userId không
+func IsManagerOf(ctx context.Context, managerId, userId string) (bool, error) {
+	if managerId == "" || userId == "" {
+		return false, nil
+	}
+	return teammembercol.CheckEmployeeBelongsToManager(ctx, managerId, userId)
+}
+
+// CanViewWorkOf kiểm tra user có được xem dữ liệu công việc của ownerId không:
+// của chính mình, có quyền xem tất cả, có quyền xem team và là quản lý của ownerId,
+// hoặc đang được uỷ quyền bởi người xem được
+func CanViewWorkOf(ctx context.Context, user *usercol.User, ownerId string) (bool, error) {
+	allowed, err := canViewWorkDirectly(ctx, user, ownerId)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	delegator, err := ActingFor(ctx, user, ownerId, func(delegator *usercol.User) (bool, error) {
+		return canViewWorkDirectly(ctx, delegator, ownerId)
+	})
+	if err != nil {
+		return false, err
+	}
+	return delegator != nil, nil
+}
+
+func canViewWorkDirectly(ctx context.Context, user *usercol.User, ownerId string) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+	if ownerId == user.GetIDString() || Can(user, WorkConfirmationViewAll) {
+		return true, nil
+	}
+	if !Can(user, WorkConfirmationViewTeam) {
+		return false, nil
+	}
+	return IsManagerOf(ctx, user.GetIDString(), ownerId)
+}
+
+// CanApproveAsManager kiểm tra user có được duyệt bước quản lý cho đơn của creatorId không.
+// Đơn của nhân viên chỉ do quản lý trực tiếp duyệt
+func CanApproveAsManager(ctx context.Context, user *usercol.User, creatorId string, creatorRole usercol.Role) (bool, error) {
+	if !Can(user, WorkConfirmationApprove) {
+		return false, nil
+	}
+	if creatorRole != usercol.RoleEmployee {
+		return true, nil
+	}
+	return IsManagerOf(ctx, user.GetIDString(), creatorId)
+}
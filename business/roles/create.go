@@ -0,0 +1,88 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/rolecol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CreateRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	TextVi      string   `json:"text_vi" binding:"required"`
+	TextEn      string   `json:"text_en"`
+	Permissions []string `json:"permissions"`
+}
+
+// Create tạo vai trò tuỳ chỉnh với danh sách quyền
+func Create() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][roles][create]")
+
+	return func(c *gin.Context) {
+		var req CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		role, err := doCreate(c, req)
+		if err != nil {
+			logger.Err(err).Str("name", req.Name).Msg("failed to create role")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		reload(c.Request.Context(), logger)
+
+		c.JSON(http.StatusOK, response.SuccessResponse(role))
+	}
+}
+
+func doCreate(c *gin.Context, req CreateRequest) (*rolecol.Role, error) {
+	ctx := c.Request.Context()
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !namePattern.MatchString(name) {
+		return nil, errors.New("INVALID_PARAM: name must be 2-32 lowercase letters, digits or underscores")
+	}
+
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = rolecol.FindByName(ctx, name)
+	if err == nil {
+		return nil, errors.New("ROLE_EXIST")
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	role := &rolecol.Role{
+		Name:        name,
+		TextVi:      strings.TrimSpace(req.TextVi),
+		TextEn:      strings.TrimSpace(req.TextEn),
+		Permissions: permissions,
+	}
+
+	if _, err = rolecol.Create(ctx, role); err != nil {
+		// created concurrently with the same name, rejected by the unique index
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("ROLE_EXIST")
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
@@ -0,0 +1,76 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/rolecol"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete xoá vai trò tuỳ chỉnh. Không xoá được vai trò hệ thống hoặc vai trò còn user đang dùng
+func Delete() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][roles][delete]")
+
+	return func(c *gin.Context) {
+		err := doDelete(c)
+		if err != nil {
+			logger.Err(err).Str("id", c.Param("id")).Msg("failed to delete role")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		reload(c.Request.Context(), logger)
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
+
+func doDelete(c *gin.Context) error {
+	ctx := c.Request.Context()
+
+	role, err := findRole(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	if role.IsSystem {
+		return errors.New("SYSTEM_ROLE_READONLY: system roles cannot be deleted")
+	}
+
+	count, err := usercol.CountByRole(ctx, usercol.Role(role.Name))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("ROLE_IN_USE: reassign users before deleting this role")
+	}
+
+	deleted, err := rolecol.SoftDelete(ctx, role.GetIDString())
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("ROLE_NOT_FOUND")
+	}
+
+	// a user may have been given this role between the count and the delete
+	count, err = usercol.CountByRole(ctx, usercol.Role(role.Name))
+	if err != nil || count > 0 {
+		if restoreErr := rolecol.Restore(ctx, role.GetIDString()); restoreErr != nil {
+			return restoreErr
+		}
+		if err != nil {
+			return err
+		}
+		return errors.New("ROLE_IN_USE: reassign users before deleting this role")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package roles
+
+import (
+	"net/http"
+
+	"api/business/rbac"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/rolecol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List lấy danh sách vai trò, vai trò hệ thống đứng trước
+func List() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][roles][list]")
+
+	return func(c *gin.Context) {
+		roles, err := rolecol.FindAll(c.Request.Context())
+		if err != nil {
+			logger.Err(err).Msg("failed to list roles")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list roles"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(roles))
+	}
+}
+
+// Permissions lấy danh sách quyền có thể gán cho vai trò
+func Permissions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, response.SuccessResponse(rbac.AllPermissions()))
+	}
+}
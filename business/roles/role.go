@@ -0,0 +1,55 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"api/business/rbac"
+	"api/internal/plog"
+	"api/schema/rolecol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// namePattern giới hạn mã vai trò ở dạng snake_case viết thường, được lưu trực tiếp ở user.role
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+// normalizePermissions kiểm tra và loại bỏ quyền trùng lặp
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]string, 0, len(permissions))
+
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !rbac.IsKnownPermission(rbac.Permission(p)) {
+			return nil, errors.New("INVALID_PARAM: unknown permission " + p)
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func findRole(ctx context.Context, id string) (*rolecol.Role, error) {
+	role, err := rolecol.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("ROLE_NOT_FOUND")
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// reload làm mới cache quyền sau khi vai trò thay đổi, các instance khác nhận qua refresher
+func reload(ctx context.Context, logger plog.Logger) {
+	if err := rbac.Load(ctx); err != nil {
+		logger.Err(err).Msg("failed to reload roles")
+	}
+}
@@ -0,0 +1,22 @@
+package roles
+
+import (
+	"api/business/rbac"
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Tất cả routes đều yêu cầu authentication
+	r.Use(middleware.AuthMiddleware())
+
+	// Xem vai trò - cần khi gán vai trò cho user
+	r.GET("", middleware.Require(rbac.RoleManage, rbac.UserAssignRole), List())                   // GET /roles - Danh sách vai trò
+	r.GET("permissions", middleware.Require(rbac.RoleManage, rbac.UserAssignRole), Permissions()) // GET /roles/permissions - Danh sách quyền
+
+	// Quản lý vai trò tuỳ chỉnh
+	r.POST("", middleware.Require(rbac.RoleManage), Create())      // POST /roles - Tạo vai trò
+	r.PUT(":id", middleware.Require(rbac.RoleManage), Update())    // PUT /roles/:id - Sửa vai trò
+	r.DELETE(":id", middleware.Require(rbac.RoleManage), Delete()) // DELETE /roles/:id - Xoá vai trò
+}
@@ -0,0 +1,87 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/rolecol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateRequest struct {
+	TextVi      *string   `json:"text_vi"`
+	TextEn      *string   `json:"text_en"`
+	Permissions *[]string `json:"permissions"`
+}
+
+// Update sửa tên hiển thị và quyền của vai trò. Vai trò hệ thống chỉ được sửa tên hiển thị
+func Update() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][roles][update]")
+
+	return func(c *gin.Context) {
+		var req UpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		role, err := doUpdate(c, req)
+		if err != nil {
+			logger.Err(err).Str("id", c.Param("id")).Msg("failed to update role")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		reload(c.Request.Context(), logger)
+
+		c.JSON(http.StatusOK, response.SuccessResponse(role))
+	}
+}
+
+func doUpdate(c *gin.Context, req UpdateRequest) (*rolecol.Role, error) {
+	ctx := c.Request.Context()
+
+	role, err := findRole(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TextVi != nil {
+		text := strings.TrimSpace(*req.TextVi)
+		if text == "" {
+			return nil, errors.New("INVALID_PARAM: text_vi must not be empty")
+		}
+		role.TextVi = text
+	}
+
+	if req.TextEn != nil {
+		role.TextEn = strings.TrimSpace(*req.TextEn)
+	}
+
+	if req.Permissions != nil {
+		// system role permissions are defined in code and re-seeded on startup
+		if role.IsSystem {
+			return nil, errors.New("SYSTEM_ROLE_READONLY: permissions of system roles cannot be changed")
+		}
+
+		permissions, err := normalizePermissions(*req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = permissions
+	}
+
+	if _, err = rolecol.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
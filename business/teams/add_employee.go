@@ -6,6 +6,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/teammembercol"
 	"api/schema/usercol"
 
@@ -29,28 +30,8 @@ func AddEmployee() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ manager mới có quyền
-		if user.Role != usercol.RoleManager {
-			code := response.ErrorResponse("Only managers can add employees")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
 			return
 		}
 
@@ -58,7 +39,7 @@ func AddEmployee() gin.HandlerFunc {
 		employee, err := usercol.FindWithEmail(c.Request.Context(), req.Email)
 		if err != nil {
 			if errors.Is(err, mongo.ErrNoDocuments) {
-				code := response.ErrorResponse("User with this email not found")
+				code := response.ErrorResponse("User with this email not found, send an invitation instead")
 				c.JSON(http.StatusNotFound, code)
 				c.Abort()
 				return
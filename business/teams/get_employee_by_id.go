@@ -6,6 +6,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/teammembercol"
 	"api/schema/usercol"
 
@@ -25,28 +26,8 @@ func GetEmployeeByID() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ manager mới có quyền
-		if user.Role != usercol.RoleManager {
-			code := response.ErrorResponse("Only managers can view employee details")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
 			return
 		}
 
@@ -5,6 +5,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/teammembercol"
 	"api/schema/usercol"
 
@@ -15,28 +16,8 @@ func GetMyTeam() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][teams][get_my_team]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra role - chỉ manager mới có quyền
-		if user.Role != usercol.RoleManager {
-			code := response.ErrorResponse("Only managers can view team information")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
 			return
 		}
 
@@ -1,6 +1,7 @@
 package teams
 
 import (
+	"api/business/rbac"
 	"api/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +10,7 @@ import (
 func Router(r *gin.RouterGroup) {
 	// Tất cả routes đều yêu cầu authentication
 	r.Use(middleware.AuthMiddleware())
+	r.Use(middleware.Require(rbac.TeamManageOwn))
 
 	// Routes cho Quản lý (Manager) - quản lý team của mình
 	r.GET("my-team", GetMyTeam())                              // Get my team (employees)
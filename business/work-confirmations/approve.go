@@ -6,8 +6,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
-	"api/schema/teammembercol"
-	"api/schema/usercol"
+	"api/middleware"
 	"api/schema/workconfirmationcol"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +15,7 @@ import (
 
 type ApproveRequest struct {
 	Comment string `json:"comment"`
+	Version *int   `json:"version"` // Version đơn client đã đọc, bỏ trống để không kiểm tra
 }
 
 func Approve() gin.HandlerFunc {
@@ -36,20 +36,8 @@ func Approve() gin.HandlerFunc {
 			req.Comment = ""
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
@@ -69,72 +57,33 @@ func Approve() gin.HandlerFunc {
 			return
 		}
 
-		// Xác định role của user và kiểm tra quyền
-		userID := user.GetIDString()
-		creatorID := workConfirmation.CreatedBy
-
-		// Kiểm tra trạng thái và xác nhận
-		if workConfirmation.Status == workconfirmationcol.StatusPendingManager {
-			// Cần quản lý xác nhận
-			// Kiểm tra user có phải là manager không
-			if user.Role != usercol.RoleManager {
-				code := response.ErrorResponse("Only managers can approve work confirmations at this stage")
-				c.JSON(http.StatusForbidden, code)
-				c.Abort()
-				return
-			}
-
-			// Kiểm tra user có phải là manager của người tạo đơn không
-			// Nếu đơn từ employee, cần kiểm tra team relationship
-			if workConfirmation.CreatorRole == usercol.RoleEmployee {
-				belongs, err := teammembercol.CheckEmployeeBelongsToManager(c.Request.Context(), userID, creatorID)
-				if err != nil {
-					logger.Err(err).Msg("failed to check employee relationship")
-					code := response.ErrorResponse("Failed to verify employee relationship")
-					c.JSON(http.StatusInternalServerError, code)
-					c.Abort()
-					return
-				}
-
-				if !belongs {
-					code := response.ErrorResponse("You can only approve work confirmations from your team members")
-					c.JSON(http.StatusForbidden, code)
-					c.Abort()
-					return
-				}
-			}
-
-			err = workconfirmationcol.ApproveByManager(c.Request.Context(), id, userID, req.Comment)
-			if err != nil {
-				logger.Err(err).Msg("failed to approve by manager")
-				code := response.ErrorResponse("Failed to approve work confirmation")
-				c.JSON(http.StatusInternalServerError, code)
-				c.Abort()
-				return
-			}
-		} else if workConfirmation.Status == workconfirmationcol.StatusPendingLeader {
-			// Cần lãnh đạo xác nhận
-			// Kiểm tra user có phải là leader không
-			if user.Role != usercol.RoleLeader {
-				code := response.ErrorResponse("Only leaders can approve work confirmations at this stage")
-				c.JSON(http.StatusForbidden, code)
-				c.Abort()
-				return
-			}
+		// Client gửi kèm version đã đọc để không ghi đè thay đổi của người khác
+		if err := checkVersion(workConfirmation, req.Version); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
 
-			err = workconfirmationcol.ApproveByLeader(c.Request.Context(), id, userID, req.Comment)
-			if err != nil {
-				logger.Err(err).Msg("failed to approve by leader")
-				code := response.ErrorResponse("Failed to approve work confirmation")
-				c.JSON(http.StatusInternalServerError, code)
-				c.Abort()
-				return
-			}
-		} else {
+		// Duyệt bước hiện tại: đơn phải đang chờ xác nhận và user là người duyệt bước đó
+		_, err = decide(c, user, workConfirmation, true, req.Comment, "")
+		switch {
+		case errors.Is(err, errNotPending):
 			code := response.ErrorResponse("Work confirmation is not in a state that can be approved")
 			c.JSON(http.StatusBadRequest, code)
 			c.Abort()
 			return
+		case errors.Is(err, errNotApprover):
+			code := response.ErrorResponse("You are not an approver for the current stage of this work confirmation")
+			c.JSON(http.StatusForbidden, code)
+			c.Abort()
+			return
+		case err != nil:
+			logger.Err(err).Msg("failed to approve work confirmation")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
 		}
 
 		// Lấy lại đơn đã cập nhật
@@ -0,0 +1,41 @@
+package workconfirmations
+
+import (
+	"context"
+
+	"api/business/rbac"
+	"api/business/workflows"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+)
+
+// resolveApprover kiểm tra user có được duyệt/từ chối đơn ở bước hiện tại không, trực tiếp hoặc qua uỷ quyền.
+// Khi user duyệt thay, trả về id người uỷ quyền
+func resolveApprover(ctx context.Context, user *usercol.User, wc *workconfirmationcol.WorkConfirmation) (bool, string, error) {
+	check := func(approver *usercol.User) (bool, error) {
+		return workflows.CanAct(ctx, approver, wc)
+	}
+
+	allowed, err := check(user)
+	if err != nil || allowed {
+		return allowed, "", err
+	}
+
+	delegator, err := rbac.ActingFor(ctx, user, wc.CreatedBy, check)
+	if err != nil || delegator == nil {
+		return false, "", err
+	}
+	return true, delegator.GetIDString(), nil
+}
+
+// canView kiểm tra quyền xem đơn: đơn của mình, của nhân viên trong team, có quyền xem tất cả,
+// hoặc là người duyệt bước hiện tại (kể cả qua uỷ quyền). Cần gọi workflows.EnsureStages trước
+func canView(ctx context.Context, user *usercol.User, wc *workconfirmationcol.WorkConfirmation) (bool, error) {
+	allowed, err := rbac.CanViewWorkOf(ctx, user, wc.CreatedBy)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	allowed, _, err = resolveApprover(ctx, user, wc)
+	return allowed, err
+}
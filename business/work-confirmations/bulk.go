@@ -0,0 +1,187 @@
+package workconfirmations
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api/business/workflows"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxBulkItems = 100
+
+type BulkApproveRequest struct {
+	BatchID string   `json:"batch_id" binding:"required"` // Mã lô do client tạo, gửi lại đúng mã này khi retry
+	IDs     []string `json:"ids" binding:"required,min=1"`
+	Comment string   `json:"comment"`
+}
+
+type BulkRejectRequest struct {
+	BatchID string   `json:"batch_id" binding:"required"` // Mã lô do client tạo, gửi lại đúng mã này khi retry
+	IDs     []string `json:"ids" binding:"required,min=1"`
+	Reason  string   `json:"reason" binding:"required"`
+}
+
+// BulkResult là kết quả xử lý một đơn trong lô
+type BulkResult struct {
+	ID       string                                     `json:"id"`
+	Success  bool                                       `json:"success"`
+	Status   workconfirmationcol.WorkConfirmationStatus `json:"status,omitempty"`   // Trạng thái đơn sau khi xử lý
+	Replayed bool                                       `json:"replayed,omitempty"` // Đơn đã được xử lý ở lần gửi trước của cùng lô
+	Code     int                                        `json:"code,omitempty"`     // HTTP code tương ứng khi lỗi
+	Error    string                                     `json:"error,omitempty"`
+}
+
+// BulkApprove duyệt bước hiện tại của nhiều đơn, mỗi đơn được kiểm tra như duyệt từng đơn.
+// Cho phép thành công một phần, gửi lại cùng batch_id không duyệt lặp lại
+func BulkApprove() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][bulk_approve]")
+
+	return func(c *gin.Context) {
+		var req BulkApproveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		res, err := doBulk(c, user, req.BatchID, req.IDs, true, req.Comment, logger)
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+// BulkReject từ chối nhiều đơn với cùng lý do, mỗi đơn được kiểm tra như từ chối từng đơn.
+// Cho phép thành công một phần, gửi lại cùng batch_id không từ chối lặp lại
+func BulkReject() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][bulk_reject]")
+
+	return func(c *gin.Context) {
+		var req BulkRejectRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		res, err := doBulk(c, user, req.BatchID, req.IDs, false, req.Reason, logger)
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doBulk(c *gin.Context, user *usercol.User, batchId string, ids []string, approve bool, text string, logger plog.Logger) (map[string]interface{}, error) {
+	// Bỏ trùng, giữ thứ tự gửi lên
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) == 0 {
+		return nil, errors.New("INVALID_PARAM: ids is required")
+	}
+	if !approve && strings.TrimSpace(text) == "" {
+		return nil, errNoReason
+	}
+	if len(unique) > maxBulkItems {
+		return nil, fmt.Errorf("INVALID_PARAM: at most %d ids per request", maxBulkItems)
+	}
+
+	results := make([]BulkResult, 0, len(unique))
+	succeeded := 0
+	for _, id := range unique {
+		result := decideOne(c, user, id, approve, text, batchId, logger)
+		if result.Success {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	return map[string]interface{}{
+		"batch_id":  batchId,
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	}, nil
+}
+
+// decideOne duyệt/từ chối một đơn trong lô và trả về kết quả thay vì lỗi
+func decideOne(c *gin.Context, user *usercol.User, id string, approve bool, text, batchId string, logger plog.Logger) BulkResult {
+	result := BulkResult{ID: id}
+
+	fail := func(err error) BulkResult {
+		result.Code = response.GetCode(err.Error())
+		result.Error = err.Error()
+		return result
+	}
+
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return fail(errors.New("WORK_CONFIRMATION_NOT_FOUND"))
+	}
+
+	workConfirmation, err := workconfirmationcol.FindByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fail(errors.New("WORK_CONFIRMATION_NOT_FOUND"))
+		}
+		logger.Err(err).Str("id", id).Msg("failed to get work confirmation")
+		return fail(errors.New("SERVER_ERROR: failed to get work confirmation"))
+	}
+
+	replayed, err := decide(c, user, workConfirmation, approve, text, batchId)
+	if errors.Is(err, workflows.ErrStateChanged) {
+		// a concurrent retry of the same batch may have processed this item first
+		if latest, findErr := workconfirmationcol.FindByID(c.Request.Context(), id); findErr == nil && decidedInBatch(latest, user.GetIDString(), batchId) {
+			workConfirmation, replayed, err = latest, true, nil
+		}
+	}
+	if err != nil {
+		if !errors.Is(err, errNotPending) && !errors.Is(err, errNotApprover) && !errors.Is(err, errNoReason) && !errors.Is(err, workflows.ErrStateChanged) {
+			logger.Err(err).Str("id", id).Msg("failed to process work confirmation")
+		}
+		return fail(err)
+	}
+
+	result.Success = true
+	result.Status = workConfirmation.Status
+	result.Replayed = replayed
+	return result
+}
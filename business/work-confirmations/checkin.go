@@ -0,0 +1,93 @@
+package workconfirmations
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"api/business/worksites"
+	"api/internal/mongodb/field"
+	"api/internal/plog"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// earthRadiusMeters dùng để đổi mét sang radian cho $centerSphere
+const earthRadiusMeters = 6378100
+
+// parseCheckIn đọc vị trí check-in tuỳ chọn từ form (latitude, longitude, accuracy mét).
+// Trả về nil nếu client không gửi vị trí
+func parseCheckIn(c *gin.Context) (*workconfirmationcol.CheckIn, error) {
+	latText := c.PostForm("latitude")
+	lngText := c.PostForm("longitude")
+	accuracyText := c.PostForm("accuracy")
+
+	if latText == "" && lngText == "" {
+		if accuracyText != "" {
+			return nil, errors.New("latitude and longitude are required when accuracy is sent")
+		}
+		return nil, nil
+	}
+	if latText == "" || lngText == "" {
+		return nil, errors.New("latitude and longitude must be sent together")
+	}
+
+	lat, err := strconv.ParseFloat(latText, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, errors.New("latitude must be a number within [-90, 90]")
+	}
+	lng, err := strconv.ParseFloat(lngText, 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, errors.New("longitude must be a number within [-180, 180]")
+	}
+
+	checkIn := &workconfirmationcol.CheckIn{Location: field.NewPoint(lng, lat)}
+	if accuracyText != "" {
+		if checkIn.AccuracyMeters, err = strconv.ParseFloat(accuracyText, 64); err != nil || checkIn.AccuracyMeters < 0 {
+			return nil, errors.New("accuracy must be a non-negative number of meters")
+		}
+	}
+
+	return checkIn, nil
+}
+
+// tagSite gắn địa điểm chứa vị trí check-in, hoặc đánh dấu ngoài mọi địa điểm.
+// Lỗi tra cứu không chặn việc tạo đơn, site_status để trống
+func tagSite(ctx context.Context, checkIn *workconfirmationcol.CheckIn) {
+	logger := plog.NewBizLogger("[business][work-confirmations][tag-site]")
+
+	site, err := worksites.Match(ctx, checkIn.Location)
+	if err != nil {
+		logger.Err(err).Msg("failed to match work site")
+		return
+	}
+
+	if site == nil {
+		checkIn.SiteStatus = workconfirmationcol.SiteOutside
+		return
+	}
+
+	checkIn.SiteStatus = workconfirmationcol.SiteInside
+	checkIn.SiteID = site.GetIDString()
+	checkIn.SiteName = site.Name
+}
+
+// nearSiteFilter lọc đơn có vị trí check-in trong vùng của địa điểm, within (mét) nới rộng vùng với địa điểm dạng Point
+func nearSiteFilter(ctx context.Context, siteID string, within float64) (primitive.E, error) {
+	site, err := worksites.Find(ctx, siteID)
+	if err != nil {
+		return primitive.E{}, err
+	}
+
+	var geoWithin primitive.M
+	if site.Area.Type == field.Point {
+		radians := (site.RadiusMeters + within) / earthRadiusMeters
+		geoWithin = primitive.M{"$centerSphere": primitive.A{site.Area.Coordinates, radians}}
+	} else {
+		geoWithin = primitive.M{"$geometry": site.Area}
+	}
+
+	return primitive.E{Key: "check_in.location", Value: primitive.M{"$geoWithin": geoWithin}}, nil
+}
@@ -0,0 +1,287 @@
+package workconfirmations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/business/notifications"
+	"api/business/workflows"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/schema/commentcol"
+	"api/schema/notificationcol"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+	"api/services/minio"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultCommentEditWindow = 15 * time.Minute
+	maxCommentLength         = 2000
+)
+
+// CommentView là bình luận kèm tên người bình luận
+type CommentView struct {
+	*commentcol.Comment
+	AuthorName string `json:"author_name"`
+}
+
+// commentEditWindow trả về khoảng thời gian sau khi đăng người bình luận còn được sửa/xoá (env COMMENT_EDIT_WINDOW_MINUTES)
+func commentEditWindow() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("COMMENT_EDIT_WINDOW_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return defaultCommentEditWindow
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// findViewable tìm đơn và kiểm tra user có quyền xem, trả về nil và ghi response lỗi nếu không
+func findViewable(c *gin.Context, user *usercol.User, logger plog.Logger) *workconfirmationcol.WorkConfirmation {
+	workConfirmation, err := workconfirmationcol.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			code := response.ErrorResponse("Work confirmation not found")
+			c.JSON(http.StatusNotFound, code)
+			c.Abort()
+			return nil
+		}
+		logger.Err(err).Msg("failed to get work confirmation")
+		code := response.ErrorResponse("Failed to get work confirmation")
+		c.JSON(http.StatusInternalServerError, code)
+		c.Abort()
+		return nil
+	}
+
+	workflows.EnsureStages(workConfirmation)
+
+	allowed, err := canView(c.Request.Context(), user, workConfirmation)
+	if err != nil {
+		logger.Err(err).Msg("failed to check access")
+		code := response.ErrorResponse("Failed to verify access")
+		c.JSON(http.StatusInternalServerError, code)
+		c.Abort()
+		return nil
+	}
+
+	if !allowed {
+		code := response.ErrorResponse("Access denied")
+		c.JSON(http.StatusForbidden, code)
+		c.Abort()
+		return nil
+	}
+
+	return workConfirmation
+}
+
+// findEditableComment tìm bình luận của đơn do user viết và còn trong thời gian được sửa/xoá
+func findEditableComment(ctx context.Context, user *usercol.User, workConfirmationId, commentId string) (*commentcol.Comment, error) {
+	comment, err := commentcol.FindByID(ctx, commentId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("COMMENT_NOT_FOUND")
+		}
+		return nil, err
+	}
+
+	if comment.WorkConfirmationID != workConfirmationId {
+		return nil, errors.New("COMMENT_NOT_FOUND")
+	}
+
+	if comment.AuthorID != user.GetIDString() {
+		return nil, errors.New("PERMISSION_DENIED: only the author can change this comment")
+	}
+
+	if timer.Now().After(comment.CreatedAt.Add(commentEditWindow())) {
+		return nil, errors.New("COMMENT_EDIT_WINDOW_EXPIRED")
+	}
+
+	return comment, nil
+}
+
+// validateMentions bỏ trùng và kiểm tra người được nhắc tới có quyền xem đơn
+func validateMentions(ctx context.Context, wc *workconfirmationcol.WorkConfirmation, ids []string) ([]string, error) {
+	seen := map[string]bool{}
+	mentions := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		mentioned, err := usercol.FindWithUserID(ctx, id)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, fmt.Errorf("MENTION_NOT_ALLOWED: user %s not found", id)
+			}
+			return nil, err
+		}
+		if mentioned.IsDelete {
+			return nil, fmt.Errorf("MENTION_NOT_ALLOWED: user %s not found", id)
+		}
+
+		allowed, err := canView(ctx, mentioned, wc)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("MENTION_NOT_ALLOWED: %s cannot view this work confirmation", mentioned.FullName)
+		}
+
+		mentions = append(mentions, id)
+	}
+
+	return mentions, nil
+}
+
+// mentionIDs đọc danh sách user_id được nhắc tới từ form, hỗ trợ gửi nhiều field hoặc phân tách bằng dấu phẩy
+func mentionIDs(c *gin.Context) []string {
+	var ids []string
+	for _, value := range c.PostFormArray("mentions") {
+		ids = append(ids, strings.Split(value, ",")...)
+	}
+	return ids
+}
+
+// participants trả về những người tham gia đơn: người tạo, người đã duyệt/từ chối và người đã bình luận
+func participants(ctx context.Context, wc *workconfirmationcol.WorkConfirmation) ([]string, error) {
+	ids := []string{wc.CreatedBy}
+	for _, stage := range wc.Stages {
+		ids = append(ids, stage.ActedBy, stage.OnBehalfOf)
+	}
+
+	comments, _, err := commentcol.FindByWorkConfirmationID(ctx, wc.GetIDString(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		ids = append(ids, comment.AuthorID)
+	}
+
+	return ids, nil
+}
+
+// notifyComment thông báo bình luận mới: người được nhắc tới nhận thông báo nhắc tên,
+// những người tham gia còn lại nhận thông báo bình luận. Người bình luận không nhận thông báo của chính mình
+func notifyComment(ctx context.Context, author *usercol.User, wc *workconfirmationcol.WorkConfirmation, comment *commentcol.Comment, mentions []string, toParticipants bool) error {
+	authorId := author.GetIDString()
+	sent := map[string]bool{authorId: true, "": true}
+	var list []*notificationcol.Notification
+
+	add := func(userId string, notificationType notificationcol.NotificationType, title string) {
+		if sent[userId] {
+			return
+		}
+		sent[userId] = true
+		list = append(list, &notificationcol.Notification{
+			UserID:             userId,
+			Type:               notificationType,
+			Title:              title,
+			Body:               fmt.Sprintf("%s: %s", author.FullName, commentPreview(comment)),
+			ActorID:            authorId,
+			WorkConfirmationID: wc.GetIDString(),
+			CommentID:          comment.GetIDString(),
+		})
+	}
+
+	for _, userId := range mentions {
+		add(userId, notificationcol.TypeMention, fmt.Sprintf("%s đã nhắc tới bạn trong đơn xác nhận công tác ngày %s", author.FullName, wc.Date))
+	}
+
+	if toParticipants {
+		userIds, err := participants(ctx, wc)
+		if err != nil {
+			return err
+		}
+		for _, userId := range userIds {
+			add(userId, notificationcol.TypeComment, fmt.Sprintf("Bình luận mới trong đơn xác nhận công tác ngày %s", wc.Date))
+		}
+	}
+
+	return notifications.Notify(ctx, list)
+}
+
+func commentPreview(comment *commentcol.Comment) string {
+	text := []rune(comment.Text)
+	if len(text) > 200 {
+		return string(text[:200]) + "…"
+	}
+	if len(text) == 0 && len(comment.Attachments) > 0 {
+		return fmt.Sprintf("[%d ảnh]", len(comment.Attachments))
+	}
+	return string(text)
+}
+
+// uploadCommentAttachments upload ảnh đính kèm bình luận lên MinIO, bỏ qua file không phải ảnh
+func uploadCommentAttachments(c *gin.Context, workConfirmationId string, logger plog.Logger) []commentcol.Attachment {
+	attachments := make([]commentcol.Attachment, 0)
+	if c.Request.MultipartForm == nil {
+		return attachments
+	}
+
+	bucket := "images"
+	for _, fileHeader := range c.Request.MultipartForm.File["attachments"] {
+		contentType := fileHeader.Header.Get("Content-Type")
+		if !strings.HasPrefix(contentType, "image/") {
+			logger.Warn().Msgf("invalid file type: %s", contentType)
+			continue
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			logger.Err(err).Msgf("failed to open file: %s", fileHeader.Filename)
+			continue
+		}
+
+		// Tạo object key: work-confirmations/comments/{work_confirmation_id}/{timestamp}-{filename}
+		filename := strings.ReplaceAll(fileHeader.Filename, " ", "_")
+		filename = strings.ReplaceAll(filename, "/", "_")
+		objectKey := fmt.Sprintf("work-confirmations/comments/%s/%d-%s", workConfirmationId, time.Now().Unix(), filename)
+
+		_, err = minio.PutObject(bucket, objectKey, file)
+		file.Close()
+		if err != nil {
+			logger.Err(err).Msgf("failed to upload file to MinIO: %s", filename)
+			continue
+		}
+
+		attachments = append(attachments, commentcol.Attachment{
+			URL:        fmt.Sprintf("/%s/%s", bucket, objectKey),
+			Filename:   filename,
+			UploadedAt: time.Now(),
+		})
+	}
+
+	return attachments
+}
+
+// commentViews gắn tên người bình luận vào danh sách bình luận
+func commentViews(ctx context.Context, comments []*commentcol.Comment) []CommentView {
+	names := map[string]string{}
+	views := make([]CommentView, 0, len(comments))
+
+	for _, comment := range comments {
+		name, ok := names[comment.AuthorID]
+		if !ok {
+			name = "N/A"
+			if u, err := usercol.FindWithUserID(ctx, comment.AuthorID); err == nil {
+				name = u.FullName
+			}
+			names[comment.AuthorID] = name
+		}
+		views = append(views, CommentView{Comment: comment, AuthorName: name})
+	}
+
+	return views
+}
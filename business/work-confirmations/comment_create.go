@@ -0,0 +1,91 @@
+package workconfirmations
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/commentcol"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateComment thêm bình luận vào đơn (multipart: text, attachments, mentions) và thông báo cho người tham gia
+func CreateComment() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][comment_create]")
+
+	return func(c *gin.Context) {
+		// Parse multipart form, giới hạn dung lượng cả request
+		if err := parseUploadForm(c); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workConfirmation := findViewable(c, user, logger)
+		if workConfirmation == nil {
+			return
+		}
+
+		res, err := doCreateComment(c, user, workConfirmation, logger)
+		if err != nil {
+			logger.Err(err).Msg("failed to create comment")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doCreateComment(c *gin.Context, user *usercol.User, wc *workconfirmationcol.WorkConfirmation, logger plog.Logger) (*CommentView, error) {
+	ctx := c.Request.Context()
+
+	text := strings.TrimSpace(c.PostForm("text"))
+	if utf8.RuneCountInString(text) > maxCommentLength {
+		return nil, errors.New("INVALID_PARAM: text is too long")
+	}
+
+	mentions, err := validateMentions(ctx, wc, mentionIDs(c))
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := uploadCommentAttachments(c, wc.GetIDString(), logger)
+	if text == "" && len(attachments) == 0 {
+		return nil, errors.New("INVALID_PARAM: text or attachments is required")
+	}
+
+	comment := &commentcol.Comment{
+		WorkConfirmationID: wc.GetIDString(),
+		AuthorID:           user.GetIDString(),
+		Text:               text,
+		Attachments:        attachments,
+		Mentions:           mentions,
+	}
+
+	if _, err := commentcol.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	// Thông báo lỗi không làm hỏng bình luận đã lưu
+	if err := notifyComment(ctx, user, wc, comment, mentions, true); err != nil {
+		logger.Err(err).Msg("failed to notify comment")
+	}
+
+	return &CommentView{Comment: comment, AuthorName: user.FullName}, nil
+}
@@ -0,0 +1,43 @@
+package workconfirmations
+
+import (
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/commentcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteComment xoá bình luận, chỉ người viết trong thời gian cho phép
+func DeleteComment() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][comment_delete]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workConfirmation := findViewable(c, user, logger)
+		if workConfirmation == nil {
+			return
+		}
+
+		comment, err := findEditableComment(c.Request.Context(), user, workConfirmation.GetIDString(), c.Param("comment_id"))
+		if err == nil {
+			err = commentcol.SoftDelete(c.Request.Context(), comment.GetIDString())
+		}
+		if err != nil {
+			logger.Err(err).Msg("failed to delete comment")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
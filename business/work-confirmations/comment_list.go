@@ -0,0 +1,64 @@
+package workconfirmations
+
+import (
+	"net/http"
+	"strconv"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/commentcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ListComments lấy bình luận của đơn theo thứ tự thời gian, cùng quyền xem như chi tiết đơn
+func ListComments() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][comment_list]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workConfirmation := findViewable(c, user, logger)
+		if workConfirmation == nil {
+			return
+		}
+
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 50
+		}
+
+		skip := (page - 1) * limit
+		findOptions := options.Find().
+			SetSkip(int64(skip)).
+			SetLimit(int64(limit))
+
+		comments, count, err := commentcol.FindByWorkConfirmationID(c.Request.Context(), workConfirmation.GetIDString(), findOptions)
+		if err != nil {
+			logger.Err(err).Msg("failed to list comments")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list comments"))
+			c.Abort()
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"data":       commentViews(c.Request.Context(), comments),
+			"total":      count,
+			"page":       page,
+			"limit":      limit,
+			"total_page": (count + int64(limit) - 1) / int64(limit),
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(responseData))
+	}
+}
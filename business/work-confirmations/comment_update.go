@@ -0,0 +1,106 @@
+package workconfirmations
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/commentcol"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateCommentRequest struct {
+	Text     string   `json:"text"`
+	Mentions []string `json:"mentions"`
+}
+
+// UpdateComment sửa nội dung bình luận, chỉ người viết trong thời gian cho phép.
+// Chỉ những người mới được nhắc tới nhận thông báo
+func UpdateComment() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][comment_update]")
+
+	return func(c *gin.Context) {
+		var req UpdateCommentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workConfirmation := findViewable(c, user, logger)
+		if workConfirmation == nil {
+			return
+		}
+
+		res, err := doUpdateComment(c, user, workConfirmation, req, logger)
+		if err != nil {
+			logger.Err(err).Msg("failed to update comment")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doUpdateComment(c *gin.Context, user *usercol.User, wc *workconfirmationcol.WorkConfirmation, req UpdateCommentRequest, logger plog.Logger) (*CommentView, error) {
+	ctx := c.Request.Context()
+
+	comment, err := findEditableComment(ctx, user, wc.GetIDString(), c.Param("comment_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if utf8.RuneCountInString(text) > maxCommentLength {
+		return nil, errors.New("INVALID_PARAM: text is too long")
+	}
+	if text == "" && len(comment.Attachments) == 0 {
+		return nil, errors.New("INVALID_PARAM: text is required")
+	}
+
+	mentions, err := validateMentions(ctx, wc, req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := map[string]bool{}
+	for _, id := range comment.Mentions {
+		previous[id] = true
+	}
+	var added []string
+	for _, id := range mentions {
+		if !previous[id] {
+			added = append(added, id)
+		}
+	}
+
+	comment.Text = text
+	comment.Mentions = mentions
+	comment.EditedAt = timer.Now()
+	if _, err := commentcol.Update(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	if err := notifyComment(ctx, user, wc, comment, added, false); err != nil {
+		logger.Err(err).Msg("failed to notify mentions")
+	}
+
+	return &CommentView{Comment: comment, AuthorName: user.FullName}, nil
+}
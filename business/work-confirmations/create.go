@@ -2,16 +2,14 @@ package workconfirmations
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
-	"strings"
-	"time"
 
+	"api/business/workflows"
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/usercol"
 	"api/schema/workconfirmationcol"
-	"api/services/minio"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -21,59 +19,20 @@ func Create() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][work-confirmations][create]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
-		// Parse multipart form
-		err := c.Request.ParseMultipartForm(32 << 20) // 32 MB max
-		if err != nil {
-			code := response.ErrorResponse("Failed to parse multipart form")
-			c.JSON(http.StatusBadRequest, code)
+		// Parse multipart form, giới hạn dung lượng cả request
+		if err := parseUploadForm(c); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
 			c.Abort()
 			return
 		}
 
-		// Lấy date, time và content từ form
-		date := c.PostForm("date")
-		startTime := c.PostForm("start_time")
-		endTime := c.PostForm("end_time")
 		content := c.PostForm("content")
-
-		if date == "" {
-			code := response.ErrorResponse("date is required")
-			c.JSON(http.StatusBadRequest, code)
-			c.Abort()
-			return
-		}
-
-		if startTime == "" {
-			code := response.ErrorResponse("start_time is required")
-			c.JSON(http.StatusBadRequest, code)
-			c.Abort()
-			return
-		}
-
-		if endTime == "" {
-			code := response.ErrorResponse("end_time is required")
-			c.JSON(http.StatusBadRequest, code)
-			c.Abort()
-			return
-		}
-
 		if content == "" {
 			code := response.ErrorResponse("content is required")
 			c.JSON(http.StatusBadRequest, code)
@@ -81,41 +40,38 @@ func Create() gin.HandlerFunc {
 			return
 		}
 
-		// Validate date format
-		_, err = time.Parse("2006-01-02", date)
-		if err != nil {
-			code := response.ErrorResponse("Invalid date format. Expected YYYY-MM-DD")
-			c.JSON(http.StatusBadRequest, code)
-			c.Abort()
-			return
+		workConfirmation := &workconfirmationcol.WorkConfirmation{
+			CreatedBy: user.GetIDString(),
+			Content:   content,
 		}
 
-		// Validate time format (HH:MM)
-		_, err = time.Parse("15:04", startTime)
-		if err != nil {
-			code := response.ErrorResponse("Invalid start_time format. Expected HH:MM (24-hour format)")
+		// Thời gian công tác: start_at/end_at/timezone hoặc date/start_time/end_time của client cũ
+		if err := applySpan(c, workConfirmation); err != nil {
+			code := response.ErrorResponse(err.Error())
 			c.JSON(http.StatusBadRequest, code)
 			c.Abort()
 			return
 		}
 
-		_, err = time.Parse("15:04", endTime)
+		// Vị trí check-in tuỳ chọn, gắn địa điểm làm việc chứa vị trí
+		checkIn, err := parseCheckIn(c)
 		if err != nil {
-			code := response.ErrorResponse("Invalid end_time format. Expected HH:MM (24-hour format)")
+			code := response.ErrorResponse(err.Error())
 			c.JSON(http.StatusBadRequest, code)
 			c.Abort()
 			return
 		}
+		if checkIn != nil {
+			tagSite(c.Request.Context(), checkIn)
+			workConfirmation.CheckIn = checkIn
+		}
 
-		// Validate end_time > start_time
-		startTimeObj, _ := time.Parse("15:04", startTime)
-		endTimeObj, _ := time.Parse("15:04", endTime)
-		if !endTimeObj.After(startTimeObj) {
-			code := response.ErrorResponse("end_time must be after start_time")
-			c.JSON(http.StatusBadRequest, code)
-			c.Abort()
+		// Không cho tạo đơn trùng giờ với đơn khác còn hiệu lực của chính mình
+		unlock, ok := checkOverlap(c, workConfirmation)
+		if !ok {
 			return
 		}
+		defer unlock()
 
 		// Xác định role của người tạo từ user
 		creatorRole := user.Role
@@ -133,57 +89,26 @@ func Create() gin.HandlerFunc {
 			return
 		}
 
-		// Upload các file lên MinIO và tạo danh sách photos
-		photos := make([]workconfirmationcol.Photo, 0)
-		bucket := "images"
-		userID := user.GetIDString()
-
-		for _, fileHeader := range formFiles {
-			// Mở file
-			file, err := fileHeader.Open()
-			if err != nil {
-				logger.Err(err).Msgf("failed to open file: %s", fileHeader.Filename)
-				continue
-			}
+		// Kiểm tra định dạng, dung lượng theo nội dung file, đọc EXIF và hash của ảnh trước khi upload
+		uploads, err := preparePhotos(workConfirmation, formFiles)
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
 
-			// Đảm bảo file luôn được đóng
-			func() {
-				defer file.Close()
-
-				// Validate file type (chỉ cho phép image)
-				contentType := fileHeader.Header.Get("Content-Type")
-				if !strings.HasPrefix(contentType, "image/") {
-					logger.Warn().Msgf("invalid file type: %s", contentType)
-					return
-				}
-
-				// Tạo object key: work-confirmations/{user_id}/{timestamp}-{filename}
-				timestamp := time.Now().Unix()
-				filename := fileHeader.Filename
-				// Sanitize filename
-				filename = strings.ReplaceAll(filename, " ", "_")
-				filename = strings.ReplaceAll(filename, "/", "_")
-				objectKey := fmt.Sprintf("work-confirmations/%s/%d-%s", userID, timestamp, filename)
-
-				// Upload lên MinIO
-				_, err = minio.PutObject(bucket, objectKey, file)
-				if err != nil {
-					logger.Err(err).Msgf("failed to upload file to MinIO: %s", filename)
-					return
-				}
-
-				// Tạo URL (giả sử có endpoint từ env, hoặc có thể tạo sau)
-				// TODO: Tạo URL đầy đủ từ MinIO endpoint
-				photoURL := fmt.Sprintf("/%s/%s", bucket, objectKey)
-
-				photos = append(photos, workconfirmationcol.Photo{
-					URL:        photoURL,
-					Filename:   filename,
-					UploadedAt: time.Now(),
-				})
-			}()
+		// Ảnh trùng với ảnh của đơn khác: gắn cảnh báo hoặc từ chối theo PHOTO_DUPLICATE_POLICY
+		if err = checkDuplicates(c.Request.Context(), workConfirmation, uploads); err != nil {
+			code := formError(err)
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
 		}
 
+		// Upload các file lên MinIO và tạo danh sách photos
+		photos := uploadPhotos(user.GetIDString(), uploads, logger)
+
 		if len(photos) == 0 {
 			code := response.ErrorResponse("Failed to upload photos")
 			c.JSON(http.StatusInternalServerError, code)
@@ -191,24 +116,27 @@ func Create() gin.HandlerFunc {
 			return
 		}
 
-		// Xác định status ban đầu
-		status := workconfirmationcol.StatusPendingManager
-		if creatorRole == usercol.RoleManager {
-			status = workconfirmationcol.StatusPendingLeader
-		}
+		workConfirmation.CreatorRole = creatorRole
+		workConfirmation.Photos = photos
 
-		// Tạo đơn mới
-		workConfirmation := &workconfirmationcol.WorkConfirmation{
-			CreatedBy:   user.GetIDString(),
-			CreatorRole: creatorRole,
-			Date:        date,
-			StartTime:   startTime,
-			EndTime:     endTime,
-			Content:     content,
-			Photos:      photos,
-			Status:      status,
+		// Cảnh báo ảnh trùng đơn khác, chụp ngoài thời gian công tác hoặc xa vị trí check-in
+		flagPhotos(workConfirmation)
+
+		// Gắn quy trình duyệt của team và xác định bước đầu tiên
+		err = workflows.Start(c.Request.Context(), workConfirmation)
+		if err != nil {
+			logger.Err(err).Msg("failed to start approval workflow")
+			code := response.ErrorResponse("Failed to start approval workflow")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
 		}
 
+		// Ghi lịch sử tạo đơn cùng lúc với tạo đơn
+		event := newEvent(c, user, workconfirmationcol.EventCreated)
+		event.ToStatus = workConfirmation.Status
+		workConfirmation.History = []workconfirmationcol.Event{*event}
+
 		_, err = workconfirmationcol.Create(c.Request.Context(), workConfirmation)
 		if err != nil {
 			logger.Err(err).Msg("failed to create work confirmation")
@@ -0,0 +1,102 @@
+package workconfirmations
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"api/business/workflows"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errNotPending  = errors.New("WORK_CONFIRMATION_NOT_PENDING")
+	errNotApprover = errors.New("PERMISSION_DENIED: not an approver for the current stage")
+	errNoReason    = errors.New("INVALID_PARAM: reason is required")
+)
+
+// decide duyệt (approve=true) hoặc từ chối bước hiện tại của đơn và lưu kèm lịch sử.
+// Dùng chung cho duyệt từng đơn và duyệt hàng loạt để cùng kiểm tra trạng thái và quyền.
+// Khi có batchId và user đã xử lý đơn trong lô này, trả về replayed=true và không thay đổi gì
+func decide(c *gin.Context, user *usercol.User, wc *workconfirmationcol.WorkConfirmation, approve bool, text, batchId string) (replayed bool, err error) {
+	if decidedInBatch(wc, user.GetIDString(), batchId) {
+		return true, nil
+	}
+
+	if !approve && strings.TrimSpace(text) == "" {
+		return false, errNoReason
+	}
+
+	workflows.EnsureStages(wc)
+	stage := workflows.CurrentStage(wc)
+	if stage == nil {
+		return false, errNotPending
+	}
+
+	// Kiểm tra quyền ở bước hiện tại, kể cả duyệt/từ chối thay theo uỷ quyền
+	allowed, onBehalfOf, err := resolveApprover(c.Request.Context(), user, wc)
+	if err != nil {
+		return false, fmt.Errorf("SERVER_ERROR: failed to verify approval permission: %w", err)
+	}
+	if !allowed {
+		return false, errNotApprover
+	}
+
+	eventType := workconfirmationcol.EventRejected
+	if approve {
+		eventType = workconfirmationcol.EventApproved
+	}
+
+	fromStatus := wc.Status
+	event := newEvent(c, user, eventType)
+	event.OnBehalfOf = onBehalfOf
+	event.StageKey = stage.Key
+	event.Comment = text
+	event.BatchID = batchId
+
+	if approve {
+		// Duyệt bước hiện tại và chuyển sang bước tiếp theo của quy trình
+		err = workflows.Approve(c.Request.Context(), wc, user.GetIDString(), onBehalfOf, text)
+	} else {
+		err = workflows.Reject(wc, user.GetIDString(), onBehalfOf, text)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return false, workflows.Save(c.Request.Context(), wc, fromStatus, event)
+}
+
+// decidedInBatch kiểm tra user đã duyệt/từ chối đơn trong lô batchId chưa
+func decidedInBatch(wc *workconfirmationcol.WorkConfirmation, userId, batchId string) bool {
+	if batchId == "" {
+		return false
+	}
+	for _, e := range wc.History {
+		if e.BatchID == batchId && e.ActorID == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVersion so sánh version client đã đọc (nếu có gửi) với version hiện tại của đơn
+func checkVersion(wc *workconfirmationcol.WorkConfirmation, version *int) error {
+	if version != nil && *version != wc.Version {
+		return workflows.ErrStateChanged
+	}
+	return nil
+}
+
+// formVersion đọc version từ multipart form, nil nếu không gửi hoặc không hợp lệ
+func formVersion(c *gin.Context) *int {
+	version, err := strconv.Atoi(c.PostForm("version"))
+	if err != nil {
+		return nil
+	}
+	return &version
+}
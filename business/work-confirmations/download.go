@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"os"
 
+	"api/business/rbac"
+	"api/business/workflows"
 	"api/internal/plog"
 	"api/internal/response"
 	"api/schema/usercol"
@@ -28,32 +30,6 @@ func Download() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra quyền - Assistant Director và Leader có quyền tải
-		userRole := user.Role
-		if userRole != usercol.RoleAssistantDirector && userRole != usercol.RoleLeader {
-			code := response.ErrorResponse("Only Assistant Director and Leader can download work confirmations")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Tìm đơn
 		workConfirmation, err := workconfirmationcol.FindByID(c.Request.Context(), id)
 		if err != nil {
@@ -76,6 +52,16 @@ func Download() gin.HandlerFunc {
 			logger.Err(err).Msg("failed to get creator info")
 		}
 
+		// Chính sách giờ làm việc và ngày lễ để phân loại giờ công
+		calendar, err := workCalendar(c.Request.Context(), []*workconfirmationcol.WorkConfirmation{workConfirmation})
+		if err != nil {
+			logger.Err(err).Msg("failed to load work calendar")
+			code := response.ErrorResponse("Failed to load work policy")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
 		// Tạo file Excel
 		f := excelize.NewFile()
 		defer func() {
@@ -99,7 +85,7 @@ func Download() gin.HandlerFunc {
 		f.DeleteSheet("Sheet1")
 
 		// Đặt header
-		headers := []string{"ID", "Ngày công tác", "Nội dung", "Người tạo", "Email", "Vai trò", "Trạng thái", "Ngày tạo", "Số lượng ảnh"}
+		headers := []string{"ID", "Ngày công tác", "Bắt đầu", "Kết thúc", "Số giờ", "Trong giờ", "Tăng ca", "Cuối tuần", "Ngày lễ", "Nội dung", "Người tạo", "Email", "Vai trò", "Trạng thái", "Ngày tạo", "Số lượng ảnh"}
 		for i, header := range headers {
 			cell := fmt.Sprintf("%c1", 'A'+i)
 			f.SetCellValue(sheetName, cell, header)
@@ -122,25 +108,23 @@ func Download() gin.HandlerFunc {
 			creatorEmail = creator.Email
 		}
 
-		statusText := string(workConfirmation.Status)
-		switch workConfirmation.Status {
-		case workconfirmationcol.StatusPendingManager:
-			statusText = "Chờ quản lý xác nhận"
-		case workconfirmationcol.StatusPendingLeader:
-			statusText = "Chờ lãnh đạo xác nhận"
-		case workconfirmationcol.StatusApproved:
-			statusText = "Đã duyệt"
-		case workconfirmationcol.StatusRejected:
-			statusText = "Đã từ chối"
-		}
+		statusText := workflows.StatusText(workConfirmation)
+		hours := calendar.Classify(workConfirmation, "", "").Hours()
 
 		rowData := []interface{}{
 			workConfirmation.GetIDString(),
 			workConfirmation.Date,
+			spanText(workConfirmation, workConfirmation.StartAt),
+			spanText(workConfirmation, workConfirmation.EndAt),
+			hours.Total,
+			hours.Normal,
+			hours.Overtime,
+			hours.Weekend,
+			hours.Holiday,
 			workConfirmation.Content,
 			creatorName,
 			creatorEmail,
-			workConfirmation.CreatorRole.Text(),
+			rbac.RoleText(workConfirmation.CreatorRole),
 			statusText,
 			workConfirmation.CreatedAt.Format("2006-01-02 15:04:05"),
 			len(workConfirmation.Photos),
@@ -170,6 +154,35 @@ func Download() gin.HandlerFunc {
 			}
 		}
 
+		// Thêm sheet thời gian theo ngày
+		daysSheetName := "Theo ngày"
+		if _, err := f.NewSheet(daysSheetName); err == nil {
+			for i, header := range dayHeaders {
+				f.SetCellValue(daysSheetName, fmt.Sprintf("%c1", 'A'+i), header)
+			}
+
+			for i, values := range dayRows(workConfirmation) {
+				for j, value := range values {
+					f.SetCellValue(daysSheetName, fmt.Sprintf("%c%d", 'A'+j, i+2), value)
+				}
+			}
+		}
+
+		// Thêm sheet lịch sử thao tác
+		workflows.EnsureStages(workConfirmation)
+		historySheetName := "Lịch sử"
+		if _, err := f.NewSheet(historySheetName); err == nil {
+			for i, header := range timelineHeaders {
+				f.SetCellValue(historySheetName, fmt.Sprintf("%c1", 'A'+i), header)
+			}
+
+			for i, event := range timeline(c, workConfirmation) {
+				for j, value := range timelineRow(workConfirmation, event) {
+					f.SetCellValue(historySheetName, fmt.Sprintf("%c%d", 'A'+j, i+2), value)
+				}
+			}
+		}
+
 		// Lưu file tạm thời
 		tmpFile, err := os.CreateTemp("", "work-confirmation-*.xlsx")
 		if err != nil {
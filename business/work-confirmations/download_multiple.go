@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"os"
 
+	"api/business/rbac"
+	"api/business/workflows"
 	"api/internal/plog"
 	"api/internal/response"
 	"api/schema/usercol"
@@ -37,32 +39,6 @@ func DownloadMultiple() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
-		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		// Kiểm tra quyền - Assistant Director và Leader có quyền tải
-		userRole := user.Role
-		if userRole != usercol.RoleAssistantDirector && userRole != usercol.RoleLeader {
-			code := response.ErrorResponse("Only Assistant Director and Leader can download work confirmations")
-			c.JSON(http.StatusForbidden, code)
-			c.Abort()
-			return
-		}
-
 		// Tìm tất cả các đơn
 		workConfirmations, err := workconfirmationcol.FindByIDs(c.Request.Context(), req.IDs)
 		if err != nil {
@@ -80,6 +56,16 @@ func DownloadMultiple() gin.HandlerFunc {
 			return
 		}
 
+		// Chính sách giờ làm việc và ngày lễ để phân loại giờ công
+		calendar, err := workCalendar(c.Request.Context(), workConfirmations)
+		if err != nil {
+			logger.Err(err).Msg("failed to load work calendar")
+			code := response.ErrorResponse("Failed to load work policy")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
 		// Tạo file Excel
 		f := excelize.NewFile()
 		defer func() {
@@ -103,7 +89,7 @@ func DownloadMultiple() gin.HandlerFunc {
 		f.DeleteSheet("Sheet1")
 
 		// Đặt header
-		headers := []string{"STT", "ID", "Ngày công tác", "Nội dung", "Người tạo", "Email", "Vai trò", "Trạng thái", "Ngày tạo", "Số lượng ảnh"}
+		headers := []string{"STT", "ID", "Ngày công tác", "Bắt đầu", "Kết thúc", "Số giờ", "Trong giờ", "Tăng ca", "Cuối tuần", "Ngày lễ", "Nội dung", "Người tạo", "Email", "Vai trò", "Trạng thái", "Ngày tạo", "Số lượng ảnh"}
 		for i, header := range headers {
 			cell := fmt.Sprintf("%c1", 'A'+i)
 			f.SetCellValue(sheetName, cell, header)
@@ -131,26 +117,24 @@ func DownloadMultiple() gin.HandlerFunc {
 				creatorEmail = creator.Email
 			}
 
-			statusText := string(wc.Status)
-			switch wc.Status {
-			case workconfirmationcol.StatusPendingManager:
-				statusText = "Chờ quản lý xác nhận"
-			case workconfirmationcol.StatusPendingLeader:
-				statusText = "Chờ lãnh đạo xác nhận"
-			case workconfirmationcol.StatusApproved:
-				statusText = "Đã duyệt"
-			case workconfirmationcol.StatusRejected:
-				statusText = "Đã từ chối"
-			}
+			statusText := workflows.StatusText(wc)
+			hours := calendar.Classify(wc, "", "").Hours()
 
 			rowData := []interface{}{
 				idx + 1,
 				wc.GetIDString(),
 				wc.Date,
+				spanText(wc, wc.StartAt),
+				spanText(wc, wc.EndAt),
+				hours.Total,
+				hours.Normal,
+				hours.Overtime,
+				hours.Weekend,
+				hours.Holiday,
 				wc.Content,
 				creatorName,
 				creatorEmail,
-				wc.CreatorRole.Text(),
+				rbac.RoleText(wc.CreatorRole),
 				statusText,
 				wc.CreatedAt.Format("2006-01-02 15:04:05"),
 				len(wc.Photos),
@@ -168,6 +152,45 @@ func DownloadMultiple() gin.HandlerFunc {
 			f.SetColWidth(sheetName, col, col, 15)
 		}
 
+		// Thêm sheet thời gian theo ngày của tất cả đơn
+		daysSheetName := "Theo ngày"
+		if _, err := f.NewSheet(daysSheetName); err == nil {
+			for i, header := range dayHeaders {
+				f.SetCellValue(daysSheetName, fmt.Sprintf("%c1", 'A'+i), header)
+			}
+
+			row := 2
+			for _, wc := range workConfirmations {
+				for _, values := range dayRows(wc) {
+					for j, value := range values {
+						f.SetCellValue(daysSheetName, fmt.Sprintf("%c%d", 'A'+j, row), value)
+					}
+					row++
+				}
+			}
+		}
+
+		// Thêm sheet lịch sử thao tác của tất cả đơn
+		historySheetName := "Lịch sử"
+		if _, err := f.NewSheet(historySheetName); err == nil {
+			historyHeaders := append([]string{"ID"}, timelineHeaders...)
+			for i, header := range historyHeaders {
+				f.SetCellValue(historySheetName, fmt.Sprintf("%c1", 'A'+i), header)
+			}
+
+			row := 2
+			for _, wc := range workConfirmations {
+				workflows.EnsureStages(wc)
+				for _, event := range timeline(c, wc) {
+					values := append([]interface{}{wc.GetIDString()}, timelineRow(wc, event)...)
+					for j, value := range values {
+						f.SetCellValue(historySheetName, fmt.Sprintf("%c%d", 'A'+j, row), value)
+					}
+					row++
+				}
+			}
+		}
+
 		// Lưu file tạm thời
 		tmpFile, err := os.CreateTemp("", "work-confirmations-*.xlsx")
 		if err != nil {
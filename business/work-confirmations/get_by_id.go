@@ -4,10 +4,10 @@ import (
 	"errors"
 	"net/http"
 
+	"api/business/workflows"
 	"api/internal/plog"
 	"api/internal/response"
-	"api/schema/teammembercol"
-	"api/schema/usercol"
+	"api/middleware"
 	"api/schema/workconfirmationcol"
 
 	"github.com/gin-gonic/gin"
@@ -26,20 +26,8 @@ func GetByID() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
@@ -59,45 +47,24 @@ func GetByID() gin.HandlerFunc {
 			return
 		}
 
-		// Kiểm tra quyền truy cập
-		userID := user.GetIDString()
-		userRole := user.Role
-		creatorID := workConfirmation.CreatedBy
+		workflows.EnsureStages(workConfirmation)
 
-		// Nếu đơn không phải của user này, kiểm tra quyền
-		if creatorID != userID {
-			if userRole == usercol.RoleEmployee {
-				// Employee chỉ xem được đơn của mình
-				code := response.ErrorResponse("Access denied")
-				c.JSON(http.StatusForbidden, code)
-				c.Abort()
-				return
-			} else if userRole == usercol.RoleManager {
-				// Manager chỉ xem được đơn của nhân viên trong team
-				belongs, err := teammembercol.CheckEmployeeBelongsToManager(c.Request.Context(), userID, creatorID)
-				if err != nil {
-					logger.Err(err).Msg("failed to check employee relationship")
-					code := response.ErrorResponse("Failed to verify access")
-					c.JSON(http.StatusInternalServerError, code)
-					c.Abort()
-					return
-				}
-				if !belongs {
-					code := response.ErrorResponse("Access denied. You can only view work confirmations from your team members")
-					c.JSON(http.StatusForbidden, code)
-					c.Abort()
-					return
-				}
-			} else if userRole == usercol.RoleLeader || userRole == usercol.RoleAssistantDirector {
-				// Leader và Assistant Director: xem được tất cả
-				// Không cần kiểm tra thêm
-			} else {
-				// Role không xác định
-				code := response.ErrorResponse("Access denied")
-				c.JSON(http.StatusForbidden, code)
-				c.Abort()
-				return
-			}
+		// Kiểm tra quyền truy cập: đơn của mình, của nhân viên trong team, có quyền xem tất cả,
+		// hoặc là người duyệt bước hiện tại
+		allowed, err := canView(c.Request.Context(), user, workConfirmation)
+		if err != nil {
+			logger.Err(err).Msg("failed to check employee relationship")
+			code := response.ErrorResponse("Failed to verify access")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			code := response.ErrorResponse("Access denied")
+			c.JSON(http.StatusForbidden, code)
+			c.Abort()
+			return
 		}
 
 		c.JSON(http.StatusOK, response.SuccessResponse(workConfirmation))
@@ -0,0 +1,307 @@
+package workconfirmations
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"api/business/workflows"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// HistoryEvent là một mục lịch sử kèm tên người thực hiện
+type HistoryEvent struct {
+	workconfirmationcol.Event
+	ActorName      string `json:"actor_name"`
+	OnBehalfOfName string `json:"on_behalf_of_name,omitempty"`
+	Reconstructed  bool   `json:"reconstructed,omitempty"` // Dựng lại cho đơn tạo trước khi có lịch sử
+}
+
+// History xem toàn bộ lịch sử thao tác của đơn theo thứ tự thời gian
+func History() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][history]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workConfirmation, err := workconfirmationcol.FindByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				code := response.ErrorResponse("Work confirmation not found")
+				c.JSON(http.StatusNotFound, code)
+				c.Abort()
+				return
+			}
+			logger.Err(err).Msg("failed to get work confirmation")
+			code := response.ErrorResponse("Failed to get work confirmation")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		workflows.EnsureStages(workConfirmation)
+
+		// Cùng quyền truy cập như xem chi tiết đơn
+		allowed, err := canView(c.Request.Context(), user, workConfirmation)
+		if err != nil {
+			logger.Err(err).Msg("failed to check access")
+			code := response.ErrorResponse("Failed to verify access")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			code := response.ErrorResponse("Access denied")
+			c.JSON(http.StatusForbidden, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(map[string]interface{}{
+			"id":     workConfirmation.GetIDString(),
+			"events": timeline(c, workConfirmation),
+		}))
+	}
+}
+
+// newEvent tạo mục lịch sử kèm nguồn thao tác (IP, user agent, phiên đăng nhập) của request hiện tại
+func newEvent(c *gin.Context, user *usercol.User, eventType workconfirmationcol.EventType) *workconfirmationcol.Event {
+	sessionID := c.GetString("session_id")
+
+	return &workconfirmationcol.Event{
+		Type:      eventType,
+		ActorID:   user.GetIDString(),
+		At:        timer.Now(),
+		IP:        c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+		SessionID: sessionID,
+	}
+}
+
+// spanTime định dạng start_at/end_at trong lịch sử, đơn cũ chưa có giá trị là rỗng
+func spanTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// diffContent liệt kê các trường nội dung thay đổi giữa hai phiên bản đơn
+func diffContent(before, after *workconfirmationcol.WorkConfirmation) []workconfirmationcol.FieldChange {
+	var changes []workconfirmationcol.FieldChange
+
+	add := func(field string, from, to string) {
+		if from != to {
+			changes = append(changes, workconfirmationcol.FieldChange{Field: field, Before: from, After: to})
+		}
+	}
+
+	add("start_at", spanTime(before.StartAt), spanTime(after.StartAt))
+	add("end_at", spanTime(before.EndAt), spanTime(after.EndAt))
+	add("timezone", before.Timezone, after.Timezone)
+	add("date", before.Date, after.Date)
+	add("start_time", before.StartTime, after.StartTime)
+	add("end_time", before.EndTime, after.EndTime)
+	add("content", before.Content, after.Content)
+
+	beforePhotos, afterPhotos := photoURLs(before.Photos), photoURLs(after.Photos)
+	if !equalStrings(beforePhotos, afterPhotos) {
+		changes = append(changes, workconfirmationcol.FieldChange{Field: "photos", Before: beforePhotos, After: afterPhotos})
+	}
+
+	return changes
+}
+
+// timeline trả về lịch sử của đơn kèm tên người thực hiện.
+// Đơn tạo trước khi có lịch sử được dựng lại từ thông tin tạo/duyệt/từ chối còn lưu
+func timeline(c *gin.Context, wc *workconfirmationcol.WorkConfirmation) []HistoryEvent {
+	events := make([]HistoryEvent, 0, len(wc.History))
+	for _, e := range wc.History {
+		events = append(events, HistoryEvent{Event: e})
+	}
+
+	if len(events) == 0 {
+		events = reconstructHistory(wc)
+	}
+
+	names := map[string]string{}
+	nameOf := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		if name, ok := names[id]; ok {
+			return name
+		}
+		name := "N/A"
+		if u, err := usercol.FindWithUserID(c.Request.Context(), id); err == nil {
+			name = u.FullName
+		}
+		names[id] = name
+		return name
+	}
+
+	for i := range events {
+		events[i].ActorName = nameOf(events[i].ActorID)
+		events[i].OnBehalfOfName = nameOf(events[i].OnBehalfOf)
+	}
+
+	return events
+}
+
+func reconstructHistory(wc *workconfirmationcol.WorkConfirmation) []HistoryEvent {
+	events := []HistoryEvent{{
+		Event: workconfirmationcol.Event{
+			Type:    workconfirmationcol.EventCreated,
+			ActorID: wc.CreatedBy,
+			At:      wc.CreatedAt,
+		},
+		Reconstructed: true,
+	}}
+
+	approvals := []struct {
+		stage    string
+		approval *workconfirmationcol.ApprovalInfo
+	}{
+		{workflows.StageManager, wc.ManagerApproval},
+		{workflows.StageLeader, wc.LeaderApproval},
+	}
+	for _, a := range approvals {
+		if a.approval == nil {
+			continue
+		}
+		events = append(events, HistoryEvent{
+			Event: workconfirmationcol.Event{
+				Type:       workconfirmationcol.EventApproved,
+				ActorID:    a.approval.ApprovedBy,
+				OnBehalfOf: a.approval.OnBehalfOf,
+				At:         a.approval.ApprovedAt,
+				StageKey:   a.stage,
+				Comment:    a.approval.Comment,
+			},
+			Reconstructed: true,
+		})
+	}
+
+	if wc.Rejection != nil {
+		events = append(events, HistoryEvent{
+			Event: workconfirmationcol.Event{
+				Type:       workconfirmationcol.EventRejected,
+				ActorID:    wc.Rejection.RejectedBy,
+				OnBehalfOf: wc.Rejection.OnBehalfOf,
+				At:         wc.Rejection.RejectedAt,
+				ToStatus:   workconfirmationcol.StatusRejected,
+				Comment:    wc.Rejection.Reason,
+			},
+			Reconstructed: true,
+		})
+	}
+
+	return events
+}
+
+// timelineHeaders là các cột của sheet lịch sử trong file Excel
+var timelineHeaders = []string{"Thời gian", "Sự kiện", "Người thực hiện", "Thay mặt", "Bước", "Trạng thái trước", "Trạng thái sau", "Ghi chú", "Thay đổi", "IP", "Thiết bị"}
+
+// timelineRow trả về một dòng lịch sử cho file Excel
+func timelineRow(wc *workconfirmationcol.WorkConfirmation, e HistoryEvent) []interface{} {
+	changes := make([]string, 0, len(e.Changes))
+	for _, change := range e.Changes {
+		changes = append(changes, fmt.Sprintf("%s: %v → %v", change.Field, change.Before, change.After))
+	}
+
+	return []interface{}{
+		e.At.Format("2006-01-02 15:04:05"),
+		eventTypeText(e.Type),
+		e.ActorName,
+		e.OnBehalfOfName,
+		stageName(wc, e.StageKey),
+		statusLabel(wc, e.FromStatus),
+		statusLabel(wc, e.ToStatus),
+		e.Comment,
+		strings.Join(changes, "; "),
+		e.IP,
+		e.UserAgent,
+	}
+}
+
+// statusLabel trả về tên trạng thái tiếng Việt, trạng thái chờ duyệt dùng tên bước của đơn
+func statusLabel(wc *workconfirmationcol.WorkConfirmation, status workconfirmationcol.WorkConfirmationStatus) string {
+	switch {
+	case status == "":
+		return ""
+	case status == workconfirmationcol.StatusApproved:
+		return "Đã duyệt"
+	case status == workconfirmationcol.StatusRejected:
+		return "Đã từ chối"
+	case status == workconfirmationcol.StatusCancelled:
+		return "Đã huỷ"
+	case status.IsPending():
+		key := strings.TrimPrefix(string(status), workconfirmationcol.StatusPendingPrefix)
+		return "Chờ " + stageName(wc, key) + " xác nhận"
+	}
+	return string(status)
+}
+
+func stageName(wc *workconfirmationcol.WorkConfirmation, key string) string {
+	for _, stage := range wc.Stages {
+		if stage.Key == key {
+			return stage.Name
+		}
+	}
+	return key
+}
+
+// eventTypeText trả về tên sự kiện tiếng Việt, dùng cho file Excel
+func eventTypeText(t workconfirmationcol.EventType) string {
+	switch t {
+	case workconfirmationcol.EventCreated:
+		return "Tạo đơn"
+	case workconfirmationcol.EventUpdated:
+		return "Chỉnh sửa"
+	case workconfirmationcol.EventApproved:
+		return "Duyệt"
+	case workconfirmationcol.EventRejected:
+		return "Từ chối"
+	case workconfirmationcol.EventWithdrawn:
+		return "Rút đơn"
+	case workconfirmationcol.EventResubmitted:
+		return "Gửi lại"
+	case workconfirmationcol.EventCancelled:
+		return "Huỷ đơn"
+	}
+	return string(t)
+}
+
+func photoURLs(photos []workconfirmationcol.Photo) []string {
+	urls := make([]string, 0, len(photos))
+	for _, p := range photos {
+		urls = append(urls, p.URL)
+	}
+	return urls
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
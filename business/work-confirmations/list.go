@@ -4,11 +4,14 @@ import (
 	"net/http"
 	"strconv"
 
+	"api/business/rbac"
+	"api/business/worksites"
 	"api/internal/plog"
 	"api/internal/response"
+	"api/middleware"
 	"api/schema/teammembercol"
-	"api/schema/usercol"
 	"api/schema/workconfirmationcol"
+	"api/schema/workflowcol"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,20 +22,8 @@ func List() gin.HandlerFunc {
 	logger := plog.NewBizLogger("[business][work-confirmations][list]")
 
 	return func(c *gin.Context) {
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
@@ -45,69 +36,84 @@ func List() gin.HandlerFunc {
 		// Tạo filter
 		filter := primitive.D{}
 
-		// Filter theo role
+		// Filter theo quyền xem
 		userID := user.GetIDString()
-		userRole := user.Role
-
-		if createdBy != "" {
-			// Nếu có created_by trong query, filter theo đó
-			// Nhưng vẫn cần kiểm tra quyền truy cập
-			if userRole == usercol.RoleEmployee {
-				// Employee chỉ có thể xem đơn của chính mình
-				if createdBy != userID {
-					code := response.ErrorResponse("Access denied")
-					c.JSON(http.StatusForbidden, code)
-					c.Abort()
-					return
-				}
-			} else if userRole == usercol.RoleManager {
-				// Manager chỉ có thể xem đơn của nhân viên trong team hoặc của chính mình
-				if createdBy != userID {
-					belongs, err := teammembercol.CheckEmployeeBelongsToManager(c.Request.Context(), userID, createdBy)
-					if err != nil || !belongs {
-						code := response.ErrorResponse("Access denied")
-						c.JSON(http.StatusForbidden, code)
-						c.Abort()
-						return
-					}
-				}
+
+		if c.Query("assigned") == "true" {
+			permissions := rbac.Permissions(user.Role)
+			if permissions == nil {
+				permissions = []rbac.Permission{}
+			}
+
+			// Đơn đang chờ ở bước duyệt giao cho vai trò của user, quyền user có hoặc đích danh user
+			filter = append(filter, primitive.E{Key: "stages", Value: primitive.M{"$elemMatch": primitive.M{
+				"status": workconfirmationcol.StagePending,
+				"$or": []primitive.M{
+					{"approver.type": workflowcol.ApproverRole, "approver.role": user.Role},
+					{"approver.type": workflowcol.ApproverPermission, "approver.permission": primitive.M{"$in": permissions}},
+					{"approver.type": workflowcol.ApproverUser, "approver.user_id": userID},
+				},
+			}}})
+		} else if createdBy != "" {
+			// Nếu có created_by trong query, filter theo đó nhưng vẫn kiểm tra quyền truy cập
+			allowed, err := rbac.CanViewWorkOf(c.Request.Context(), user, createdBy)
+			if err != nil || !allowed {
+				code := response.ErrorResponse("Access denied")
+				c.JSON(http.StatusForbidden, code)
+				c.Abort()
+				return
 			}
-			// Leader và Assistant Director có thể xem tất cả
 			filter = append(filter, primitive.E{Key: "created_by", Value: createdBy})
-		} else {
-			// Không có created_by, filter theo role
-			if userRole == usercol.RoleEmployee {
-				// Employee: chỉ thấy đơn của mình
+		} else if rbac.Can(user, rbac.WorkConfirmationViewAll) {
+			// Xem tất cả đơn (không filter created_by)
+		} else if rbac.Can(user, rbac.WorkConfirmationViewTeam) {
+			// Thấy đơn của nhân viên trong team và của chính mình
+			teamMembers, _, err := teammembercol.FindByManagerID(c.Request.Context(), userID, nil)
+			if err != nil {
+				logger.Err(err).Msg("failed to get team members")
+				// Nếu lỗi, chỉ lấy đơn của chính mình
 				filter = append(filter, primitive.E{Key: "created_by", Value: userID})
-			} else if userRole == usercol.RoleManager {
-				// Manager: thấy đơn của nhân viên trong team và của chính mình
-				// Lấy danh sách employee IDs trong team
-				teamMembers, _, err := teammembercol.FindByManagerID(c.Request.Context(), userID, nil)
-				if err != nil {
-					logger.Err(err).Msg("failed to get team members")
-					// Nếu lỗi, chỉ lấy đơn của chính manager
-					filter = append(filter, primitive.E{Key: "created_by", Value: userID})
-				} else {
-					// Tạo danh sách user IDs (bao gồm cả manager)
-					userIDs := []interface{}{userID}
-					for _, tm := range teamMembers {
-						userIDs = append(userIDs, tm.EmployeeID)
-					}
-					filter = append(filter, primitive.E{Key: "created_by", Value: primitive.D{{Key: "$in", Value: userIDs}}})
-				}
-			} else if userRole == usercol.RoleLeader || userRole == usercol.RoleAssistantDirector {
-				// Leader và Assistant Director: thấy tất cả đơn (không filter created_by)
 			} else {
-				// Role không xác định, mặc định chỉ thấy đơn của mình
-				filter = append(filter, primitive.E{Key: "created_by", Value: userID})
+				// Tạo danh sách user IDs (bao gồm cả quản lý)
+				userIDs := []interface{}{userID}
+				for _, tm := range teamMembers {
+					userIDs = append(userIDs, tm.EmployeeID)
+				}
+				filter = append(filter, primitive.E{Key: "created_by", Value: primitive.D{{Key: "$in", Value: userIDs}}})
 			}
+		} else {
+			// Chỉ thấy đơn của mình
+			filter = append(filter, primitive.E{Key: "created_by", Value: userID})
 		}
 
 		// Filter theo status
-		if status != "" {
+		if status == "pending" {
+			// Đang chờ duyệt ở bất kỳ bước nào
+			filter = append(filter, primitive.E{Key: "status", Value: primitive.Regex{Pattern: "^" + workconfirmationcol.StatusPendingPrefix}})
+		} else if status != "" {
 			filter = append(filter, primitive.E{Key: "status", Value: workconfirmationcol.WorkConfirmationStatus(status)})
 		}
 
+		// Filter theo địa điểm: vị trí check-in trong vùng địa điểm, within (mét) nới rộng địa điểm dạng Point
+		if siteID := c.Query("near_site"); siteID != "" {
+			within, err := strconv.ParseFloat(c.DefaultQuery("within", "0"), 64)
+			if err != nil || within < 0 || within > worksites.MaxRadiusMeters {
+				code := response.ErrorResponse("within must be a number of meters within [0, 50000]")
+				c.JSON(http.StatusBadRequest, code)
+				c.Abort()
+				return
+			}
+
+			near, err := nearSiteFilter(c.Request.Context(), siteID, within)
+			if err != nil {
+				code := response.ErrorResponse(err.Error())
+				c.JSON(code.Code, code)
+				c.Abort()
+				return
+			}
+			filter = append(filter, near)
+		}
+
 		// Setup pagination
 		skip := (page - 1) * limit
 		findOptions := options.Find().
@@ -0,0 +1,74 @@
+package workconfirmations
+
+import (
+	"context"
+
+	"api/internal/plog"
+	"api/internal/thumbnail"
+	"api/internal/timespan"
+	"api/schema/workconfirmationcol"
+)
+
+const migrateBatchSize = 500
+
+// MigrateTimeSpans tính start_at/end_at/timezone/days cho các đơn tạo trước khi có các trường này,
+// từ date/start_time/end_time theo múi giờ mặc định. Chạy lúc khởi động, đơn đã có start_at được bỏ qua
+func MigrateTimeSpans(ctx context.Context) error {
+	logger := plog.NewBizLogger("[business][work-confirmations][migrate-time-spans]")
+
+	loc, err := timespan.LoadLocation("")
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+	lastID := ""
+	for {
+		list, err := workconfirmationcol.FindWithoutTimeSpan(ctx, lastID, migrateBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			break
+		}
+
+		for _, wc := range list {
+			lastID = wc.GetIDString()
+
+			start, end, err := timespan.FromLegacy(wc.Date, wc.StartTime, wc.EndTime, loc)
+			if err != nil {
+				logger.Warn().Str("id", lastID).Msgf("skip work confirmation with invalid date/time: %v", err)
+				continue
+			}
+
+			setSpan(wc, start, end, loc)
+			if err := workconfirmationcol.SetTimeSpan(ctx, wc); err != nil {
+				return err
+			}
+			migrated++
+		}
+	}
+
+	if migrated > 0 {
+		logger.Info().Msgf("migrated %d work confirmations to start_at/end_at", migrated)
+	}
+
+	return nil
+}
+
+// MigratePhotoVariants gán thumb_url/medium_url cho ảnh upload trước khi có ảnh thu nhỏ.
+// Ảnh thu nhỏ được tạo khi truy cập lần đầu như ảnh mới
+func MigratePhotoVariants(ctx context.Context) error {
+	logger := plog.NewBizLogger("[business][work-confirmations][migrate-photo-variants]")
+
+	migrated, err := workconfirmationcol.FillPhotoVariantURLs(ctx, thumbnail.URL("", thumbnail.Thumb), thumbnail.URL("", thumbnail.Medium))
+	if err != nil {
+		return err
+	}
+
+	if migrated > 0 {
+		logger.Info().Msgf("filled photo variant urls of %d work confirmations", migrated)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package workconfirmations
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	searedis "api/internal/redis"
+	sealock "api/internal/redis/lock"
+	"api/internal/response"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// overlaps kiểm tra hai đơn có khoảng thời gian giao nhau không. Đơn kết thúc đúng lúc đơn kia bắt đầu không tính là trùng
+func overlaps(a, b *workconfirmationcol.WorkConfirmation) bool {
+	return a.StartAt.Before(b.EndAt) && b.StartAt.Before(a.EndAt)
+}
+
+// findOverlaps trả về ID các đơn còn hiệu lực khác của cùng người tạo bị trùng giờ với đơn đang tạo/sửa
+func findOverlaps(ctx context.Context, workConfirmation *workconfirmationcol.WorkConfirmation) ([]string, error) {
+	existing, err := workconfirmationcol.FindActiveByCreatorInRange(ctx, workConfirmation.CreatedBy, workConfirmation.StartAt, workConfirmation.EndAt)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]string, 0)
+	for _, other := range existing {
+		if other.GetIDString() == workConfirmation.GetIDString() {
+			continue
+		}
+		if overlaps(workConfirmation, other) {
+			conflicts = append(conflicts, other.GetIDString())
+		}
+	}
+
+	return conflicts, nil
+}
+
+// overlapLockExpiry là số giây giữ khoá theo người tạo, đủ cho kiểm tra ảnh, upload và ghi đơn
+const overlapLockExpiry = 60
+
+// checkOverlap khoá theo người tạo rồi từ chối request khi đơn trùng giờ với đơn khác của cùng người tạo,
+// để hai request đồng thời không cùng qua kiểm tra. Trả về hàm mở khoá (gọi sau khi đã ghi đơn), false nếu đã trả lỗi
+func checkOverlap(c *gin.Context, workConfirmation *workconfirmationcol.WorkConfirmation) (func(), bool) {
+	unlock, err := lockCreator(workConfirmation.CreatedBy)
+	if err != nil {
+		code := response.ErrorResponse("WORK_CONFIRMATION_BUSY: another work confirmation of this user is being saved, retry the request")
+		c.JSON(code.Code, code)
+		c.Abort()
+		return nil, false
+	}
+
+	conflicts, err := findOverlaps(c.Request.Context(), workConfirmation)
+	if err != nil {
+		unlock()
+		code := response.ErrorResponse("Failed to check overlapping work confirmations")
+		c.JSON(http.StatusInternalServerError, code)
+		c.Abort()
+		return nil, false
+	}
+
+	if len(conflicts) > 0 {
+		unlock()
+		code := response.ErrorResponse("WORK_CONFIRMATION_OVERLAP")
+		code.Data = map[string]interface{}{
+			"conflicting_ids": conflicts,
+		}
+		c.JSON(code.Code, code)
+		c.Abort()
+		return nil, false
+	}
+
+	return unlock, true
+}
+
+// lockCreator giữ khoá redis theo người tạo, không khoá khi redis chưa được cấu hình
+func lockCreator(createdBy string) (func(), error) {
+	if searedis.GetClient() == nil {
+		return func() {}, nil
+	}
+
+	mutex, err := sealock.LockTimeout("work_confirmation:creator:"+createdBy, overlapLockExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_, _ = sealock.Unlock(mutex)
+	}, nil
+}
+
+// OverlapGroup là một nhóm đơn của cùng người tạo có khoảng thời gian giao nhau
+type OverlapGroup struct {
+	CreatedBy   string                                  `json:"created_by"`
+	CreatorName string                                  `json:"creator_name"`
+	Date        string                                  `json:"date"` // Ngày bắt đầu của đơn sớm nhất trong nhóm
+	StartAt     time.Time                               `json:"start_at"`
+	EndAt       time.Time                               `json:"end_at"`
+	Items       []*workconfirmationcol.WorkConfirmation `json:"items"`
+}
+
+// overlapGroups gom các đơn trùng giờ theo người tạo. Các đơn nối nhau bằng khoảng thời gian giao nhau
+// (A trùng B, B trùng C) nằm chung một nhóm
+func overlapGroups(list []*workconfirmationcol.WorkConfirmation) []OverlapGroup {
+	byCreator := map[string][]*workconfirmationcol.WorkConfirmation{}
+	creators := make([]string, 0)
+	for _, wc := range list {
+		if wc.StartAt.IsZero() || wc.EndAt.IsZero() {
+			continue
+		}
+		if _, ok := byCreator[wc.CreatedBy]; !ok {
+			creators = append(creators, wc.CreatedBy)
+		}
+		byCreator[wc.CreatedBy] = append(byCreator[wc.CreatedBy], wc)
+	}
+
+	groups := make([]OverlapGroup, 0)
+	for _, creator := range creators {
+		items := byCreator[creator]
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].StartAt.Before(items[j].StartAt)
+		})
+
+		// Quét theo thời điểm bắt đầu, đơn bắt đầu trước thời điểm kết thúc muộn nhất của nhóm hiện tại thì vào nhóm đó
+		current := []*workconfirmationcol.WorkConfirmation{items[0]}
+		groupEnd := items[0].EndAt
+		flush := func() {
+			if len(current) > 1 {
+				groups = append(groups, OverlapGroup{
+					CreatedBy: creator,
+					Date:      current[0].Date,
+					StartAt:   current[0].StartAt,
+					EndAt:     groupEnd,
+					Items:     current,
+				})
+			}
+		}
+		for _, wc := range items[1:] {
+			if wc.StartAt.Before(groupEnd) {
+				current = append(current, wc)
+				if wc.EndAt.After(groupEnd) {
+					groupEnd = wc.EndAt
+				}
+				continue
+			}
+			flush()
+			current = []*workconfirmationcol.WorkConfirmation{wc}
+			groupEnd = wc.EndAt
+		}
+		flush()
+	}
+
+	return groups
+}
@@ -0,0 +1,114 @@
+package workconfirmations
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/internal/timespan"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultOverlapReportDays = 30
+	maxOverlapReportDays     = 366
+)
+
+// OverlapReport liệt kê các nhóm đơn trùng giờ đang còn hiệu lực trên toàn tổ chức.
+// Query: from, to (YYYY-MM-DD, mặc định 30 ngày gần nhất), page, limit
+func OverlapReport() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][overlap-report]")
+
+	return func(c *gin.Context) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 {
+			limit = 20
+		}
+
+		today := timer.Now()
+		to := c.DefaultQuery("to", today.Format("2006-01-02"))
+		from := c.DefaultQuery("from", today.AddDate(0, 0, -defaultOverlapReportDays).Format("2006-01-02"))
+
+		loc, _ := timespan.LoadLocation("")
+
+		fromDate, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: Invalid from format. Expected YYYY-MM-DD")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+		toDate, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: Invalid to format. Expected YYYY-MM-DD")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+		if toDate.Before(fromDate) || toDate.Sub(fromDate) > maxOverlapReportDays*24*time.Hour {
+			code := response.ErrorResponse("INVALID_PARAM: to must be after from and within 366 days")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Đơn có thời gian giao với các ngày từ from tới hết ngày to
+		list, err := workconfirmationcol.FindActiveInRange(c.Request.Context(), fromDate, toDate.AddDate(0, 0, 1))
+		if err != nil {
+			logger.Err(err).Msg("failed to list work confirmations")
+			code := response.ErrorResponse("Failed to list work confirmations")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		groups := overlapGroups(list)
+		total := int64(len(groups))
+
+		start := (page - 1) * limit
+		if start > len(groups) {
+			start = len(groups)
+		}
+		end := start + limit
+		if end > len(groups) {
+			end = len(groups)
+		}
+		pageGroups := groups[start:end]
+
+		// Chỉ lấy tên người tạo cho các nhóm trong trang hiện tại
+		names := map[string]string{}
+		for i := range pageGroups {
+			name, ok := names[pageGroups[i].CreatedBy]
+			if !ok {
+				name = "N/A"
+				if u, err := usercol.FindWithUserID(c.Request.Context(), pageGroups[i].CreatedBy); err == nil {
+					name = u.FullName
+				}
+				names[pageGroups[i].CreatedBy] = name
+			}
+			pageGroups[i].CreatorName = name
+		}
+
+		responseData := map[string]interface{}{
+			"data":       pageGroups,
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"total_page": (total + int64(limit) - 1) / int64(limit),
+			"from":       from,
+			"to":         to,
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(responseData))
+	}
+}
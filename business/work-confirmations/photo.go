@@ -0,0 +1,158 @@
+package workconfirmations
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"api/internal/exif"
+	"api/internal/mongodb/field"
+	"api/internal/timespan"
+	"api/schema/workconfirmationcol"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultPhotoTimeToleranceMinutes = 60
+	defaultPhotoMaxDistanceMeters    = 500
+)
+
+// photoTimeTolerance là độ lệch cho phép giữa thời điểm chụp và thời gian công tác, cấu hình qua PHOTO_TIME_TOLERANCE_MINUTES
+func photoTimeTolerance() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("PHOTO_TIME_TOLERANCE_MINUTES"))
+	if err != nil || minutes < 0 {
+		minutes = defaultPhotoTimeToleranceMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// photoMaxDistance là khoảng cách (mét) tối đa giữa vị trí chụp và vị trí check-in, cấu hình qua PHOTO_MAX_DISTANCE_METERS.
+// Cộng thêm độ chính xác GPS của check-in khi so sánh
+func photoMaxDistance() float64 {
+	meters, err := strconv.ParseFloat(os.Getenv("PHOTO_MAX_DISTANCE_METERS"), 64)
+	if err != nil || meters <= 0 {
+		meters = defaultPhotoMaxDistanceMeters
+	}
+	return meters
+}
+
+// readExif đọc EXIF của ảnh upload. Thời điểm chụp không ghi múi giờ được hiểu theo múi giờ của đơn.
+// Trả về nil nếu ảnh không có EXIF hoặc EXIF hỏng, không chặn upload
+func readExif(data []byte, workConfirmation *workconfirmationcol.WorkConfirmation) *workconfirmationcol.PhotoExif {
+	metadata, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	loc, err := timespan.LoadLocation(workConfirmation.Timezone)
+	if err != nil {
+		loc, _ = timespan.LoadLocation("")
+	}
+
+	photoExif := &workconfirmationcol.PhotoExif{
+		Make:  metadata.Make,
+		Model: metadata.Model,
+	}
+	if takenAt, ok := metadata.TakenAt(loc); ok {
+		photoExif.TakenAt = takenAt
+	}
+	if metadata.HasGPS {
+		photoExif.Location = field.NewPoint(metadata.Longitude, metadata.Latitude)
+	}
+
+	return photoExif
+}
+
+// flagPhotos tính lại cảnh báo ảnh của đơn: ảnh trùng với ảnh của đơn khác lúc upload, ảnh chụp ngoài
+// thời gian công tác (trừ độ lệch cho phép) hoặc vị trí chụp xa vị trí check-in. Ảnh không có EXIF chỉ kiểm tra trùng
+func flagPhotos(workConfirmation *workconfirmationcol.WorkConfirmation) {
+	warnings := make([]workconfirmationcol.PhotoWarning, 0)
+	tolerance := photoTimeTolerance()
+
+	for _, photo := range workConfirmation.Photos {
+		if photo.DuplicateOf != "" {
+			warnings = append(warnings, workconfirmationcol.PhotoWarning{
+				Code:        workconfirmationcol.PhotoDuplicate,
+				Filename:    photo.Filename,
+				Message:     fmt.Sprintf("photo matches a photo on work confirmation %s", photo.DuplicateOf),
+				DuplicateOf: photo.DuplicateOf,
+			})
+		}
+
+		if photo.Exif == nil {
+			continue
+		}
+
+		takenAt := photo.Exif.TakenAt
+		if !takenAt.IsZero() && !workConfirmation.StartAt.IsZero() &&
+			(takenAt.Before(workConfirmation.StartAt.Add(-tolerance)) || takenAt.After(workConfirmation.EndAt.Add(tolerance))) {
+			warnings = append(warnings, workconfirmationcol.PhotoWarning{
+				Code:     workconfirmationcol.PhotoTakenOutsideSpan,
+				Filename: photo.Filename,
+				Message: fmt.Sprintf("photo taken at %s is outside the work time %s - %s",
+					spanText(workConfirmation, takenAt), spanText(workConfirmation, workConfirmation.StartAt), spanText(workConfirmation, workConfirmation.EndAt)),
+			})
+		}
+
+		checkIn := workConfirmation.CheckIn
+		if photo.Exif.Location == nil || checkIn == nil || checkIn.Location == nil {
+			continue
+		}
+		distance, ok := pointDistance(photo.Exif.Location, checkIn.Location)
+		if ok && distance > photoMaxDistance()+checkIn.AccuracyMeters {
+			warnings = append(warnings, workconfirmationcol.PhotoWarning{
+				Code:     workconfirmationcol.PhotoFarFromCheckIn,
+				Filename: photo.Filename,
+				Message:  fmt.Sprintf("photo taken %.0f m from the check-in location", distance),
+			})
+		}
+	}
+
+	workConfirmation.PhotoWarnings = warnings
+}
+
+// pointDistance là khoảng cách (mét) giữa hai GeoJSON Point theo công thức haversine
+func pointDistance(a, b *field.Geometry) (float64, bool) {
+	lng1, lat1, ok := pointCoordinates(a)
+	if !ok {
+		return 0, false
+	}
+	lng2, lat2, ok := pointCoordinates(b)
+	if !ok {
+		return 0, false
+	}
+
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h)), true
+}
+
+// pointCoordinates đọc [longitude, latitude] của Point, toạ độ đọc từ MongoDB có dạng primitive.A
+func pointCoordinates(point *field.Geometry) (float64, float64, bool) {
+	if point.Type != field.Point {
+		return 0, 0, false
+	}
+
+	switch coordinates := point.Coordinates.(type) {
+	case []float64:
+		if len(coordinates) == 2 {
+			return coordinates[0], coordinates[1], true
+		}
+	case primitive.A:
+		if len(coordinates) == 2 {
+			lng, lngOK := coordinates[0].(float64)
+			lat, latOK := coordinates[1].(float64)
+			return lng, lat, lngOK && latOK
+		}
+	}
+
+	return 0, 0, false
+}
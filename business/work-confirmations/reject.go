@@ -6,8 +6,7 @@ import (
 
 	"api/internal/plog"
 	"api/internal/response"
-	"api/schema/teammembercol"
-	"api/schema/usercol"
+	"api/middleware"
 	"api/schema/workconfirmationcol"
 
 	"github.com/gin-gonic/gin"
@@ -15,7 +14,8 @@ import (
 )
 
 type RejectRequest struct {
-	Reason string `json:"reason" binding:"required"`
+	Reason  string `json:"reason" binding:"required"`
+	Version *int   `json:"version"` // Version đơn client đã đọc, bỏ trống để không kiểm tra
 }
 
 func Reject() gin.HandlerFunc {
@@ -38,20 +38,8 @@ func Reject() gin.HandlerFunc {
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
@@ -71,62 +59,31 @@ func Reject() gin.HandlerFunc {
 			return
 		}
 
-		// Kiểm tra: chỉ có thể từ chối khi đơn đang chờ xác nhận
-		if workConfirmation.Status != workconfirmationcol.StatusPendingManager &&
-			workConfirmation.Status != workconfirmationcol.StatusPendingLeader {
-			code := response.ErrorResponse("Work confirmation is not in a state that can be rejected")
-			c.JSON(http.StatusBadRequest, code)
+		// Client gửi kèm version đã đọc để không ghi đè thay đổi của người khác
+		if err := checkVersion(workConfirmation, req.Version); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
 			c.Abort()
 			return
 		}
 
-		// Kiểm tra quyền
-		userID := user.GetIDString()
-		creatorID := workConfirmation.CreatedBy
-
-		if workConfirmation.Status == workconfirmationcol.StatusPendingManager {
-			// Manager chỉ có thể từ chối đơn từ nhân viên trong team
-			if user.Role != usercol.RoleManager {
-				code := response.ErrorResponse("Only managers can reject work confirmations at this stage")
-				c.JSON(http.StatusForbidden, code)
-				c.Abort()
-				return
-			}
-
-			// Kiểm tra employee có thuộc team của manager không
-			if workConfirmation.CreatorRole == usercol.RoleEmployee {
-				belongs, err := teammembercol.CheckEmployeeBelongsToManager(c.Request.Context(), userID, creatorID)
-				if err != nil {
-					logger.Err(err).Msg("failed to check employee relationship")
-					code := response.ErrorResponse("Failed to verify employee relationship")
-					c.JSON(http.StatusInternalServerError, code)
-					c.Abort()
-					return
-				}
-
-				if !belongs {
-					code := response.ErrorResponse("You can only reject work confirmations from your team members")
-					c.JSON(http.StatusForbidden, code)
-					c.Abort()
-					return
-				}
-			}
-		} else if workConfirmation.Status == workconfirmationcol.StatusPendingLeader {
-			// Leader có thể từ chối đơn
-			if user.Role != usercol.RoleLeader {
-				code := response.ErrorResponse("Only leaders can reject work confirmations at this stage")
-				c.JSON(http.StatusForbidden, code)
-				c.Abort()
-				return
-			}
-		}
-
-		// Từ chối đơn
-		err = workconfirmationcol.Reject(c.Request.Context(), id, user.GetIDString(), req.Reason)
-		if err != nil {
+		// Từ chối bước hiện tại: đơn phải đang chờ xác nhận và user là người duyệt bước đó
+		_, err = decide(c, user, workConfirmation, false, req.Reason, "")
+		switch {
+		case errors.Is(err, errNotPending):
+			code := response.ErrorResponse("Work confirmation is not in a state that can be rejected")
+			c.JSON(http.StatusBadRequest, code)
+			c.Abort()
+			return
+		case errors.Is(err, errNotApprover):
+			code := response.ErrorResponse("You are not an approver for the current stage of this work confirmation")
+			c.JSON(http.StatusForbidden, code)
+			c.Abort()
+			return
+		case err != nil:
 			logger.Err(err).Msg("failed to reject work confirmation")
-			code := response.ErrorResponse("Failed to reject work confirmation")
-			c.JSON(http.StatusInternalServerError, code)
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
 			c.Abort()
 			return
 		}
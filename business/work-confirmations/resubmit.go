@@ -0,0 +1,161 @@
+package workconfirmations
+
+import (
+	"errors"
+	"net/http"
+
+	"api/business/workflows"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/middleware"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Resubmit cho phép người tạo sửa đơn bị từ chối và gửi lại, đơn đi lại quy trình duyệt từ đầu.
+// Form giống Update, thêm note là ghi chú phản hồi lý do từ chối
+func Resubmit() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][resubmit]")
+
+	return func(c *gin.Context) {
+		// Parse multipart form, giới hạn dung lượng cả request
+		if err := parseUploadForm(c); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workConfirmation, err := workconfirmationcol.FindByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				code := response.ErrorResponse("Work confirmation not found")
+				c.JSON(http.StatusNotFound, code)
+				c.Abort()
+				return
+			}
+			logger.Err(err).Msg("failed to get work confirmation")
+			code := response.ErrorResponse("Failed to get work confirmation")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		// Client gửi kèm version đã đọc để không ghi đè thay đổi của người khác
+		if err := checkVersion(workConfirmation, formVersion(c)); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Chỉ người tạo mới được gửi lại
+		if workConfirmation.CreatedBy != user.GetIDString() {
+			code := response.ErrorResponse("Only creator can resubmit")
+			c.JSON(http.StatusForbidden, code)
+			c.Abort()
+			return
+		}
+
+		if workConfirmation.Status != workconfirmationcol.StatusRejected {
+			code := response.ErrorResponse("WORK_CONFIRMATION_NOT_REJECTED")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Giữ bản trước khi sửa để ghi lịch sử thay đổi
+		before := *workConfirmation
+
+		if err := applyForm(c, workConfirmation); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Ngày/giờ gửi lại không được trùng với đơn khác còn hiệu lực
+		unlock, ok := checkOverlap(c, workConfirmation)
+		if !ok {
+			return
+		}
+		defer unlock()
+
+		// Kiểm tra ảnh mới, chỉ upload sau khi đơn vào lại được quy trình
+		uploads, err := formPhotos(c, workConfirmation)
+		if err != nil {
+			code := formError(err)
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Gắn lý do từ chối trước đó vào lần gửi lại
+		resubmission := &workconfirmationcol.ResubmissionInfo{
+			Count:         1,
+			ResubmittedAt: timer.Now(),
+			Note:          c.PostForm("note"),
+		}
+		if workConfirmation.Resubmission != nil {
+			resubmission.Count = workConfirmation.Resubmission.Count + 1
+		}
+		if workConfirmation.Rejection != nil {
+			resubmission.PreviousRejection = *workConfirmation.Rejection
+		}
+
+		// Đưa đơn vào lại quy trình từ bước đầu tiên
+		if err := workflows.Restart(c.Request.Context(), workConfirmation); err != nil {
+			logger.Err(err).Msg("failed to restart workflow")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+		workConfirmation.Resubmission = resubmission
+
+		// Upload ảnh mới và thay ảnh cũ
+		replacePhotos(workConfirmation, user.GetIDString(), uploads, logger)
+
+		event := newEvent(c, user, workconfirmationcol.EventResubmitted)
+		event.Changes = diffContent(&before, workConfirmation)
+		event.Comment = resubmission.Note
+		event.FromStatus = workconfirmationcol.StatusRejected
+		event.ToStatus = workConfirmation.Status
+
+		updated, err := workconfirmationcol.Resubmit(c.Request.Context(), workConfirmation, event)
+		if err != nil {
+			logger.Err(err).Msg("failed to resubmit work confirmation")
+			code := response.ErrorResponse("Failed to resubmit work confirmation")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		if !updated {
+			code := response.ErrorResponse("WORK_CONFIRMATION_STATE_CHANGED")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Lấy lại đơn đã cập nhật
+		result, err := workconfirmationcol.FindByID(c.Request.Context(), workConfirmation.GetIDString())
+		if err != nil {
+			logger.Err(err).Msg("failed to get updated work confirmation")
+			code := response.ErrorResponse("Failed to retrieve updated work confirmation")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(result))
+	}
+}
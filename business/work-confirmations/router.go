@@ -1,6 +1,7 @@
 package workconfirmations
 
 import (
+	"api/business/rbac"
 	"api/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -10,12 +11,23 @@ func Router(r *gin.RouterGroup) {
 	// Tất cả routes đều yêu cầu authentication
 	r.Use(middleware.AuthMiddleware())
 
-	r.POST("", Create())                            // Create a new work confirmation
-	r.GET("", List())                               // List all work confirmations
-	r.GET(":id", GetByID())                         // Get a work confirmation by ID
-	r.PUT(":id", Update())                          // Update a work confirmation by ID
-	r.POST(":id/approve", Approve())                // Approve a work confirmation by ID
-	r.POST(":id/reject", Reject())                  // Reject a work confirmation by ID
-	r.GET(":id/download", Download())               // Download a work confirmation by ID
-	r.POST("download-multiple", DownloadMultiple()) // Download multiple work confirmations
+	r.POST("", middleware.Require(rbac.WorkConfirmationCreate), LimitUpload(), middleware.Idempotency(), Create()) // Create a new work confirmation
+	r.GET("", List())                                                                                              // List all work confirmations
+	r.GET("overlaps", middleware.Require(rbac.WorkConfirmationOverlaps), OverlapReport())                          // Report overlapping work confirmations across the organisation
+	r.GET(":id", GetByID())                                                                                        // Get a work confirmation by ID
+	r.GET(":id/history", History())                                                                                // Get the audit timeline of a work confirmation
+	r.PUT(":id", LimitUpload(), Update())                                                                          // Update a work confirmation by ID
+	r.POST("bulk-approve", middleware.Idempotency(), BulkApprove())                                                // Approve many work confirmations, per-item results
+	r.POST("bulk-reject", middleware.Idempotency(), BulkReject())                                                  // Reject many work confirmations, per-item results
+	r.POST(":id/approve", middleware.Idempotency(), Approve())                                                     // Approve a work confirmation by ID
+	r.POST(":id/reject", middleware.Idempotency(), Reject())                                                       // Reject a work confirmation by ID
+	r.POST(":id/withdraw", Withdraw())                                                                             // Creator withdraws a pending work confirmation
+	r.POST(":id/resubmit", Resubmit())                                                                             // Creator edits and resubmits a rejected work confirmation
+	r.POST(":id/cancel", middleware.Require(rbac.WorkConfirmationCancel), Cancel())                                // Cancel an approved work confirmation
+	r.GET(":id/comments", ListComments())                                                                          // List comments of a work confirmation
+	r.POST(":id/comments", CreateComment())                                                                        // Add a comment to a work confirmation
+	r.PUT(":id/comments/:comment_id", UpdateComment())                                                             // Edit own comment within the edit window
+	r.DELETE(":id/comments/:comment_id", DeleteComment())                                                          // Delete own comment within the edit window
+	r.GET(":id/download", middleware.Require(rbac.ReportDownload), Download())                                     // Download a work confirmation by ID
+	r.POST("download-multiple", middleware.Require(rbac.ReportDownload), DownloadMultiple())                       // Download multiple work confirmations
 }
@@ -0,0 +1,177 @@
+package workconfirmations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"api/business/worktime"
+	"api/internal/timespan"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxSpanDays = 31
+
+// maxSpan là thời gian tối đa của một đơn, cấu hình qua WORK_CONFIRMATION_MAX_DAYS
+func maxSpan() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("WORK_CONFIRMATION_MAX_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultMaxSpanDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// setSpan gán thời gian công tác cho đơn, tính lại các trường date/start_time/end_time cho client cũ và phần chia theo ngày
+func setSpan(workConfirmation *workconfirmationcol.WorkConfirmation, start, end time.Time, loc *time.Location) {
+	workConfirmation.StartAt = start.In(loc)
+	workConfirmation.EndAt = end.In(loc)
+	workConfirmation.Timezone = loc.String()
+	workConfirmation.Days = timespan.SplitDays(start, end, loc)
+	workConfirmation.Date, workConfirmation.StartTime, workConfirmation.EndTime = timespan.Legacy(start, end, loc)
+
+	// end_at đúng 00:00 thuộc về ngày trước đó
+	workConfirmation.EndDate = workConfirmation.Date
+	if n := len(workConfirmation.Days); n > 0 {
+		workConfirmation.EndDate = workConfirmation.Days[n-1].Date
+	}
+}
+
+// applySpan đọc thời gian công tác từ form. Client mới gửi start_at/end_at (RFC3339 hoặc YYYY-MM-DDTHH:MM theo timezone),
+// client cũ gửi date + start_time/end_time (end_time không sau start_time là qua đêm).
+// Trường không gửi giữ nguyên giá trị hiện tại của đơn
+func applySpan(c *gin.Context, workConfirmation *workconfirmationcol.WorkConfirmation) error {
+	startAt := c.PostForm("start_at")
+	endAt := c.PostForm("end_at")
+	date := c.PostForm("date")
+	startTime := c.PostForm("start_time")
+	endTime := c.PostForm("end_time")
+	timezone := c.PostForm("timezone")
+
+	if startAt == "" && endAt == "" && date == "" && startTime == "" && endTime == "" && timezone == "" {
+		return nil
+	}
+
+	if timezone == "" {
+		timezone = workConfirmation.Timezone
+	}
+	loc, err := timespan.LoadLocation(timezone)
+	if err != nil {
+		return errors.New("Invalid timezone. Expected an IANA name such as Asia/Ho_Chi_Minh")
+	}
+
+	start, end := workConfirmation.StartAt, workConfirmation.EndAt
+
+	if startAt != "" || endAt != "" {
+		if startAt != "" {
+			if start, err = timespan.Parse(startAt, loc); err != nil {
+				return errors.New("Invalid start_at format. Expected RFC3339 or YYYY-MM-DDTHH:MM")
+			}
+		}
+		if endAt != "" {
+			if end, err = timespan.Parse(endAt, loc); err != nil {
+				return errors.New("Invalid end_at format. Expected RFC3339 or YYYY-MM-DDTHH:MM")
+			}
+		}
+	} else if date != "" || startTime != "" || endTime != "" {
+		if date == "" {
+			date = workConfirmation.Date
+		}
+		if startTime == "" {
+			startTime = workConfirmation.StartTime
+		}
+		if endTime == "" {
+			endTime = workConfirmation.EndTime
+		}
+
+		if date == "" {
+			return errors.New("date is required")
+		}
+		if startTime == "" {
+			return errors.New("start_time is required")
+		}
+		if endTime == "" {
+			return errors.New("end_time is required")
+		}
+
+		if start, end, err = timespan.FromLegacy(date, startTime, endTime, loc); err != nil {
+			if errors.Is(err, timespan.ErrEmptySpan) {
+				return err
+			}
+			return errors.New("Invalid date or time format. Expected YYYY-MM-DD and HH:MM (24-hour format)")
+		}
+	}
+
+	if start.IsZero() || end.IsZero() {
+		return errors.New("start_at and end_at are required")
+	}
+
+	if !end.After(start) {
+		return errors.New("end_at must be after start_at")
+	}
+
+	if end.Sub(start) > maxSpan() {
+		return fmt.Errorf("work confirmation cannot be longer than %d days", int(maxSpan().Hours()/24))
+	}
+
+	setSpan(workConfirmation, start, end, loc)
+	return nil
+}
+
+// dayHeaders là các cột của sheet thời gian theo ngày trong file Excel
+var dayHeaders = []string{"ID", "Ngày", "Từ", "Đến", "Số giờ"}
+
+// spanText định dạng thời điểm theo múi giờ của đơn cho file Excel
+func spanText(wc *workconfirmationcol.WorkConfirmation, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	loc, err := timespan.LoadLocation(wc.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	return t.In(loc).Format("2006-01-02 15:04")
+}
+
+// spanHours đổi số phút sang số giờ, làm tròn 2 chữ số
+func spanHours(minutes int) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
+
+// workCalendar lấy chính sách giờ làm việc và các ngày lễ trong khoảng thời gian của các đơn,
+// dùng để phân loại giờ công trong file Excel
+func workCalendar(ctx context.Context, list []*workconfirmationcol.WorkConfirmation) (*worktime.Calendar, error) {
+	from, to := "", ""
+	for _, wc := range list {
+		for _, day := range wc.Days {
+			if from == "" || day.Date < from {
+				from = day.Date
+			}
+			if day.Date > to {
+				to = day.Date
+			}
+		}
+	}
+
+	return worktime.LoadCalendar(ctx, from, to)
+}
+
+// dayRows trả về các dòng thời gian theo ngày của đơn cho file Excel
+func dayRows(wc *workconfirmationcol.WorkConfirmation) [][]interface{} {
+	rows := make([][]interface{}, 0, len(wc.Days))
+	for _, day := range wc.Days {
+		rows = append(rows, []interface{}{
+			wc.GetIDString(),
+			day.Date,
+			spanText(wc, day.Start),
+			spanText(wc, day.End),
+			spanHours(day.Minutes),
+		})
+	}
+	return rows
+}
@@ -2,16 +2,12 @@ package workconfirmations
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
-	"strings"
-	"time"
 
 	"api/internal/plog"
 	"api/internal/response"
-	"api/schema/usercol"
+	"api/middleware"
 	"api/schema/workconfirmationcol"
-	"api/services/minio"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -29,29 +25,16 @@ func Update() gin.HandlerFunc {
 			return
 		}
 
-		// Parse multipart form
-		err := c.Request.ParseMultipartForm(32 << 20) // 32 MB max
-		if err != nil {
-			code := response.ErrorResponse("Failed to parse multipart form")
-			c.JSON(http.StatusBadRequest, code)
+		// Parse multipart form, giới hạn dung lượng cả request
+		if err := parseUploadForm(c); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
 			c.Abort()
 			return
 		}
 
-		// Lấy user từ context
-		userInterface, exists := c.Get("current_user")
-		if !exists {
-			code := response.ErrorResponse("Unauthorized")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*usercol.User)
+		user, ok := middleware.ContextUser(c)
 		if !ok {
-			code := response.ErrorResponse("Invalid user")
-			c.JSON(http.StatusUnauthorized, code)
-			c.Abort()
 			return
 		}
 
@@ -71,6 +54,14 @@ func Update() gin.HandlerFunc {
 			return
 		}
 
+		// Client gửi kèm version đã đọc để không ghi đè thay đổi của người khác
+		if err := checkVersion(workConfirmation, formVersion(c)); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
 		// Kiểm tra quyền: chỉ người tạo mới được sửa
 		if workConfirmation.CreatedBy != user.GetIDString() {
 			code := response.ErrorResponse("Only creator can update")
@@ -80,154 +71,48 @@ func Update() gin.HandlerFunc {
 		}
 
 		// Kiểm tra: chỉ được sửa khi chưa được xác nhận
-		if workConfirmation.Status != workconfirmationcol.StatusPendingManager &&
-			workConfirmation.Status != workconfirmationcol.StatusPendingLeader {
+		if !workConfirmation.Status.IsPending() {
 			code := response.ErrorResponse("Cannot update work confirmation that has been approved or rejected")
 			c.JSON(http.StatusBadRequest, code)
 			c.Abort()
 			return
 		}
 
-		// Lấy date, time và content từ form
-		date := c.PostForm("date")
-		startTime := c.PostForm("start_time")
-		endTime := c.PostForm("end_time")
-		content := c.PostForm("content")
-
-		// Cập nhật thông tin
-		if date != "" {
-			// Validate date format
-			_, err := time.Parse("2006-01-02", date)
-			if err != nil {
-				code := response.ErrorResponse("Invalid date format. Expected YYYY-MM-DD")
-				c.JSON(http.StatusBadRequest, code)
-				c.Abort()
-				return
-			}
-			workConfirmation.Date = date
-		}
-
-		if startTime != "" {
-			// Validate time format
-			_, err := time.Parse("15:04", startTime)
-			if err != nil {
-				code := response.ErrorResponse("Invalid start_time format. Expected HH:MM (24-hour format)")
-				c.JSON(http.StatusBadRequest, code)
-				c.Abort()
-				return
-			}
-			workConfirmation.StartTime = startTime
-		}
+		// Giữ bản trước khi sửa để ghi lịch sử thay đổi
+		before := *workConfirmation
 
-		if endTime != "" {
-			// Validate time format
-			_, err := time.Parse("15:04", endTime)
-			if err != nil {
-				code := response.ErrorResponse("Invalid end_time format. Expected HH:MM (24-hour format)")
-				c.JSON(http.StatusBadRequest, code)
-				c.Abort()
-				return
-			}
-			workConfirmation.EndTime = endTime
+		// Cập nhật thời gian công tác và content từ form
+		if err := applyForm(c, workConfirmation); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
 		}
 
-		// Validate end_time > start_time if both are provided
-		if startTime != "" && endTime != "" {
-			startTimeObj, _ := time.Parse("15:04", startTime)
-			endTimeObj, _ := time.Parse("15:04", endTime)
-			if !endTimeObj.After(startTimeObj) {
-				code := response.ErrorResponse("end_time must be after start_time")
-				c.JSON(http.StatusBadRequest, code)
-				c.Abort()
-				return
-			}
-		} else if startTime != "" && workConfirmation.EndTime != "" {
-			// If only startTime is updated, validate against existing endTime
-			startTimeObj, _ := time.Parse("15:04", startTime)
-			endTimeObj, _ := time.Parse("15:04", workConfirmation.EndTime)
-			if !endTimeObj.After(startTimeObj) {
-				code := response.ErrorResponse("end_time must be after start_time")
-				c.JSON(http.StatusBadRequest, code)
-				c.Abort()
-				return
-			}
-		} else if endTime != "" && workConfirmation.StartTime != "" {
-			// If only endTime is updated, validate against existing startTime
-			startTimeObj, _ := time.Parse("15:04", workConfirmation.StartTime)
-			endTimeObj, _ := time.Parse("15:04", endTime)
-			if !endTimeObj.After(startTimeObj) {
-				code := response.ErrorResponse("end_time must be after start_time")
-				c.JSON(http.StatusBadRequest, code)
-				c.Abort()
-				return
-			}
+		// Ngày/giờ sau khi sửa không được trùng với đơn khác còn hiệu lực
+		unlock, ok := checkOverlap(c, workConfirmation)
+		if !ok {
+			return
 		}
+		defer unlock()
 
-		if content != "" {
-			workConfirmation.Content = content
+		// Kiểm tra ảnh mới, chỉ upload khi mọi kiểm tra đã qua
+		uploads, err := formPhotos(c, workConfirmation)
+		if err != nil {
+			code := formError(err)
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
 		}
+		replacePhotos(workConfirmation, user.GetIDString(), uploads, logger)
 
-		// Xử lý photos nếu có
-		formFiles := c.Request.MultipartForm.File["photos"]
-		if len(formFiles) > 0 {
-			// Upload các file mới lên MinIO và thay thế photos cũ
-			photos := make([]workconfirmationcol.Photo, 0)
-			bucket := "images"
-			userID := user.GetIDString()
-
-			for _, fileHeader := range formFiles {
-				// Mở file
-				file, err := fileHeader.Open()
-				if err != nil {
-					logger.Err(err).Msgf("failed to open file: %s", fileHeader.Filename)
-					continue
-				}
-
-				// Đảm bảo file luôn được đóng
-				func() {
-					defer file.Close()
-
-					// Validate file type (chỉ cho phép image)
-					contentType := fileHeader.Header.Get("Content-Type")
-					if !strings.HasPrefix(contentType, "image/") {
-						logger.Warn().Msgf("invalid file type: %s", contentType)
-						return
-					}
-
-					// Tạo object key: work-confirmations/{user_id}/{timestamp}-{filename}
-					timestamp := time.Now().Unix()
-					filename := fileHeader.Filename
-					// Sanitize filename
-					filename = strings.ReplaceAll(filename, " ", "_")
-					filename = strings.ReplaceAll(filename, "/", "_")
-					objectKey := fmt.Sprintf("work-confirmations/%s/%d-%s", userID, timestamp, filename)
-
-					// Upload lên MinIO
-					_, err = minio.PutObject(bucket, objectKey, file)
-					if err != nil {
-						logger.Err(err).Msgf("failed to upload file to MinIO: %s", filename)
-						return
-					}
-
-					// Tạo URL
-					photoURL := fmt.Sprintf("/%s/%s", bucket, objectKey)
-
-					photos = append(photos, workconfirmationcol.Photo{
-						URL:        photoURL,
-						Filename:   filename,
-						UploadedAt: time.Now(),
-					})
-				}()
-			}
-
-			// Chỉ cập nhật photos nếu có ít nhất 1 file upload thành công
-			if len(photos) > 0 {
-				workConfirmation.Photos = photos
-			}
-		}
+		// Lưu cập nhật cùng lịch sử thay đổi
+		event := newEvent(c, user, workconfirmationcol.EventUpdated)
+		event.Changes = diffContent(&before, workConfirmation)
+		event.FromStatus = workConfirmation.Status
+		event.ToStatus = workConfirmation.Status
 
-		// Lưu cập nhật
-		_, err = workconfirmationcol.Update(c.Request.Context(), workConfirmation)
+		updated, err := workconfirmationcol.UpdateContent(c.Request.Context(), workConfirmation, event)
 		if err != nil {
 			logger.Err(err).Msg("failed to update work confirmation")
 			code := response.ErrorResponse("Failed to update work confirmation")
@@ -236,8 +121,15 @@ func Update() gin.HandlerFunc {
 			return
 		}
 
+		if !updated {
+			code := response.ErrorResponse("WORK_CONFIRMATION_STATE_CHANGED")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
 		// Lấy lại đơn đã cập nhật
-		updated, err := workconfirmationcol.FindByID(c.Request.Context(), id)
+		result, err := workconfirmationcol.FindByID(c.Request.Context(), id)
 		if err != nil {
 			logger.Err(err).Msg("failed to get updated work confirmation")
 			code := response.ErrorResponse("Failed to retrieve updated work confirmation")
@@ -246,6 +138,57 @@ func Update() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, response.SuccessResponse(updated))
+		c.JSON(http.StatusOK, response.SuccessResponse(result))
 	}
 }
+
+// applyForm cập nhật thời gian công tác và content của đơn từ multipart form,
+// chỉ các trường có gửi lên mới được thay đổi
+func applyForm(c *gin.Context, workConfirmation *workconfirmationcol.WorkConfirmation) error {
+	// Thời gian công tác
+	if err := applySpan(c, workConfirmation); err != nil {
+		return err
+	}
+
+	if content := c.PostForm("content"); content != "" {
+		workConfirmation.Content = content
+	}
+
+	return nil
+}
+
+// formPhotos kiểm tra định dạng, dung lượng, đọc EXIF và hash của ảnh mới trong form, sau đó kiểm tra ảnh trùng
+// với đơn khác theo PHOTO_DUPLICATE_POLICY. Trả về nil khi form không có ảnh. Cần gọi sau applyForm
+// để EXIF được đọc theo múi giờ mới của đơn
+func formPhotos(c *gin.Context, workConfirmation *workconfirmationcol.WorkConfirmation) ([]*photoUpload, error) {
+	formFiles := c.Request.MultipartForm.File["photos"]
+	if len(formFiles) == 0 {
+		return nil, nil
+	}
+
+	uploads, err := preparePhotos(workConfirmation, formFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkDuplicates(c.Request.Context(), workConfirmation, uploads); err != nil {
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+// replacePhotos upload ảnh mới đã kiểm tra lên MinIO và thay thế photos cũ, sau đó tính lại cảnh báo ảnh
+// vì thời gian công tác hoặc ảnh có thể đã thay đổi
+func replacePhotos(workConfirmation *workconfirmationcol.WorkConfirmation, userID string, uploads []*photoUpload, logger plog.Logger) {
+	if len(uploads) > 0 {
+		photos := uploadPhotos(userID, uploads, logger)
+
+		// Chỉ cập nhật photos nếu có ít nhất 1 file upload thành công
+		if len(photos) > 0 {
+			workConfirmation.Photos = photos
+		}
+	}
+
+	flagPhotos(workConfirmation)
+}
@@ -0,0 +1,316 @@
+package workconfirmations
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/imagehash"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/thumbnail"
+	"api/schema/workconfirmationcol"
+	"api/services/minio"
+
+	"github.com/gin-gonic/gin"
+	"github.com/h2non/filetype"
+)
+
+const (
+	defaultPhotoMaxFileMB    = 10
+	defaultPhotoMaxRequestMB = 30
+	defaultPhotoAllowedTypes = "jpg,png,webp,heif"
+
+	// formOverhead là phần dành cho các trường text và header multipart ngoài dung lượng file
+	formOverhead = 1 << 20
+	// formMemory là phần multipart form giữ trong bộ nhớ, phần còn lại ghi ra file tạm
+	formMemory = 32 << 20
+
+	// duplicateDistance là số bit khác nhau tối đa giữa hai hash để coi là cùng một ảnh,
+	// nhỏ hơn imagehash.BandCount để ảnh trùng luôn có chung ít nhất một đoạn hash
+	duplicateDistance = imagehash.BandCount - 1
+
+	// PHOTO_DUPLICATE_POLICY
+	duplicatePolicyFlag   = "flag"   // Lưu đơn, gắn cảnh báo cho người duyệt
+	duplicatePolicyReject = "reject" // Từ chối request
+)
+
+// envMB đọc cấu hình dung lượng (MB) từ biến môi trường, trả về bytes
+func envMB(key string, fallback int) int64 {
+	mb, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || mb <= 0 {
+		mb = fallback
+	}
+	return int64(mb) << 20
+}
+
+// maxUploadRequest là dung lượng body tối đa của request upload ảnh, gồm PHOTO_MAX_REQUEST_MB và phần trường text
+func maxUploadRequest() int64 {
+	return envMB("PHOTO_MAX_REQUEST_MB", defaultPhotoMaxRequestMB) + formOverhead
+}
+
+// LimitUpload giới hạn body theo PHOTO_MAX_REQUEST_MB ngay từ đầu route, trước cả middleware đọc body như Idempotency
+func LimitUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadRequest())
+		c.Next()
+	}
+}
+
+// parseUploadForm giới hạn body theo PHOTO_MAX_REQUEST_MB trước khi parse multipart form,
+// để file lớn không bị ghi hết ra đĩa trước khi bị từ chối. Body vượt giới hạn trả về lỗi REQUEST_TOO_LARGE
+func parseUploadForm(c *gin.Context) error {
+	maxRequest := envMB("PHOTO_MAX_REQUEST_MB", defaultPhotoMaxRequestMB)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadRequest())
+
+	if err := c.Request.ParseMultipartForm(formMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("REQUEST_TOO_LARGE: request body is larger than %d MB", maxRequest>>20)
+		}
+		return errors.New("Failed to parse multipart form")
+	}
+	return nil
+}
+
+// allowedPhotoTypes là các định dạng ảnh được upload (phần mở rộng theo h2non/filetype), cấu hình qua PHOTO_ALLOWED_TYPES
+func allowedPhotoTypes() []string {
+	value := os.Getenv("PHOTO_ALLOWED_TYPES")
+	if strings.TrimSpace(value) == "" {
+		value = defaultPhotoAllowedTypes
+	}
+
+	types := make([]string, 0)
+	for _, t := range strings.Split(value, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// duplicatePolicy là cách xử lý ảnh trùng với ảnh của đơn khác, cấu hình qua PHOTO_DUPLICATE_POLICY (flag | reject)
+func duplicatePolicy() string {
+	if os.Getenv("PHOTO_DUPLICATE_POLICY") == duplicatePolicyReject {
+		return duplicatePolicyReject
+	}
+	return duplicatePolicyFlag
+}
+
+// photoUpload là ảnh đã kiểm tra, chờ upload lên MinIO
+type photoUpload struct {
+	data  []byte
+	photo workconfirmationcol.Photo
+}
+
+// preparePhotos kiểm tra dung lượng từng file và cả request, định dạng theo nội dung file (magic bytes),
+// sau đó đọc EXIF và tính hash của ảnh. Lỗi ở bất kỳ file nào từ chối cả request
+func preparePhotos(workConfirmation *workconfirmationcol.WorkConfirmation, formFiles []*multipart.FileHeader) ([]*photoUpload, error) {
+	maxFile := envMB("PHOTO_MAX_FILE_MB", defaultPhotoMaxFileMB)
+	maxRequest := envMB("PHOTO_MAX_REQUEST_MB", defaultPhotoMaxRequestMB)
+
+	var total int64
+	for _, fileHeader := range formFiles {
+		if fileHeader.Size > maxFile {
+			return nil, fmt.Errorf("PHOTO_TOO_LARGE: %s is larger than %d MB", fileHeader.Filename, maxFile>>20)
+		}
+		total += fileHeader.Size
+	}
+	if total > maxRequest {
+		return nil, fmt.Errorf("PHOTO_TOO_LARGE: photos are larger than %d MB in total", maxRequest>>20)
+	}
+
+	allowed := allowedPhotoTypes()
+	uploads := make([]*photoUpload, 0, len(formFiles))
+	for _, fileHeader := range formFiles {
+		data, err := readUpload(fileHeader, maxFile)
+		if err != nil {
+			return nil, err
+		}
+
+		// Định dạng theo nội dung file, không tin Content-Type client gửi
+		kind, _ := filetype.Match(data)
+		if kind == filetype.Unknown || !containsString(allowed, kind.Extension) {
+			return nil, fmt.Errorf("PHOTO_TYPE_NOT_ALLOWED: %s must be one of %s", fileHeader.Filename, strings.Join(allowed, ", "))
+		}
+
+		// Sanitize filename
+		filename := strings.ReplaceAll(fileHeader.Filename, " ", "_")
+		filename = strings.ReplaceAll(filename, "/", "_")
+
+		sum := sha256.Sum256(data)
+		upload := &photoUpload{
+			data: data,
+			photo: workconfirmationcol.Photo{
+				Filename:    filename,
+				ContentType: kind.MIME.Value,
+				Size:        int64(len(data)),
+				Exif:        readExif(data, workConfirmation),
+				SHA256:      hex.EncodeToString(sum[:]),
+			},
+		}
+
+		// Giải mã được JPEG/PNG/GIF và WebP (decoder đăng ký bởi internal/thumbnail), HEIC chỉ so khớp bằng sha256
+		if hash, err := imagehash.FromBytes(data); err == nil {
+			upload.photo.Hash = imagehash.Format(hash)
+			upload.photo.HashBands = imagehash.Bands(hash)
+		}
+
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, nil
+}
+
+// readUpload đọc toàn bộ file upload, file thực tế lớn hơn maxSize bị từ chối
+func readUpload(fileHeader *multipart.FileHeader, maxSize int64) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fileHeader.Filename, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", fileHeader.Filename, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("PHOTO_TOO_LARGE: %s is larger than %d MB", fileHeader.Filename, maxSize>>20)
+	}
+
+	return data, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// markDuplicates gắn duplicate_of cho ảnh trùng (hash lệch không quá duplicateDistance bit, hoặc cùng sha256)
+// với ảnh của đơn còn hiệu lực khác, lấy đơn tạo sớm nhất
+func markDuplicates(ctx context.Context, workConfirmation *workconfirmationcol.WorkConfirmation, uploads []*photoUpload) error {
+	bands := make([]string, 0)
+	sums := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		bands = append(bands, upload.photo.HashBands...)
+		sums = append(sums, upload.photo.SHA256)
+	}
+
+	// Đơn đang tạo chưa có ID, đơn đang sửa bỏ qua ảnh cũ của chính nó
+	candidates, err := workconfirmationcol.FindByPhotoHashes(ctx, bands, sums, workConfirmation.GetIDString())
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		for _, other := range candidates {
+			if containsDuplicate(other.Photos, &upload.photo) {
+				upload.photo.DuplicateOf = other.GetIDString()
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// containsDuplicate kiểm tra photo có trùng với ảnh nào trong danh sách không
+func containsDuplicate(photos []workconfirmationcol.Photo, photo *workconfirmationcol.Photo) bool {
+	hash, err := imagehash.Parse(photo.Hash)
+	hasHash := photo.Hash != "" && err == nil
+
+	for _, other := range photos {
+		if other.SHA256 != "" && other.SHA256 == photo.SHA256 {
+			return true
+		}
+		if !hasHash || other.Hash == "" {
+			continue
+		}
+		if otherHash, err := imagehash.Parse(other.Hash); err == nil && imagehash.Distance(hash, otherHash) <= duplicateDistance {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDuplicates đánh dấu ảnh trùng với đơn khác. Khi PHOTO_DUPLICATE_POLICY=reject trả về lỗi PHOTO_DUPLICATE
+// kèm danh sách ảnh trùng và ID đơn có ảnh đó
+func checkDuplicates(ctx context.Context, workConfirmation *workconfirmationcol.WorkConfirmation, uploads []*photoUpload) error {
+	if err := markDuplicates(ctx, workConfirmation, uploads); err != nil {
+		plog.NewBizLogger("[business][work-confirmations][check-duplicates]").Err(err).Msg("failed to find duplicate photos")
+		return errors.New("SERVER_ERROR: failed to check duplicate photos")
+	}
+
+	if duplicatePolicy() != duplicatePolicyReject {
+		return nil
+	}
+
+	duplicates := make([]map[string]string, 0)
+	for _, upload := range uploads {
+		if upload.photo.DuplicateOf != "" {
+			duplicates = append(duplicates, map[string]string{
+				"filename":             upload.photo.Filename,
+				"work_confirmation_id": upload.photo.DuplicateOf,
+			})
+		}
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	code := response.ErrorResponse("PHOTO_DUPLICATE")
+	code.Data = map[string]interface{}{
+		"duplicates": duplicates,
+	}
+	return code
+}
+
+// uploadPhotos upload ảnh đã kiểm tra lên MinIO, bỏ qua ảnh upload lỗi
+func uploadPhotos(userID string, uploads []*photoUpload, logger plog.Logger) []workconfirmationcol.Photo {
+	photos := make([]workconfirmationcol.Photo, 0, len(uploads))
+	bucket := "images"
+
+	for _, upload := range uploads {
+		// Tạo object key: work-confirmations/{user_id}/{timestamp}-{filename}
+		timestamp := time.Now().Unix()
+		objectKey := fmt.Sprintf("work-confirmations/%s/%d-%s", userID, timestamp, upload.photo.Filename)
+
+		// Upload lên MinIO
+		if _, err := minio.PutObject(bucket, objectKey, bytes.NewReader(upload.data)); err != nil {
+			logger.Err(err).Msgf("failed to upload file to MinIO: %s", upload.photo.Filename)
+			continue
+		}
+
+		photo := upload.photo
+		photo.URL = fmt.Sprintf("/%s/%s", bucket, objectKey)
+		photo.ThumbURL = thumbnail.URL(photo.URL, thumbnail.Thumb)
+		photo.MediumURL = thumbnail.URL(photo.URL, thumbnail.Medium)
+		photo.UploadedAt = time.Now()
+		photos = append(photos, photo)
+	}
+
+	return photos
+}
+
+// formError trả về response lỗi của err, giữ nguyên data khi err đã là response (ví dụ PHOTO_DUPLICATE)
+func formError(err error) *response.Response {
+	var code *response.Response
+	if errors.As(err, &code) {
+		return code
+	}
+	return response.ErrorResponse(err.Error())
+}
@@ -0,0 +1,110 @@
+package workconfirmations
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"api/business/workflows"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type WithdrawRequest struct {
+	Reason  string `json:"reason"`
+	Version *int   `json:"version"` // Version đơn client đã đọc, bỏ trống để không kiểm tra
+}
+
+// Withdraw cho phép người tạo rút lại đơn đang chờ duyệt, đơn chuyển sang trạng thái đã huỷ
+func Withdraw() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][work-confirmations][withdraw]")
+
+	return func(c *gin.Context) {
+		var req WithdrawRequest
+		// reason is optional, an empty body is allowed
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workConfirmation, err := workconfirmationcol.FindByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				code := response.ErrorResponse("Work confirmation not found")
+				c.JSON(http.StatusNotFound, code)
+				c.Abort()
+				return
+			}
+			logger.Err(err).Msg("failed to get work confirmation")
+			code := response.ErrorResponse("Failed to get work confirmation")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		// Client gửi kèm version đã đọc để không ghi đè thay đổi của người khác
+		if err := checkVersion(workConfirmation, req.Version); err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Chỉ người tạo mới được rút đơn
+		if workConfirmation.CreatedBy != user.GetIDString() {
+			code := response.ErrorResponse("Only creator can withdraw")
+			c.JSON(http.StatusForbidden, code)
+			c.Abort()
+			return
+		}
+
+		workflows.EnsureStages(workConfirmation)
+		stage := workflows.CurrentStage(workConfirmation)
+		if stage == nil {
+			code := response.ErrorResponse("WORK_CONFIRMATION_NOT_PENDING")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		fromStatus := workConfirmation.Status
+		event := newEvent(c, user, workconfirmationcol.EventWithdrawn)
+		event.StageKey = stage.Key
+		event.Comment = req.Reason
+		err = workflows.Withdraw(workConfirmation, user.GetIDString(), req.Reason)
+		if err == nil {
+			err = workflows.Save(c.Request.Context(), workConfirmation, fromStatus, event)
+		}
+		if err != nil {
+			logger.Err(err).Msg("failed to withdraw work confirmation")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		// Lấy lại đơn đã cập nhật
+		updated, err := workconfirmationcol.FindByID(c.Request.Context(), workConfirmation.GetIDString())
+		if err != nil {
+			logger.Err(err).Msg("failed to get updated work confirmation")
+			code := response.ErrorResponse("Failed to retrieve updated work confirmation")
+			c.JSON(http.StatusInternalServerError, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(updated))
+	}
+}
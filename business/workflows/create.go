@@ -0,0 +1,88 @@
+package workflows
+
+import (
+	"net/http"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/usercol"
+	"api/schema/workflowcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Stages      []workflowcol.Stage `json:"stages" binding:"required"`
+	TeamIDs     []string            `json:"team_ids"`   // Team áp dụng, team đang thuộc quy trình khác sẽ được chuyển sang
+	IsDefault   bool                `json:"is_default"` // Áp dụng cho team chưa được gán quy trình
+}
+
+// Create tạo quy trình duyệt với các bước theo thứ tự và gán cho team
+func Create() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][workflows][create]")
+
+	return func(c *gin.Context) {
+		var req CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		workflow, err := doCreate(c, user, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to create workflow")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(workflow))
+	}
+}
+
+func doCreate(c *gin.Context, user *usercol.User, req CreateRequest) (*workflowcol.Workflow, error) {
+	ctx := c.Request.Context()
+
+	if err := validateStages(ctx, req.Stages); err != nil {
+		return nil, err
+	}
+
+	if err := validateTeams(ctx, req.TeamIDs); err != nil {
+		return nil, err
+	}
+
+	if req.TeamIDs == nil {
+		req.TeamIDs = []string{}
+	}
+
+	workflow := &workflowcol.Workflow{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Stages:      req.Stages,
+		TeamIDs:     req.TeamIDs,
+		IsDefault:   req.IsDefault,
+		CreatedBy:   user.GetIDString(),
+	}
+
+	if _, err := workflowcol.Create(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := afterSave(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
@@ -0,0 +1,35 @@
+package workflows
+
+import (
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/workflowcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete xoá quy trình. Team của quy trình chuyển về quy trình mặc định, đơn đang duyệt không bị ảnh hưởng
+func Delete() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][workflows][delete]")
+
+	return func(c *gin.Context) {
+		workflow, err := findWorkflow(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if err = workflowcol.SoftDelete(c.Request.Context(), workflow.GetIDString()); err != nil {
+			logger.Err(err).Msg("failed to delete workflow")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to delete workflow"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
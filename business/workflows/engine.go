@@ -0,0 +1,433 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+
+	"api/business/rbac"
+	"api/internal/timer"
+	"api/schema/teammembercol"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+	"api/schema/workflowcol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrStateChanged là lỗi khi đơn đã được người khác xử lý trước lúc lưu
+var ErrStateChanged = errors.New("WORK_CONFIRMATION_STATE_CHANGED")
+
+const (
+	StageManager = "manager"
+	StageLeader  = "leader"
+)
+
+// LegacyLeaderApprover là rule bước lãnh đạo trước khi duyệt theo quyền, còn trong quy trình và đơn đã lưu
+var LegacyLeaderApprover = workflowcol.ApproverRule{Type: workflowcol.ApproverRole, Role: usercol.RoleLeader}
+
+// LeaderApprover là rule bước lãnh đạo: bất kỳ ai có quyền duyệt cuối
+var LeaderApprover = workflowcol.ApproverRule{Type: workflowcol.ApproverPermission, Permission: string(rbac.WorkConfirmationApproveFinal)}
+
+// DefaultStages là quy trình có sẵn khi chưa cấu hình quy trình mặc định:
+// quản lý trực tiếp rồi lãnh đạo, đơn của quản lý bỏ qua bước quản lý
+func DefaultStages() []workflowcol.Stage {
+	return []workflowcol.Stage{
+		{
+			Key:      StageManager,
+			Name:     "Quản lý",
+			Approver: workflowcol.ApproverRule{Type: workflowcol.ApproverDirectManager},
+			Skip:     workflowcol.SkipCondition{CreatorRoles: []usercol.Role{usercol.RoleManager}},
+		},
+		{
+			Key:      StageLeader,
+			Name:     "Lãnh đạo",
+			Approver: LeaderApprover,
+		},
+	}
+}
+
+// Resolve tìm quy trình áp dụng cho người tạo đơn: quy trình của team, quy trình mặc định, hoặc quy trình có sẵn
+func Resolve(ctx context.Context, creatorId string) (*workflowcol.Workflow, error) {
+	teamIds, err := rbac.TeamIDsOf(ctx, creatorId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, teamId := range teamIds {
+		workflow, err := workflowcol.FindByTeamID(ctx, teamId)
+		if err == nil {
+			return workflow, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+	}
+
+	workflow, err := workflowcol.FindDefault(ctx)
+	if err == nil {
+		return workflow, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	return &workflowcol.Workflow{Name: "Mặc định", Stages: DefaultStages()}, nil
+}
+
+// Start gắn quy trình vào đơn mới và chuyển đơn tới bước đầu tiên không bị bỏ qua
+func Start(ctx context.Context, wc *workconfirmationcol.WorkConfirmation) error {
+	workflow, err := Resolve(ctx, wc.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	wc.WorkflowID = workflow.GetIDString()
+	wc.Stages = snapshot(workflow.Stages)
+
+	return enter(ctx, wc, 0)
+}
+
+// CurrentStage trả về bước đang chờ duyệt, nil nếu đơn không còn chờ duyệt
+func CurrentStage(wc *workconfirmationcol.WorkConfirmation) *workconfirmationcol.StageRecord {
+	if !wc.Status.IsPending() || wc.StageIndex < 0 || wc.StageIndex >= len(wc.Stages) {
+		return nil
+	}
+	return &wc.Stages[wc.StageIndex]
+}
+
+// CanAct kiểm tra user có phải người duyệt của bước hiện tại không (không xét uỷ quyền)
+func CanAct(ctx context.Context, user *usercol.User, wc *workconfirmationcol.WorkConfirmation) (bool, error) {
+	stage := CurrentStage(wc)
+	if stage == nil || user == nil {
+		return false, nil
+	}
+
+	switch stage.Approver.Type {
+	case workflowcol.ApproverDirectManager:
+		return rbac.CanApproveAsManager(ctx, user, wc.CreatedBy, wc.CreatorRole)
+	case workflowcol.ApproverTeamLeader:
+		leaders, err := teamLeadersOf(ctx, wc.CreatedBy)
+		if err != nil {
+			return false, err
+		}
+		return leaders[user.GetIDString()], nil
+	case workflowcol.ApproverRole:
+		return user.Role == stage.Approver.Role, nil
+	case workflowcol.ApproverPermission:
+		return rbac.Can(user, rbac.Permission(stage.Approver.Permission)), nil
+	case workflowcol.ApproverUser:
+		return user.GetIDString() == stage.Approver.UserID, nil
+	}
+
+	return false, nil
+}
+
+// Approve duyệt bước hiện tại và chuyển đơn sang bước tiếp theo, hoặc hoàn tất khi hết bước
+func Approve(ctx context.Context, wc *workconfirmationcol.WorkConfirmation, actorId, onBehalfOf, comment string) error {
+	stage := CurrentStage(wc)
+	if stage == nil {
+		return errors.New("WORK_CONFIRMATION_NOT_PENDING")
+	}
+
+	now := timer.Now()
+	stage.Status = workconfirmationcol.StageApproved
+	stage.ActedBy = actorId
+	stage.OnBehalfOf = onBehalfOf
+	stage.ActedAt = now
+	stage.Comment = comment
+
+	// manager/leader approvals are still exposed in their original fields for existing clients
+	approval := &workconfirmationcol.ApprovalInfo{
+		ApprovedBy: actorId,
+		OnBehalfOf: onBehalfOf,
+		ApprovedAt: now,
+		Comment:    comment,
+	}
+	switch stage.Key {
+	case StageManager:
+		wc.ManagerApproval = approval
+	case StageLeader:
+		wc.LeaderApproval = approval
+	}
+
+	return enter(ctx, wc, wc.StageIndex+1)
+}
+
+// Reject từ chối đơn ở bước hiện tại
+func Reject(wc *workconfirmationcol.WorkConfirmation, actorId, onBehalfOf, reason string) error {
+	stage := CurrentStage(wc)
+	if stage == nil {
+		return errors.New("WORK_CONFIRMATION_NOT_PENDING")
+	}
+
+	now := timer.Now()
+	stage.Status = workconfirmationcol.StageRejected
+	stage.ActedBy = actorId
+	stage.OnBehalfOf = onBehalfOf
+	stage.ActedAt = now
+	stage.Comment = reason
+
+	wc.Status = workconfirmationcol.StatusRejected
+	wc.Rejection = &workconfirmationcol.RejectionInfo{
+		RejectedBy: actorId,
+		OnBehalfOf: onBehalfOf,
+		RejectedAt: now,
+		Reason:     reason,
+	}
+
+	return nil
+}
+
+// Withdraw đánh dấu đơn đang chờ duyệt bị người tạo rút lại
+func Withdraw(wc *workconfirmationcol.WorkConfirmation, actorId, reason string) error {
+	stage := CurrentStage(wc)
+	if stage == nil {
+		return errors.New("WORK_CONFIRMATION_NOT_PENDING")
+	}
+
+	stage.Status = workconfirmationcol.StageWaiting
+	wc.Status = workconfirmationcol.StatusCancelled
+	wc.Cancellation = &workconfirmationcol.CancellationInfo{
+		CancelledBy: actorId,
+		CancelledAt: timer.Now(),
+		Reason:      reason,
+		Withdrawn:   true,
+	}
+
+	return nil
+}
+
+// Cancel huỷ đơn đã duyệt, đơn huỷ không thể gửi lại
+func Cancel(wc *workconfirmationcol.WorkConfirmation, actorId, reason string) error {
+	if wc.Status != workconfirmationcol.StatusApproved {
+		return errors.New("WORK_CONFIRMATION_NOT_APPROVED")
+	}
+
+	wc.Status = workconfirmationcol.StatusCancelled
+	wc.Cancellation = &workconfirmationcol.CancellationInfo{
+		CancelledBy: actorId,
+		CancelledAt: timer.Now(),
+		Reason:      reason,
+	}
+
+	return nil
+}
+
+// Restart đưa đơn bị từ chối vào lại quy trình từ đầu. Quy trình được xác định lại
+// vì team của người tạo hoặc quy trình của team có thể đã thay đổi
+func Restart(ctx context.Context, wc *workconfirmationcol.WorkConfirmation) error {
+	if wc.Status != workconfirmationcol.StatusRejected {
+		return errors.New("WORK_CONFIRMATION_NOT_REJECTED")
+	}
+
+	wc.ManagerApproval = nil
+	wc.LeaderApproval = nil
+	wc.Rejection = nil
+
+	return Start(ctx, wc)
+}
+
+// Save lưu trạng thái quy trình của đơn cùng mục lịch sử, lỗi nếu đơn đã được người khác xử lý từ status fromStatus
+func Save(ctx context.Context, wc *workconfirmationcol.WorkConfirmation, fromStatus workconfirmationcol.WorkConfirmationStatus, event *workconfirmationcol.Event) error {
+	event.FromStatus = fromStatus
+	event.ToStatus = wc.Status
+
+	updated, err := workconfirmationcol.UpdateWorkflowState(ctx, wc, fromStatus, event)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrStateChanged
+	}
+	return nil
+}
+
+// EnsureStages dựng lại các bước cho đơn tạo trước khi có quy trình cấu hình được, dựa theo status cũ
+func EnsureStages(wc *workconfirmationcol.WorkConfirmation) {
+	if len(wc.Stages) > 0 || !wc.Status.IsPending() {
+		return
+	}
+
+	wc.Stages = snapshot(DefaultStages())
+	for i := range wc.Stages {
+		stage := &wc.Stages[i]
+		if workconfirmationcol.PendingStatus(stage.Key) == wc.Status {
+			stage.Status = workconfirmationcol.StagePending
+			wc.StageIndex = i
+			return
+		}
+
+		stage.Status = workconfirmationcol.StageSkipped
+		if stage.Key == StageManager && wc.ManagerApproval != nil {
+			stage.Status = workconfirmationcol.StageApproved
+			stage.ActedBy = wc.ManagerApproval.ApprovedBy
+			stage.OnBehalfOf = wc.ManagerApproval.OnBehalfOf
+			stage.ActedAt = wc.ManagerApproval.ApprovedAt
+			stage.Comment = wc.ManagerApproval.Comment
+		}
+	}
+}
+
+// StatusText trả về tên trạng thái tiếng Việt của đơn
+func StatusText(wc *workconfirmationcol.WorkConfirmation) string {
+	if stage := CurrentStage(wc); stage != nil {
+		return "Chờ " + stage.Name + " xác nhận"
+	}
+
+	switch wc.Status {
+	case workconfirmationcol.StatusPendingManager:
+		return "Chờ quản lý xác nhận"
+	case workconfirmationcol.StatusPendingLeader:
+		return "Chờ lãnh đạo xác nhận"
+	case workconfirmationcol.StatusApproved:
+		return "Đã duyệt"
+	case workconfirmationcol.StatusRejected:
+		return "Đã từ chối"
+	case workconfirmationcol.StatusCancelled:
+		if wc.Cancellation != nil && wc.Cancellation.Withdrawn {
+			return "Đã rút đơn"
+		}
+		return "Đã huỷ"
+	}
+	return string(wc.Status)
+}
+
+// enter chuyển đơn tới bước đầu tiên từ index không bị bỏ qua, hoàn tất đơn khi không còn bước nào
+func enter(ctx context.Context, wc *workconfirmationcol.WorkConfirmation, index int) error {
+	for i := index; i < len(wc.Stages); i++ {
+		stage := &wc.Stages[i]
+
+		skip, err := shouldSkip(ctx, wc, stage)
+		if err != nil {
+			return err
+		}
+		if skip {
+			stage.Status = workconfirmationcol.StageSkipped
+			continue
+		}
+
+		stage.Status = workconfirmationcol.StagePending
+		wc.StageIndex = i
+		wc.Status = workconfirmationcol.PendingStatus(stage.Key)
+		return nil
+	}
+
+	wc.StageIndex = len(wc.Stages)
+	wc.Status = workconfirmationcol.StatusApproved
+	return nil
+}
+
+func shouldSkip(ctx context.Context, wc *workconfirmationcol.WorkConfirmation, stage *workconfirmationcol.StageRecord) (bool, error) {
+	creatorRole := wc.CreatorRole
+	if creatorRole == "" {
+		creatorRole = usercol.RoleEmployee
+	}
+	for _, role := range stage.Skip.CreatorRoles {
+		if role == creatorRole {
+			return true, nil
+		}
+	}
+
+	if !stage.Skip.NoApprover {
+		return false, nil
+	}
+
+	has, err := hasApprover(ctx, wc, stage.Approver)
+	if err != nil {
+		return false, err
+	}
+	return !has, nil
+}
+
+// hasApprover kiểm tra có ít nhất một người duyệt được bước theo rule
+func hasApprover(ctx context.Context, wc *workconfirmationcol.WorkConfirmation, rule workflowcol.ApproverRule) (bool, error) {
+	switch rule.Type {
+	case workflowcol.ApproverDirectManager:
+		// non-employee creators are approved by any manager, see rbac.CanApproveAsManager
+		if wc.CreatorRole != "" && wc.CreatorRole != usercol.RoleEmployee {
+			return true, nil
+		}
+		member, err := findMember(ctx, wc.CreatedBy)
+		if err != nil {
+			return false, err
+		}
+		return member != "", nil
+	case workflowcol.ApproverTeamLeader:
+		leaders, err := teamLeadersOf(ctx, wc.CreatedBy)
+		if err != nil {
+			return false, err
+		}
+		return len(leaders) > 0, nil
+	case workflowcol.ApproverRole:
+		count, err := usercol.CountByRole(ctx, rule.Role)
+		if err != nil {
+			return false, err
+		}
+		return count > 0, nil
+	case workflowcol.ApproverPermission:
+		roles := rbac.RolesWith(rbac.Permission(rule.Permission))
+		for _, role := range roles {
+			// user chưa có vai trò được xem là nhân viên, xem rbac.Permissions
+			if role == usercol.RoleEmployee {
+				roles = append(roles, "")
+				break
+			}
+		}
+		count, err := usercol.CountByRoles(ctx, roles)
+		if err != nil {
+			return false, err
+		}
+		return count > 0, nil
+	case workflowcol.ApproverUser:
+		user, err := usercol.FindWithUserID(ctx, rule.UserID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return false, nil
+			}
+			return false, err
+		}
+		return !user.IsDelete, nil
+	}
+	return false, nil
+}
+
+// teamLeadersOf trả về quản lý các team của người tạo đơn, không tính chính người tạo
+func teamLeadersOf(ctx context.Context, creatorId string) (map[string]bool, error) {
+	teams, err := rbac.TeamsOf(ctx, creatorId)
+	if err != nil {
+		return nil, err
+	}
+
+	leaders := make(map[string]bool, len(teams))
+	for _, t := range teams {
+		if t.ManagerID != "" && t.ManagerID != creatorId {
+			leaders[t.ManagerID] = true
+		}
+	}
+	return leaders, nil
+}
+
+func findMember(ctx context.Context, employeeId string) (string, error) {
+	member, err := teammembercol.FindByEmployeeID(ctx, employeeId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.ManagerID, nil
+}
+
+func snapshot(stages []workflowcol.Stage) []workconfirmationcol.StageRecord {
+	records := make([]workconfirmationcol.StageRecord, 0, len(stages))
+	for _, s := range stages {
+		records = append(records, workconfirmationcol.StageRecord{
+			Key:      s.Key,
+			Name:     s.Name,
+			Approver: s.Approver,
+			Skip:     s.Skip,
+			Status:   workconfirmationcol.StageWaiting,
+		})
+	}
+	return records
+}
@@ -0,0 +1,24 @@
+package workflows
+
+import (
+	"net/http"
+
+	"api/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetByID xem chi tiết quy trình duyệt
+func GetByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, err := findWorkflow(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(workflow))
+	}
+}
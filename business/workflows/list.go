@@ -0,0 +1,75 @@
+package workflows
+
+import (
+	"net/http"
+	"strconv"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/workflowcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// List lấy danh sách quy trình duyệt
+func List() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][workflows][list]")
+
+	return func(c *gin.Context) {
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		skip := (page - 1) * limit
+		findOptions := options.Find().
+			SetSkip(int64(skip)).
+			SetLimit(int64(limit)).
+			SetSort(primitive.D{{Key: "is_default", Value: -1}, {Key: "created_at", Value: 1}})
+
+		filter := primitive.D{}
+		if teamID := c.Query("team_id"); teamID != "" {
+			filter = append(filter, primitive.E{Key: "team_ids", Value: teamID})
+		}
+
+		workflows, count, err := workflowcol.FindWithFilter(c.Request.Context(), filter, findOptions)
+		if err != nil {
+			logger.Err(err).Msg("failed to list workflows")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list workflows"))
+			c.Abort()
+			return
+		}
+
+		if workflows == nil {
+			workflows = []*workflowcol.Workflow{}
+		}
+
+		responseData := map[string]interface{}{
+			"data":       workflows,
+			"total":      count,
+			"page":       page,
+			"limit":      limit,
+			"total_page": (count + int64(limit) - 1) / int64(limit),
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(responseData))
+	}
+}
+
+// Builtin trả về quy trình có sẵn, áp dụng khi chưa có quy trình mặc định
+func Builtin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, response.SuccessResponse(&workflowcol.Workflow{
+			Name:    "Mặc định",
+			Stages:  DefaultStages(),
+			TeamIDs: []string{},
+		}))
+	}
+}
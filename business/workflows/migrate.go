@@ -0,0 +1,31 @@
+package workflows
+
+import (
+	"context"
+
+	"api/internal/plog"
+	"api/schema/workconfirmationcol"
+	"api/schema/workflowcol"
+)
+
+// MigrateApproverRules chuyển rule bước lãnh đạo theo vai trò leader trong quy trình và đơn đã lưu
+// sang rule theo quyền duyệt cuối, để vai trò tuỳ chỉnh có quyền này cũng duyệt được
+func MigrateApproverRules(ctx context.Context) error {
+	logger := plog.NewBizLogger("[business][workflows][migrate-approver-rules]")
+
+	workflows, err := workflowcol.ReplaceApprover(ctx, LegacyLeaderApprover, LeaderApprover)
+	if err != nil {
+		return err
+	}
+
+	workConfirmations, err := workconfirmationcol.ReplaceStageApprover(ctx, LegacyLeaderApprover, LeaderApprover)
+	if err != nil {
+		return err
+	}
+
+	if workflows > 0 || workConfirmations > 0 {
+		logger.Info().Msgf("migrated leader approver rules of %d workflows and %d work confirmations", workflows, workConfirmations)
+	}
+
+	return nil
+}
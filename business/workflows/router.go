@@ -0,0 +1,22 @@
+package workflows
+
+import (
+	"api/business/rbac"
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Tất cả routes đều yêu cầu authentication
+	r.Use(middleware.AuthMiddleware())
+	r.Use(middleware.Require(rbac.WorkflowManage))
+
+	// Routes cho Lãnh đạo - cấu hình quy trình duyệt đơn theo team
+	r.GET("", List())           // GET /workflows - Danh sách quy trình
+	r.GET("builtin", Builtin()) // GET /workflows/builtin - Quy trình có sẵn
+	r.GET(":id", GetByID())     // GET /workflows/:id - Chi tiết quy trình
+	r.POST("", Create())        // POST /workflows - Tạo quy trình
+	r.PUT(":id", Update())      // PUT /workflows/:id - Sửa quy trình
+	r.DELETE(":id", Delete())   // DELETE /workflows/:id - Xoá quy trình
+}
@@ -0,0 +1,96 @@
+package workflows
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/workflowcol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateRequest struct {
+	Name        *string              `json:"name"`
+	Description *string              `json:"description"`
+	Stages      *[]workflowcol.Stage `json:"stages"`
+	TeamIDs     *[]string            `json:"team_ids"`
+	IsDefault   *bool                `json:"is_default"`
+}
+
+// Update sửa quy trình. Đơn đang duyệt giữ nguyên các bước tại thời điểm tạo đơn
+func Update() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][workflows][update]")
+
+	return func(c *gin.Context) {
+		var req UpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		workflow, err := doUpdate(c, req)
+		if err != nil {
+			logger.Err(err).Str("id", c.Param("id")).Msg("failed to update workflow")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(workflow))
+	}
+}
+
+func doUpdate(c *gin.Context, req UpdateRequest) (*workflowcol.Workflow, error) {
+	ctx := c.Request.Context()
+
+	workflow, err := findWorkflow(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("INVALID_PARAM: name must not be empty")
+		}
+		workflow.Name = name
+	}
+
+	if req.Description != nil {
+		workflow.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if req.Stages != nil {
+		if err = validateStages(ctx, *req.Stages); err != nil {
+			return nil, err
+		}
+		workflow.Stages = *req.Stages
+	}
+
+	if req.TeamIDs != nil {
+		if err = validateTeams(ctx, *req.TeamIDs); err != nil {
+			return nil, err
+		}
+		workflow.TeamIDs = *req.TeamIDs
+	}
+
+	if req.IsDefault != nil {
+		workflow.IsDefault = *req.IsDefault
+	}
+
+	if _, err = workflowcol.Update(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	if err = afterSave(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
@@ -0,0 +1,137 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"api/business/rbac"
+	"api/schema/teamcol"
+	"api/schema/usercol"
+	"api/schema/workflowcol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxStages = 10
+
+// stageKeyPattern giới hạn mã bước ở dạng snake_case, status của đơn là pending_<key>
+var stageKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+// validateStages kiểm tra danh sách bước: mã không trùng, rule người duyệt và điều kiện bỏ qua hợp lệ
+func validateStages(ctx context.Context, stages []workflowcol.Stage) error {
+	if len(stages) == 0 {
+		return errors.New("INVALID_PARAM: workflow must have at least one stage")
+	}
+	if len(stages) > maxStages {
+		return errors.New("INVALID_PARAM: workflow must not have more than 10 stages")
+	}
+
+	seen := make(map[string]bool, len(stages))
+	for i := range stages {
+		stage := &stages[i]
+		stage.Key = strings.TrimSpace(stage.Key)
+		stage.Name = strings.TrimSpace(stage.Name)
+
+		if !stageKeyPattern.MatchString(stage.Key) {
+			return errors.New("INVALID_PARAM: stage key must be 2-32 lowercase letters, digits or underscores")
+		}
+		if seen[stage.Key] {
+			return errors.New("INVALID_PARAM: duplicate stage key " + stage.Key)
+		}
+		seen[stage.Key] = true
+
+		if stage.Name == "" {
+			return errors.New("INVALID_PARAM: stage name is required")
+		}
+
+		if err := validateApprover(ctx, &stage.Approver); err != nil {
+			return err
+		}
+
+		for _, role := range stage.Skip.CreatorRoles {
+			if !rbac.RoleExists(role) {
+				return errors.New("ROLE_NOT_FOUND: " + role.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateApprover(ctx context.Context, rule *workflowcol.ApproverRule) error {
+	switch rule.Type {
+	case workflowcol.ApproverDirectManager, workflowcol.ApproverTeamLeader:
+		rule.Role = ""
+		rule.Permission = ""
+		rule.UserID = ""
+	case workflowcol.ApproverRole:
+		rule.Permission = ""
+		rule.UserID = ""
+		if !rbac.RoleExists(rule.Role) {
+			return errors.New("ROLE_NOT_FOUND: " + rule.Role.String())
+		}
+	case workflowcol.ApproverPermission:
+		rule.Role = ""
+		rule.UserID = ""
+		if !rbac.IsKnownPermission(rbac.Permission(rule.Permission)) {
+			return errors.New("INVALID_PARAM: unknown permission " + rule.Permission)
+		}
+	case workflowcol.ApproverUser:
+		rule.Role = ""
+		rule.Permission = ""
+		user, err := usercol.FindWithUserID(ctx, rule.UserID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return errors.New("ACCOUNT_NOT_FOUND")
+			}
+			return err
+		}
+		if user.IsDelete {
+			return errors.New("ACCOUNT_NOT_FOUND")
+		}
+	default:
+		return errors.New("INVALID_PARAM: approver type must be one of: direct_manager, team_leader, role, permission, user")
+	}
+	return nil
+}
+
+// validateTeams kiểm tra các team tồn tại
+func validateTeams(ctx context.Context, teamIds []string) error {
+	for _, id := range teamIds {
+		team, err := teamcol.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return errors.New("TEAM_NOT_FOUND")
+			}
+			return err
+		}
+		if team.IsDelete {
+			return errors.New("TEAM_NOT_FOUND")
+		}
+	}
+	return nil
+}
+
+// afterSave giữ ràng buộc mỗi team một quy trình và chỉ một quy trình mặc định
+func afterSave(ctx context.Context, workflow *workflowcol.Workflow) error {
+	if err := workflowcol.ReleaseTeams(ctx, workflow.GetIDString(), workflow.TeamIDs); err != nil {
+		return err
+	}
+	if workflow.IsDefault {
+		return workflowcol.ClearDefault(ctx, workflow.GetIDString())
+	}
+	return nil
+}
+
+func findWorkflow(ctx context.Context, id string) (*workflowcol.Workflow, error) {
+	workflow, err := workflowcol.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("WORKFLOW_NOT_FOUND")
+		}
+		return nil, err
+	}
+	return workflow, nil
+}
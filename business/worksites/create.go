@@ -0,0 +1,78 @@
+package worksites
+
+import (
+	"net/http"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/usercol"
+	"api/schema/worksitecol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRequest struct {
+	Name         string      `json:"name" binding:"required"`
+	Address      string      `json:"address"`
+	Area         AreaRequest `json:"area" binding:"required"`
+	RadiusMeters float64     `json:"radius_meters"` // Bắt buộc khi area là Point
+}
+
+// Create tạo địa điểm làm việc dạng Polygon hoặc Point kèm bán kính
+func Create() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worksites][create]")
+
+	return func(c *gin.Context) {
+		var req CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		site, err := doCreate(c, user, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to create work site")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(site))
+	}
+}
+
+func doCreate(c *gin.Context, user *usercol.User, req CreateRequest) (*worksitecol.WorkSite, error) {
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	area, err := validateArea(req.Area, req.RadiusMeters)
+	if err != nil {
+		return nil, err
+	}
+
+	site := &worksitecol.WorkSite{
+		Name:         name,
+		Address:      strings.TrimSpace(req.Address),
+		Area:         *area,
+		RadiusMeters: req.RadiusMeters,
+		CreatedBy:    user.GetIDString(),
+	}
+
+	if _, err = worksitecol.Create(c.Request.Context(), site); err != nil {
+		return nil, err
+	}
+
+	return site, nil
+}
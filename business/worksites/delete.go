@@ -0,0 +1,35 @@
+package worksites
+
+import (
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/worksitecol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete xoá địa điểm. Đơn đã gắn địa điểm vẫn giữ tên địa điểm tại thời điểm check-in
+func Delete() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worksites][delete]")
+
+	return func(c *gin.Context) {
+		site, err := Find(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if err = worksitecol.SoftDelete(c.Request.Context(), site.GetIDString()); err != nil {
+			logger.Err(err).Msg("failed to delete work site")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to delete work site"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
@@ -0,0 +1,24 @@
+package worksites
+
+import (
+	"net/http"
+
+	"api/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetByID xem chi tiết địa điểm
+func GetByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		site, err := Find(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(site))
+	}
+}
@@ -0,0 +1,59 @@
+package worksites
+
+import (
+	"net/http"
+	"strconv"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/worksitecol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// List lấy danh sách địa điểm
+func List() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worksites][list]")
+
+	return func(c *gin.Context) {
+		// Parse query parameters
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		skip := (page - 1) * limit
+		findOptions := options.Find().
+			SetSkip(int64(skip)).
+			SetLimit(int64(limit)).
+			SetSort(primitive.D{{Key: "name", Value: 1}})
+
+		sites, count, err := worksitecol.FindWithFilter(c.Request.Context(), primitive.D{}, findOptions)
+		if err != nil {
+			logger.Err(err).Msg("failed to list work sites")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list work sites"))
+			c.Abort()
+			return
+		}
+
+		if sites == nil {
+			sites = []*worksitecol.WorkSite{}
+		}
+
+		responseData := map[string]interface{}{
+			"data":       sites,
+			"total":      count,
+			"page":       page,
+			"limit":      limit,
+			"total_page": (count + int64(limit) - 1) / int64(limit),
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(responseData))
+	}
+}
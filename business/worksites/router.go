@@ -0,0 +1,22 @@
+package worksites
+
+import (
+	"api/business/rbac"
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Tất cả routes đều yêu cầu authentication
+	r.Use(middleware.AuthMiddleware())
+
+	// Mọi user xem được địa điểm để chọn bộ lọc và hiển thị bản đồ
+	r.GET("", List())       // GET /work-sites - Danh sách địa điểm
+	r.GET(":id", GetByID()) // GET /work-sites/:id - Chi tiết địa điểm
+
+	// Routes cho Lãnh đạo - khai báo vùng của địa điểm
+	r.POST("", middleware.Require(rbac.WorkSiteManage), Create())      // POST /work-sites - Tạo địa điểm
+	r.PUT(":id", middleware.Require(rbac.WorkSiteManage), Update())    // PUT /work-sites/:id - Sửa địa điểm
+	r.DELETE(":id", middleware.Require(rbac.WorkSiteManage), Delete()) // DELETE /work-sites/:id - Xoá địa điểm
+}
@@ -0,0 +1,123 @@
+package worksites
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"api/internal/mongodb/field"
+	"api/schema/worksitecol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MaxRadiusMeters là bán kính lớn nhất của địa điểm dạng Point, cũng là phạm vi tìm địa điểm khi check-in
+const MaxRadiusMeters = 50000
+
+const maxPolygonPoints = 1000
+
+// AreaRequest là vùng GeoJSON của địa điểm, toạ độ [longitude, latitude]
+type AreaRequest struct {
+	Type        string          `json:"type" binding:"required"` // Point | Polygon
+	Coordinates json.RawMessage `json:"coordinates" binding:"required"`
+}
+
+// validateArea kiểm tra vùng hợp lệ: Point cần bán kính trong (0, MaxRadiusMeters],
+// Polygon gồm các vòng khép kín tối thiểu 4 điểm và không dùng bán kính
+func validateArea(area AreaRequest, radius float64) (*field.Geometry, error) {
+	switch area.Type {
+	case field.Point:
+		var point []float64
+		if err := json.Unmarshal(area.Coordinates, &point); err != nil || len(point) != 2 {
+			return nil, errors.New("INVALID_PARAM: Point coordinates must be [longitude, latitude]")
+		}
+		if !validLngLat(point[0], point[1]) {
+			return nil, errors.New("INVALID_PARAM: longitude must be within [-180, 180] and latitude within [-90, 90]")
+		}
+		if radius <= 0 || radius > MaxRadiusMeters {
+			return nil, errors.New("INVALID_PARAM: radius_meters must be greater than 0 and at most 50000 for a Point area")
+		}
+		return field.NewPoint(point[0], point[1]), nil
+
+	case field.Polygon:
+		var rings [][][]float64
+		if err := json.Unmarshal(area.Coordinates, &rings); err != nil || len(rings) == 0 {
+			return nil, errors.New("INVALID_PARAM: Polygon coordinates must be a list of rings of [longitude, latitude]")
+		}
+		if radius != 0 {
+			return nil, errors.New("INVALID_PARAM: radius_meters is only allowed for a Point area")
+		}
+
+		total := 0
+		for _, ring := range rings {
+			if len(ring) < 4 {
+				return nil, errors.New("INVALID_PARAM: each Polygon ring must have at least 4 points")
+			}
+			total += len(ring)
+			for _, point := range ring {
+				if len(point) != 2 || !validLngLat(point[0], point[1]) {
+					return nil, errors.New("INVALID_PARAM: Polygon points must be [longitude, latitude] within range")
+				}
+			}
+			first, last := ring[0], ring[len(ring)-1]
+			if first[0] != last[0] || first[1] != last[1] {
+				return nil, errors.New("INVALID_PARAM: each Polygon ring must be closed (first point equals last point)")
+			}
+		}
+		if total > maxPolygonPoints {
+			return nil, errors.New("INVALID_PARAM: Polygon must not have more than 1000 points")
+		}
+		return field.NewPolygon(rings), nil
+	}
+
+	return nil, errors.New("INVALID_PARAM: area type must be Point or Polygon")
+}
+
+// validLngLat kiểm tra kinh độ, vĩ độ nằm trong phạm vi
+func validLngLat(lng, lat float64) bool {
+	return lng >= -180 && lng <= 180 && lat >= -90 && lat <= 90
+}
+
+// normalizeName bỏ khoảng trắng thừa, tên không được rỗng
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("INVALID_PARAM: name must not be empty")
+	}
+	return name, nil
+}
+
+// Find tìm địa điểm theo ID, trả về WORK_SITE_NOT_FOUND nếu không có
+func Find(ctx context.Context, id string) (*worksitecol.WorkSite, error) {
+	site, err := worksitecol.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("WORK_SITE_NOT_FOUND")
+		}
+		return nil, err
+	}
+	return site, nil
+}
+
+// Match tìm địa điểm chứa vị trí check-in: ưu tiên Polygon chứa điểm, sau đó Point có bán kính bao điểm gần nhất.
+// Trả về nil nếu vị trí nằm ngoài mọi địa điểm
+func Match(ctx context.Context, point *field.Geometry) (*worksitecol.WorkSite, error) {
+	containing, err := worksitecol.FindContaining(ctx, point)
+	if err != nil {
+		return nil, err
+	}
+	if len(containing) > 0 {
+		return containing[0], nil
+	}
+
+	covering, err := worksitecol.FindCovering(ctx, point, MaxRadiusMeters)
+	if err != nil {
+		return nil, err
+	}
+	if len(covering) > 0 {
+		return &covering[0].WorkSite, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,97 @@
+package worksites
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/worksitecol"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateRequest struct {
+	Name         *string      `json:"name"`
+	Address      *string      `json:"address"`
+	Area         *AreaRequest `json:"area"`
+	RadiusMeters *float64     `json:"radius_meters"`
+}
+
+// Update sửa địa điểm. Đơn đã tạo giữ nguyên địa điểm được gắn lúc check-in
+func Update() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worksites][update]")
+
+	return func(c *gin.Context) {
+		var req UpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		site, err := doUpdate(c, req)
+		if err != nil {
+			logger.Err(err).Str("id", c.Param("id")).Msg("failed to update work site")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(site))
+	}
+}
+
+func doUpdate(c *gin.Context, req UpdateRequest) (*worksitecol.WorkSite, error) {
+	ctx := c.Request.Context()
+
+	site, err := Find(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if site.Name, err = normalizeName(*req.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Address != nil {
+		site.Address = strings.TrimSpace(*req.Address)
+	}
+
+	// Đổi vùng hoặc bán kính thì kiểm tra lại cả hai
+	if req.Area != nil || req.RadiusMeters != nil {
+		radius := site.RadiusMeters
+		if req.RadiusMeters != nil {
+			radius = *req.RadiusMeters
+		}
+
+		var area *AreaRequest
+		if req.Area != nil {
+			area = req.Area
+		} else {
+			coordinates, err := json.Marshal(site.Area.Coordinates)
+			if err != nil {
+				return nil, err
+			}
+			area = &AreaRequest{Type: site.Area.Type, Coordinates: coordinates}
+		}
+
+		geometry, err := validateArea(*area, radius)
+		if err != nil {
+			return nil, err
+		}
+		site.Area = *geometry
+		site.RadiusMeters = radius
+	}
+
+	if _, err = worksitecol.Update(ctx, site); err != nil {
+		return nil, err
+	}
+
+	return site, nil
+}
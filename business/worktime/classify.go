@@ -0,0 +1,58 @@
+package worktime
+
+import (
+	"context"
+
+	"api/internal/timespan"
+	"api/internal/workhours"
+	"api/schema/holidaycol"
+	"api/schema/workconfirmationcol"
+	"api/schema/workpolicycol"
+)
+
+// Calendar là chính sách giờ làm việc cùng các ngày lễ dùng để phân loại giờ công
+type Calendar struct {
+	Policy   *workpolicycol.WorkPolicy
+	Holidays map[string]string // date -> tên ngày lễ
+
+	hours *workhours.Calendar
+}
+
+// LoadCalendar lấy chính sách giờ làm việc và các ngày lễ trong khoảng [from, to] (YYYY-MM-DD)
+func LoadCalendar(ctx context.Context, from, to string) (*Calendar, error) {
+	policy, err := LoadPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := holidaycol.FindInRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCalendar(policy, holidays), nil
+}
+
+// NewCalendar tạo Calendar từ chính sách và danh sách ngày lễ
+func NewCalendar(policy *workpolicycol.WorkPolicy, holidays []*holidaycol.Holiday) *Calendar {
+	cal := &Calendar{
+		Policy:   policy,
+		Holidays: map[string]string{},
+	}
+	for _, holiday := range holidays {
+		cal.Holidays[holiday.Date] = holiday.Name
+	}
+	cal.hours = workhours.New(policy.WorkDays, policy.DayStart, policy.DayEnd, cal.Holidays)
+	return cal
+}
+
+// Classify phân loại thời gian công tác của đơn theo từng ngày địa phương. Chỉ tính các ngày trong [from, to],
+// from/to rỗng là không giới hạn
+func (cal *Calendar) Classify(wc *workconfirmationcol.WorkConfirmation, from, to string) workhours.Breakdown {
+	loc, err := timespan.LoadLocation(wc.Timezone)
+	if err != nil {
+		loc, _ = timespan.LoadLocation("")
+	}
+
+	return cal.hours.Classify(wc.Days, loc, from, to)
+}
@@ -0,0 +1,54 @@
+package worktime
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"api/internal/timespan"
+	"api/schema/holidaycol"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type HolidayRequest struct {
+	Date string `json:"date" binding:"required"` // YYYY-MM-DD
+	Name string `json:"name" binding:"required"`
+}
+
+// validateHoliday kiểm tra ngày hợp lệ và chưa có ngày lễ khác trùng ngày (exceptID là ngày lễ đang sửa)
+func validateHoliday(ctx context.Context, req *HolidayRequest, exceptID string) error {
+	date, err := time.Parse(timespan.DateLayout, req.Date)
+	if err != nil {
+		return errors.New("INVALID_PARAM: date must be YYYY-MM-DD")
+	}
+	req.Date = date.Format(timespan.DateLayout)
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("INVALID_PARAM: name must not be empty")
+	}
+
+	existing, err := holidaycol.FindByDate(ctx, req.Date)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if err == nil && existing.GetIDString() != exceptID {
+		return errors.New("HOLIDAY_EXISTS")
+	}
+
+	return nil
+}
+
+// findHoliday tìm ngày lễ theo ID, trả về HOLIDAY_NOT_FOUND nếu không có
+func findHoliday(ctx context.Context, id string) (*holidaycol.Holiday, error) {
+	holiday, err := holidaycol.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("HOLIDAY_NOT_FOUND")
+		}
+		return nil, err
+	}
+	return holiday, nil
+}
@@ -0,0 +1,64 @@
+package worktime
+
+import (
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/middleware"
+	"api/schema/holidaycol"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateHoliday thêm ngày lễ vào lịch
+func CreateHoliday() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worktime][create-holiday]")
+
+	return func(c *gin.Context) {
+		var req HolidayRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		holiday, err := doCreateHoliday(c, user, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to create holiday")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(holiday))
+	}
+}
+
+func doCreateHoliday(c *gin.Context, user *usercol.User, req HolidayRequest) (*holidaycol.Holiday, error) {
+	ctx := c.Request.Context()
+
+	if err := validateHoliday(ctx, &req, ""); err != nil {
+		return nil, err
+	}
+
+	holiday := &holidaycol.Holiday{
+		Date:      req.Date,
+		Name:      req.Name,
+		CreatedBy: user.GetIDString(),
+	}
+
+	if _, err := holidaycol.Create(ctx, holiday); err != nil {
+		return nil, err
+	}
+
+	return holiday, nil
+}
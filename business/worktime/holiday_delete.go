@@ -0,0 +1,35 @@
+package worktime
+
+import (
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/holidaycol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteHoliday xoá ngày lễ khỏi lịch
+func DeleteHoliday() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worktime][delete-holiday]")
+
+	return func(c *gin.Context) {
+		holiday, err := findHoliday(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if err = holidaycol.SoftDelete(c.Request.Context(), holiday.GetIDString()); err != nil {
+			logger.Err(err).Msg("failed to delete holiday")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to delete holiday"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(nil))
+	}
+}
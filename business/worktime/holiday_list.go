@@ -0,0 +1,47 @@
+package worktime
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/schema/holidaycol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListHolidays lấy danh sách ngày lễ trong một năm (query year, mặc định năm hiện tại)
+func ListHolidays() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worktime][list-holidays]")
+
+	return func(c *gin.Context) {
+		year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(timer.Now().Year())))
+		if err != nil || year < 1970 || year > 9999 {
+			code := response.ErrorResponse("INVALID_PARAM: year must be a 4-digit year")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		holidays, err := holidaycol.FindInRange(c.Request.Context(), fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year))
+		if err != nil {
+			logger.Err(err).Msg("failed to list holidays")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list holidays"))
+			c.Abort()
+			return
+		}
+
+		if holidays == nil {
+			holidays = []*holidaycol.Holiday{}
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(map[string]interface{}{
+			"data":  holidays,
+			"total": len(holidays),
+			"year":  year,
+		}))
+	}
+}
@@ -0,0 +1,59 @@
+package worktime
+
+import (
+	"net/http"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/schema/holidaycol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateHoliday sửa ngày hoặc tên ngày lễ
+func UpdateHoliday() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worktime][update-holiday]")
+
+	return func(c *gin.Context) {
+		var req HolidayRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		holiday, err := doUpdateHoliday(c, req)
+		if err != nil {
+			logger.Err(err).Str("id", c.Param("id")).Msg("failed to update holiday")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(holiday))
+	}
+}
+
+func doUpdateHoliday(c *gin.Context, req HolidayRequest) (*holidaycol.Holiday, error) {
+	ctx := c.Request.Context()
+
+	holiday, err := findHoliday(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateHoliday(ctx, &req, holiday.GetIDString()); err != nil {
+		return nil, err
+	}
+
+	holiday.Date = req.Date
+	holiday.Name = req.Name
+
+	if _, err = holidaycol.Update(ctx, holiday); err != nil {
+		return nil, err
+	}
+
+	return holiday, nil
+}
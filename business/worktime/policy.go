@@ -0,0 +1,132 @@
+package worktime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timespan"
+	"api/middleware"
+	"api/schema/usercol"
+	"api/schema/workpolicycol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UpdatePolicyRequest struct {
+	WorkDays []int  `json:"work_days" binding:"required,dive,min=0,max=6"` // 0 = Chủ nhật ... 6 = Thứ bảy
+	DayStart string `json:"day_start" binding:"required"`                  // HH:MM
+	DayEnd   string `json:"day_end" binding:"required"`                    // HH:MM
+}
+
+// DefaultPolicy là chính sách dùng khi lãnh đạo chưa cấu hình: thứ 2 - thứ 6, 08:00 - 17:00
+func DefaultPolicy() *workpolicycol.WorkPolicy {
+	return &workpolicycol.WorkPolicy{
+		Key:      workpolicycol.DefaultKey,
+		WorkDays: []int{1, 2, 3, 4, 5},
+		DayStart: "08:00",
+		DayEnd:   "17:00",
+	}
+}
+
+// LoadPolicy lấy chính sách giờ làm việc đã cấu hình, chưa có thì dùng DefaultPolicy
+func LoadPolicy(ctx context.Context) (*workpolicycol.WorkPolicy, error) {
+	policy, err := workpolicycol.FindByKey(ctx, workpolicycol.DefaultKey)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return DefaultPolicy(), nil
+		}
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetPolicy trả về chính sách giờ làm việc đang áp dụng
+func GetPolicy() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worktime][get-policy]")
+
+	return func(c *gin.Context) {
+		policy, err := LoadPolicy(c.Request.Context())
+		if err != nil {
+			logger.Err(err).Msg("failed to load work policy")
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to load work policy"))
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(policy))
+	}
+}
+
+// UpdatePolicy cấu hình ngày làm việc trong tuần và giờ hành chính. Áp dụng cho cả báo cáo các kỳ trước
+func UpdatePolicy() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worktime][update-policy]")
+
+	return func(c *gin.Context) {
+		var req UpdatePolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			code := response.ErrorResponse("INVALID_PARAM: " + err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		policy, err := doUpdatePolicy(c, user, req)
+		if err != nil {
+			logger.Err(err).Msg("failed to update work policy")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(policy))
+	}
+}
+
+func doUpdatePolicy(c *gin.Context, user *usercol.User, req UpdatePolicyRequest) (*workpolicycol.WorkPolicy, error) {
+	dayStart, err := time.Parse(timespan.TimeLayout, req.DayStart)
+	if err != nil {
+		return nil, errors.New("INVALID_PARAM: day_start must be HH:MM")
+	}
+	dayEnd, err := time.Parse(timespan.TimeLayout, req.DayEnd)
+	if err != nil {
+		return nil, errors.New("INVALID_PARAM: day_end must be HH:MM")
+	}
+	if !dayEnd.After(dayStart) {
+		return nil, errors.New("INVALID_PARAM: day_end must be after day_start")
+	}
+
+	// Bỏ ngày trùng
+	seen := map[int]bool{}
+	workDays := make([]int, 0, len(req.WorkDays))
+	for _, day := range req.WorkDays {
+		if !seen[day] {
+			seen[day] = true
+			workDays = append(workDays, day)
+		}
+	}
+
+	policy := &workpolicycol.WorkPolicy{
+		Key:       workpolicycol.DefaultKey,
+		WorkDays:  workDays,
+		DayStart:  dayStart.Format(timespan.TimeLayout),
+		DayEnd:    dayEnd.Format(timespan.TimeLayout),
+		UpdatedBy: user.GetIDString(),
+	}
+
+	if err := workpolicycol.Upsert(c.Request.Context(), policy); err != nil {
+		return nil, err
+	}
+
+	return LoadPolicy(c.Request.Context())
+}
@@ -0,0 +1,24 @@
+package worktime
+
+import (
+	"api/business/rbac"
+	"api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	// Tất cả routes đều yêu cầu authentication
+	r.Use(middleware.AuthMiddleware())
+
+	// Mọi user xem được chính sách, lịch nghỉ lễ và giờ công trong phạm vi được xem
+	r.GET("policy", GetPolicy())      // GET /work-time/policy - Chính sách giờ làm việc
+	r.GET("holidays", ListHolidays()) // GET /work-time/holidays - Danh sách ngày lễ theo năm
+	r.GET("summary", Summary())       // GET /work-time/summary - Tổng giờ công theo user/team
+
+	// Routes cho Lãnh đạo - cấu hình giờ làm việc và lịch nghỉ lễ
+	r.PUT("policy", middleware.Require(rbac.WorkTimeManage), UpdatePolicy())           // PUT /work-time/policy - Sửa chính sách giờ làm việc
+	r.POST("holidays", middleware.Require(rbac.WorkTimeManage), CreateHoliday())       // POST /work-time/holidays - Thêm ngày lễ
+	r.PUT("holidays/:id", middleware.Require(rbac.WorkTimeManage), UpdateHoliday())    // PUT /work-time/holidays/:id - Sửa ngày lễ
+	r.DELETE("holidays/:id", middleware.Require(rbac.WorkTimeManage), DeleteHoliday()) // DELETE /work-time/holidays/:id - Xoá ngày lễ
+}
@@ -0,0 +1,348 @@
+package worktime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"api/business/rbac"
+	"api/internal/plog"
+	"api/internal/response"
+	"api/internal/timer"
+	"api/internal/timespan"
+	"api/internal/workhours"
+	"api/middleware"
+	"api/schema/teamcol"
+	"api/schema/teammembercol"
+	"api/schema/usercol"
+	"api/schema/workconfirmationcol"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxSummaryDays = 366
+
+// UserTotal là tổng giờ công của một user trong kỳ
+type UserTotal struct {
+	UserID   string                   `json:"user_id"`
+	FullName string                   `json:"full_name"`
+	Email    string                   `json:"email"`
+	Count    int                      `json:"count"` // Số đơn có thời gian trong kỳ
+	Minutes  workhours.Breakdown      `json:"minutes"`
+	Hours    workhours.HoursBreakdown `json:"hours"`
+}
+
+// TeamTotal là tổng giờ công của một team (quản lý và nhân viên) trong kỳ
+type TeamTotal struct {
+	TeamID   string                   `json:"team_id"` // Rỗng với nhóm user chưa thuộc team nào
+	TeamName string                   `json:"team_name"`
+	Count    int                      `json:"count"`
+	Minutes  workhours.Breakdown      `json:"minutes"`
+	Hours    workhours.HoursBreakdown `json:"hours"`
+	Users    []*UserTotal             `json:"users"`
+}
+
+// Summary tổng hợp giờ công theo user hoặc team trong khoảng ngày, phân loại trong giờ/tăng ca/cuối tuần/ngày lễ.
+// Query: from, to (YYYY-MM-DD, mặc định từ đầu tháng tới hôm nay), group_by (user|team), user_id, team_id,
+// status (approved - mặc định, chỉ đơn đã duyệt | all - cả đơn đang chờ duyệt).
+// Người không có quyền xem team/tất cả chỉ xem được của chính mình, quản lý xem được team của mình
+func Summary() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[business][worktime][summary]")
+
+	return func(c *gin.Context) {
+		user, ok := middleware.ContextUser(c)
+		if !ok {
+			return
+		}
+
+		res, err := doSummary(c, user)
+		if err != nil {
+			logger.Err(err).Msg("failed to summarize work time")
+			code := response.ErrorResponse(err.Error())
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusOK, response.SuccessResponse(res))
+	}
+}
+
+func doSummary(c *gin.Context, user *usercol.User) (map[string]interface{}, error) {
+	ctx := c.Request.Context()
+
+	loc, err := timespan.LoadLocation("")
+	if err != nil {
+		return nil, err
+	}
+
+	today := timer.Now().In(loc)
+	from := c.DefaultQuery("from", time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc).Format(timespan.DateLayout))
+	to := c.DefaultQuery("to", today.Format(timespan.DateLayout))
+
+	fromDate, err := time.ParseInLocation(timespan.DateLayout, from, loc)
+	if err != nil {
+		return nil, errors.New("INVALID_PARAM: from must be YYYY-MM-DD")
+	}
+	toDate, err := time.ParseInLocation(timespan.DateLayout, to, loc)
+	if err != nil {
+		return nil, errors.New("INVALID_PARAM: to must be YYYY-MM-DD")
+	}
+	if toDate.Before(fromDate) || toDate.Sub(fromDate) > maxSummaryDays*24*time.Hour {
+		return nil, errors.New("INVALID_PARAM: to must be after from and within 366 days")
+	}
+
+	groupBy := c.DefaultQuery("group_by", "user")
+	if groupBy != "user" && groupBy != "team" {
+		return nil, errors.New("INVALID_PARAM: group_by must be user or team")
+	}
+
+	status := c.DefaultQuery("status", "approved")
+	if status != "approved" && status != "all" {
+		return nil, errors.New("INVALID_PARAM: status must be approved or all")
+	}
+
+	teams, err := visibleTeams(ctx, user, c.Query("team_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := scopeUserIDs(ctx, user, c.Query("user_id"), c.Query("team_id"), teams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Đơn có thời gian giao với các ngày từ from tới hết ngày to, giờ công chỉ tính phần trong kỳ
+	list, err := workconfirmationcol.FindForWorkTime(ctx, fromDate, toDate.AddDate(0, 0, 1), userIDs, status == "approved")
+	if err != nil {
+		return nil, err
+	}
+
+	cal, err := LoadCalendar(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := map[string]*UserTotal{}
+	for _, uid := range userIDs {
+		byUser[uid] = &UserTotal{UserID: uid}
+	}
+
+	var total workhours.Breakdown
+	for _, wc := range list {
+		breakdown := cal.Classify(wc, from, to)
+		if breakdown.Total == 0 {
+			continue
+		}
+
+		row, ok := byUser[wc.CreatedBy]
+		if !ok {
+			row = &UserTotal{UserID: wc.CreatedBy}
+			byUser[wc.CreatedBy] = row
+		}
+		row.Count++
+		row.Minutes.Add(breakdown)
+		total.Add(breakdown)
+	}
+
+	ids := make([]string, 0, len(byUser))
+	for uid := range byUser {
+		ids = append(ids, uid)
+	}
+	found, err := usercol.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make(map[string]*usercol.User, len(found))
+	for _, u := range found {
+		profiles[u.GetIDString()] = u
+	}
+
+	users := make([]*UserTotal, 0, len(byUser))
+	for _, row := range byUser {
+		row.FullName, row.Email = "N/A", ""
+		if u, ok := profiles[row.UserID]; ok {
+			row.FullName, row.Email = u.FullName, u.Email
+		}
+		row.Hours = row.Minutes.Hours()
+		users = append(users, row)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].FullName < users[j].FullName
+	})
+
+	res := map[string]interface{}{
+		"from":     from,
+		"to":       to,
+		"status":   status,
+		"group_by": groupBy,
+		"policy":   cal.Policy,
+		"holidays": cal.Holidays,
+		"minutes":  total,
+		"hours":    total.Hours(),
+	}
+
+	if groupBy == "user" {
+		res["data"] = users
+		return res, nil
+	}
+
+	teamTotals, err := groupByTeam(ctx, users, teams, c.Query("team_id") == "" && c.Query("user_id") == "" && rbac.Can(user, rbac.WorkConfirmationViewAll))
+	if err != nil {
+		return nil, err
+	}
+	res["data"] = teamTotals
+	return res, nil
+}
+
+// visibleTeams trả về các team user được xem giờ công: tất cả team khi có quyền xem tất cả,
+// team mình quản lý khi có quyền xem team. teamID khác rỗng thì chỉ trả về team đó nếu được xem
+func visibleTeams(ctx context.Context, user *usercol.User, teamID string) ([]*teamcol.Team, error) {
+	var teams []*teamcol.Team
+	var err error
+
+	switch {
+	case rbac.Can(user, rbac.WorkConfirmationViewAll):
+		teams, _, err = teamcol.FindWithFilter(ctx, primitive.D{}, nil)
+	case rbac.Can(user, rbac.WorkConfirmationViewTeam):
+		teams, _, err = teamcol.FindByManagerID(ctx, user.GetIDString(), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if teamID == "" {
+		return teams, nil
+	}
+
+	for _, team := range teams {
+		if team.GetIDString() == teamID {
+			return []*teamcol.Team{team}, nil
+		}
+	}
+
+	if _, err := teamcol.FindByID(ctx, teamID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("TEAM_NOT_FOUND")
+		}
+		return nil, err
+	}
+	return nil, errors.New("PERMISSION_DENIED")
+}
+
+// scopeUserIDs xác định các user được tổng hợp, nil là tất cả user
+func scopeUserIDs(ctx context.Context, user *usercol.User, userID, teamID string, teams []*teamcol.Team) ([]string, error) {
+	if userID != "" {
+		allowed, err := rbac.CanViewWorkOf(ctx, user, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("PERMISSION_DENIED")
+		}
+		return []string{userID}, nil
+	}
+
+	if teamID == "" && rbac.Can(user, rbac.WorkConfirmationViewAll) {
+		return nil, nil
+	}
+
+	seen := map[string]bool{}
+	userIDs := make([]string, 0)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	if teamID == "" {
+		add(user.GetIDString())
+	}
+	for _, team := range teams {
+		members, err := teamMemberIDs(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range members {
+			add(id)
+		}
+	}
+
+	return userIDs, nil
+}
+
+// teamMemberIDs là quản lý và các nhân viên của team
+func teamMemberIDs(ctx context.Context, team *teamcol.Team) ([]string, error) {
+	if team.ManagerID == "" {
+		return []string{}, nil
+	}
+
+	members, _, err := teammembercol.FindByManagerID(ctx, team.ManagerID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{team.ManagerID}
+	for _, member := range members {
+		ids = append(ids, member.EmployeeID)
+	}
+	return ids, nil
+}
+
+// groupByTeam cộng giờ công của user theo team. withoutTeam thêm nhóm các user chưa thuộc team nào
+func groupByTeam(ctx context.Context, users []*UserTotal, teams []*teamcol.Team, withoutTeam bool) ([]*TeamTotal, error) {
+	byID := map[string]*UserTotal{}
+	for _, row := range users {
+		byID[row.UserID] = row
+	}
+
+	inTeam := map[string]bool{}
+	result := make([]*TeamTotal, 0, len(teams)+1)
+	for _, team := range teams {
+		members, err := teamMemberIDs(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+
+		teamTotal := &TeamTotal{
+			TeamID:   team.GetIDString(),
+			TeamName: team.Name,
+			Users:    make([]*UserTotal, 0, len(members)),
+		}
+		for _, id := range members {
+			inTeam[id] = true
+			row, ok := byID[id]
+			if !ok {
+				continue
+			}
+			teamTotal.Count += row.Count
+			teamTotal.Minutes.Add(row.Minutes)
+			teamTotal.Users = append(teamTotal.Users, row)
+		}
+		teamTotal.Hours = teamTotal.Minutes.Hours()
+		result = append(result, teamTotal)
+	}
+
+	if withoutTeam {
+		others := &TeamTotal{TeamName: "Chưa thuộc team", Users: make([]*UserTotal, 0)}
+		for _, row := range users {
+			if inTeam[row.UserID] {
+				continue
+			}
+			others.Count += row.Count
+			others.Minutes.Add(row.Minutes)
+			others.Users = append(others.Users, row)
+		}
+		if len(others.Users) > 0 {
+			others.Hours = others.Minutes.Hours()
+			result = append(result, others)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,312 @@
+// Package exif reads the few EXIF fields used to verify photo evidence: capture time, GPS position and device.
+// It understands JPEG (APP1 segment) and HEIC/HEIF, where the Exif item is located by its "Exif\0\0" header.
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// DateTimeLayout is the EXIF date/time format, without timezone
+const DateTimeLayout = "2006:01:02 15:04:05"
+
+// maxSize limits how much of a photo is read looking for EXIF
+const maxSize = 32 << 20
+
+// ErrNotFound is returned when the image carries no EXIF data
+var ErrNotFound = errors.New("exif: no EXIF data")
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	tiffLE     = []byte("II*\x00")
+	tiffBE     = []byte("MM\x00*")
+)
+
+// TIFF tags
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+// TIFF field types
+const (
+	typeASCII    = 2
+	typeShort    = 3
+	typeLong     = 4
+	typeRational = 5
+)
+
+// Metadata is the EXIF data of a photo
+type Metadata struct {
+	Make       string
+	Model      string
+	DateTime   string // DateTimeOriginal, or DateTime when missing, in DateTimeLayout
+	OffsetTime string // OffsetTimeOriginal such as "+07:00", empty when the camera did not record it
+
+	Orientation int // 1-8 as in the TIFF specification, 0 when missing
+
+	HasGPS    bool
+	Latitude  float64
+	Longitude float64
+}
+
+// TakenAt returns the capture time. Without an offset in the EXIF data the time is read in loc
+func (m *Metadata) TakenAt(loc *time.Location) (time.Time, bool) {
+	if m.DateTime == "" {
+		return time.Time{}, false
+	}
+
+	if m.OffsetTime != "" {
+		if t, err := time.Parse(DateTimeLayout+"-07:00", m.DateTime+m.OffsetTime); err == nil {
+			return t, true
+		}
+	}
+
+	t, err := time.ParseInLocation(DateTimeLayout, m.DateTime, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Decode reads EXIF data from a JPEG or HEIC image
+func Decode(r io.Reader) (*Metadata, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize))
+	if err != nil {
+		return nil, err
+	}
+
+	tiff, err := findTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTIFF(tiff)
+}
+
+// findTIFF returns the TIFF block holding the EXIF data
+func findTIFF(data []byte) ([]byte, error) {
+	if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xD8 {
+		return jpegTIFF(data)
+	}
+
+	// HEIC/HEIF: the Exif item is stored as a 4-byte offset, "Exif\0\0" and the TIFF block
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		for i := 0; ; {
+			j := bytes.Index(data[i:], exifHeader)
+			if j < 0 {
+				break
+			}
+			start := i + j + len(exifHeader)
+			if isTIFF(data[start:]) {
+				return data[start:], nil
+			}
+			i = start
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// jpegTIFF walks the JPEG markers until the APP1 Exif segment
+func jpegTIFF(data []byte) ([]byte, error) {
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, ErrNotFound
+		}
+		marker := data[pos+1]
+		// Start of scan: image data follows, no more metadata
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if size < 2 || pos+2+size > len(data) {
+			return nil, ErrNotFound
+		}
+
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) && isTIFF(segment[len(exifHeader):]) {
+			return segment[len(exifHeader):], nil
+		}
+		pos += 2 + size
+	}
+
+	return nil, ErrNotFound
+}
+
+func isTIFF(data []byte) bool {
+	return bytes.HasPrefix(data, tiffLE) || bytes.HasPrefix(data, tiffBE)
+}
+
+// entry is a TIFF IFD entry
+type entry struct {
+	typ   uint16
+	count uint32
+	value []byte // inline value or the data at its offset
+}
+
+type reader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// parseTIFF reads IFD0 and the Exif and GPS sub-IFDs
+func parseTIFF(data []byte) (*Metadata, error) {
+	if len(data) < 8 {
+		return nil, ErrNotFound
+	}
+
+	r := &reader{data: data, order: binary.LittleEndian}
+	if bytes.HasPrefix(data, tiffBE) {
+		r.order = binary.BigEndian
+	}
+
+	ifd0, err := r.ifd(r.order.Uint32(data[4:]))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Metadata{
+		Make:     r.ascii(ifd0[tagMake]),
+		Model:    r.ascii(ifd0[tagModel]),
+		DateTime: r.ascii(ifd0[tagDateTime]),
+	}
+	if orientation, ok := r.long(ifd0[tagOrientation]); ok && orientation >= 1 && orientation <= 8 {
+		m.Orientation = int(orientation)
+	}
+
+	if offset, ok := r.long(ifd0[tagExifIFD]); ok {
+		if exifIFD, err := r.ifd(offset); err == nil {
+			if original := r.ascii(exifIFD[tagDateTimeOriginal]); original != "" {
+				m.DateTime = original
+			}
+			m.OffsetTime = r.ascii(exifIFD[tagOffsetOriginal])
+		}
+	}
+
+	if offset, ok := r.long(ifd0[tagGPSIFD]); ok {
+		if gpsIFD, err := r.ifd(offset); err == nil {
+			lat, latOK := r.degrees(gpsIFD[tagGPSLatitude])
+			lng, lngOK := r.degrees(gpsIFD[tagGPSLongitude])
+			if latOK && lngOK {
+				if r.ascii(gpsIFD[tagGPSLatitudeRef]) == "S" {
+					lat = -lat
+				}
+				if r.ascii(gpsIFD[tagGPSLongitudeRef]) == "W" {
+					lng = -lng
+				}
+				m.HasGPS = true
+				m.Latitude, m.Longitude = lat, lng
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// ifd reads the entries of the IFD at offset, keyed by tag
+func (r *reader) ifd(offset uint32) (map[uint16]*entry, error) {
+	start := int(offset)
+	if start < 8 || start+2 > len(r.data) {
+		return nil, errors.New("exif: invalid IFD offset")
+	}
+
+	n := int(r.order.Uint16(r.data[start:]))
+	if start+2+n*12 > len(r.data) {
+		return nil, errors.New("exif: truncated IFD")
+	}
+
+	entries := make(map[uint16]*entry, n)
+	for i := 0; i < n; i++ {
+		raw := r.data[start+2+i*12 : start+2+(i+1)*12]
+		e := &entry{
+			typ:   r.order.Uint16(raw[2:]),
+			count: r.order.Uint32(raw[4:]),
+		}
+
+		size := int(e.count) * typeSize(e.typ)
+		if size <= 0 || size > len(r.data) {
+			continue
+		}
+		if size <= 4 {
+			e.value = raw[8 : 8+size]
+		} else {
+			valueOffset := int(r.order.Uint32(raw[8:]))
+			if valueOffset < 0 || valueOffset+size > len(r.data) {
+				continue
+			}
+			e.value = r.data[valueOffset : valueOffset+size]
+		}
+		entries[r.order.Uint16(raw)] = e
+	}
+
+	return entries, nil
+}
+
+func typeSize(typ uint16) int {
+	switch typ {
+	case typeASCII:
+		return 1
+	case typeShort:
+		return 2
+	case typeLong:
+		return 4
+	case typeRational:
+		return 8
+	}
+	return 0
+}
+
+func (r *reader) ascii(e *entry) string {
+	if e == nil || e.typ != typeASCII {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (r *reader) long(e *entry) (uint32, bool) {
+	if e == nil || e.count != 1 {
+		return 0, false
+	}
+	switch e.typ {
+	case typeLong:
+		return r.order.Uint32(e.value), true
+	case typeShort:
+		return uint32(r.order.Uint16(e.value)), true
+	}
+	return 0, false
+}
+
+// degrees converts a GPS degrees/minutes/seconds triple of rationals to decimal degrees
+func (r *reader) degrees(e *entry) (float64, bool) {
+	if e == nil || e.typ != typeRational || e.count != 3 {
+		return 0, false
+	}
+
+	var parts [3]float64
+	for i := range parts {
+		num := r.order.Uint32(e.value[i*8:])
+		den := r.order.Uint32(e.value[i*8+4:])
+		if den == 0 {
+			return 0, false
+		}
+		parts[i] = float64(num) / float64(den)
+	}
+
+	return parts[0] + parts[1]/60 + parts[2]/3600, true
+}
@@ -0,0 +1,184 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// tiffBuilder writes a little-endian TIFF block with IFDs laid out one after another
+type tiffBuilder struct {
+	buf bytes.Buffer
+}
+
+type testEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func asciiEntry(tag uint16, s string) testEntry {
+	return testEntry{tag: tag, typ: typeASCII, count: uint32(len(s) + 1), value: append([]byte(s), 0)}
+}
+
+func shortEntry(tag uint16, v uint16) testEntry {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return testEntry{tag: tag, typ: typeShort, count: 1, value: b}
+}
+
+func longEntry(tag uint16, v uint32) testEntry {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return testEntry{tag: tag, typ: typeLong, count: 1, value: b}
+}
+
+func degreesEntry(tag uint16, d, m, s uint32) testEntry {
+	b := make([]byte, 24)
+	for i, v := range []uint32{d, m, s} {
+		binary.LittleEndian.PutUint32(b[i*8:], v)
+		binary.LittleEndian.PutUint32(b[i*8+4:], 1)
+	}
+	return testEntry{tag: tag, typ: typeRational, count: 3, value: b}
+}
+
+// ifdSize is the size of an IFD with its out-of-line values
+func ifdSize(entries []testEntry) int {
+	size := 2 + len(entries)*12 + 4
+	for _, e := range entries {
+		if len(e.value) > 4 {
+			size += len(e.value)
+		}
+	}
+	return size
+}
+
+// writeIFD appends an IFD at the current end of the buffer
+func (b *tiffBuilder) writeIFD(entries []testEntry) {
+	start := b.buf.Len()
+	extra := start + 2 + len(entries)*12 + 4
+	var values bytes.Buffer
+
+	binary.Write(&b.buf, binary.LittleEndian, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(&b.buf, binary.LittleEndian, e.tag)
+		binary.Write(&b.buf, binary.LittleEndian, e.typ)
+		binary.Write(&b.buf, binary.LittleEndian, e.count)
+		if len(e.value) <= 4 {
+			v := make([]byte, 4)
+			copy(v, e.value)
+			b.buf.Write(v)
+			continue
+		}
+		binary.Write(&b.buf, binary.LittleEndian, uint32(extra+values.Len()))
+		values.Write(e.value)
+	}
+	binary.Write(&b.buf, binary.LittleEndian, uint32(0))
+	b.buf.Write(values.Bytes())
+}
+
+func sampleTIFF() []byte {
+	exifEntries := []testEntry{
+		asciiEntry(tagDateTimeOriginal, "2026:03:10 09:15:00"),
+		asciiEntry(tagOffsetOriginal, "+07:00"),
+	}
+	gpsEntries := []testEntry{
+		asciiEntry(tagGPSLatitudeRef, "N"),
+		degreesEntry(tagGPSLatitude, 10, 46, 30),
+		asciiEntry(tagGPSLongitudeRef, "E"),
+		degreesEntry(tagGPSLongitude, 106, 42, 0),
+	}
+	ifd0 := []testEntry{
+		asciiEntry(tagMake, "Apple"),
+		asciiEntry(tagModel, "iPhone 15"),
+		shortEntry(tagOrientation, 6),
+		longEntry(tagExifIFD, 0),
+		longEntry(tagGPSIFD, 0),
+	}
+
+	exifOffset := 8 + ifdSize(ifd0)
+	gpsOffset := exifOffset + ifdSize(exifEntries)
+	ifd0[3] = longEntry(tagExifIFD, uint32(exifOffset))
+	ifd0[4] = longEntry(tagGPSIFD, uint32(gpsOffset))
+
+	b := &tiffBuilder{}
+	b.buf.Write(tiffLE)
+	binary.Write(&b.buf, binary.LittleEndian, uint32(8))
+	b.writeIFD(ifd0)
+	b.writeIFD(exifEntries)
+	b.writeIFD(gpsEntries)
+	return b.buf.Bytes()
+}
+
+func sampleJPEG() []byte {
+	segment := append(append([]byte{}, exifHeader...), sampleTIFF()...)
+
+	var buf bytes.Buffer
+	buf.Write([]byte{0xFF, 0xD8})
+	// APP0 JFIF segment before the EXIF segment
+	buf.Write([]byte{0xFF, 0xE0, 0x00, 0x07, 'J', 'F', 'I', 'F', 0x00})
+	buf.Write([]byte{0xFF, 0xE1})
+	binary.Write(&buf, binary.BigEndian, uint16(len(segment)+2))
+	buf.Write(segment)
+	buf.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+	return buf.Bytes()
+}
+
+func TestDecodeJPEG(t *testing.T) {
+	m, err := Decode(bytes.NewReader(sampleJPEG()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m.Make != "Apple" || m.Model != "iPhone 15" {
+		t.Fatalf("got device %q %q, want Apple iPhone 15", m.Make, m.Model)
+	}
+	if m.Orientation != 6 {
+		t.Fatalf("got orientation %d, want 6", m.Orientation)
+	}
+
+	takenAt, ok := m.TakenAt(time.UTC)
+	if !ok {
+		t.Fatal("expected a capture time")
+	}
+	if want := time.Date(2026, 3, 10, 2, 15, 0, 0, time.UTC); !takenAt.Equal(want) {
+		t.Fatalf("got capture time %v, want %v", takenAt, want)
+	}
+
+	if !m.HasGPS || math.Abs(m.Latitude-10.775) > 1e-9 || math.Abs(m.Longitude-106.7) > 1e-9 {
+		t.Fatalf("got GPS %v %v,%v, want 10.775,106.7", m.HasGPS, m.Latitude, m.Longitude)
+	}
+}
+
+func TestDecodeHEIC(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x00, 0x00, 0x00, 0x18})
+	buf.WriteString("ftypheic")
+	buf.Write(make([]byte, 12))
+	// Exif item: offset to the TIFF header, then "Exif\0\0" and the TIFF block
+	buf.Write([]byte{0x00, 0x00, 0x00, 0x06})
+	buf.Write(exifHeader)
+	buf.Write(sampleTIFF())
+
+	m, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Model != "iPhone 15" || !m.HasGPS {
+		t.Fatalf("got %+v, want model and GPS", m)
+	}
+}
+
+func TestDecodeWithoutExif(t *testing.T) {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9}
+	if _, err := Decode(bytes.NewReader(jpeg)); err != ErrNotFound {
+		t.Fatalf("got error %v, want ErrNotFound", err)
+	}
+
+	if _, err := Decode(bytes.NewReader([]byte("GIF89a"))); err != ErrNotFound {
+		t.Fatalf("got error %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,118 @@
+// Package imagehash computes a perceptual difference hash (dHash) of an image, so that
+// re-encoded, resized or slightly edited copies of the same photo get hashes a few bits apart.
+package imagehash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"math/bits"
+	"strconv"
+)
+
+// BandCount is the number of 16-bit bands a hash is split into. Two hashes at most BandCount-1 bits
+// apart share at least one band, so bands can be indexed to find near duplicates
+const BandCount = 4
+
+// maxPixels guards against decompression bombs
+const maxPixels = 64_000_000
+
+// ErrTooLarge is returned when the image has too many pixels to decode
+var ErrTooLarge = errors.New("imagehash: image too large")
+
+// FromBytes decodes a JPEG, PNG or GIF image and returns its dHash
+func FromBytes(data []byte) (uint64, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	if config.Width*config.Height > maxPixels {
+		return 0, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	return DHash(img), nil
+}
+
+// DHash shrinks the image to 9x8 grey cells and sets one bit per cell brighter than its right neighbour
+func DHash(img image.Image) uint64 {
+	const width, height = 9, 8
+
+	var cells [height][width]float64
+	bounds := img.Bounds()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cells[y][x] = cellLuma(img, image.Rect(
+				bounds.Min.X+x*bounds.Dx()/width,
+				bounds.Min.Y+y*bounds.Dy()/height,
+				bounds.Min.X+(x+1)*bounds.Dx()/width,
+				bounds.Min.Y+(y+1)*bounds.Dy()/height,
+			))
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			hash <<= 1
+			if cells[y][x] > cells[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// cellLuma averages the luma of up to 8x8 evenly spaced samples in the cell
+func cellLuma(img image.Image, cell image.Rectangle) float64 {
+	if cell.Empty() {
+		cell.Max = cell.Min.Add(image.Pt(1, 1))
+	}
+
+	const samples = 8
+	stepX := max(cell.Dx()/samples, 1)
+	stepY := max(cell.Dy()/samples, 1)
+
+	var sum float64
+	var n int
+	for y := cell.Min.Y; y < cell.Max.Y; y += stepY {
+		for x := cell.Min.X; x < cell.Max.X; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+// Distance is the number of differing bits between two hashes
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Format returns the hash as 16 hex digits
+func Format(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// Parse reads a hash written by Format
+func Parse(s string) (uint64, error) {
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// Bands splits the hash into BandCount values of the form "<index>:<4 hex digits>"
+func Bands(hash uint64) []string {
+	bands := make([]string, BandCount)
+	for i := range bands {
+		bands[i] = fmt.Sprintf("%d:%04x", i, (hash>>(16*(BandCount-1-i)))&0xFFFF)
+	}
+	return bands
+}
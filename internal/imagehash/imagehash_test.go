@@ -0,0 +1,80 @@
+package imagehash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gradient draws a diagonal gradient with a dark square, scaled to the given size
+func gradient(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x*255/w + y*128/h) / 2)
+			if x > w/4 && x < w/2 && y > h/4 && y < h/2 {
+				v = 20
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestSimilarImagesHashClose(t *testing.T) {
+	original := gradient(640, 480)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, gradient(320, 240), &jpeg.Options{Quality: 60}); err != nil {
+		t.Fatal(err)
+	}
+	resized, err := FromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := Distance(DHash(original), resized); d > 3 {
+		t.Fatalf("got distance %d between resized copies, want at most 3", d)
+	}
+}
+
+func TestDifferentImagesHashFar(t *testing.T) {
+	flipped := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	src := gradient(640, 480)
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			flipped.Set(639-x, y, src.At(x, y))
+		}
+	}
+
+	if d := Distance(DHash(src), DHash(flipped)); d < 10 {
+		t.Fatalf("got distance %d between different images, want at least 10", d)
+	}
+}
+
+func TestFormatParseBands(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gradient(64, 64)); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := FromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := Parse(Format(hash))
+	if err != nil || parsed != hash {
+		t.Fatalf("got %x, %v after round trip, want %x", parsed, err, hash)
+	}
+
+	bands := Bands(0x0123456789abcdef)
+	want := []string{"0:0123", "1:4567", "2:89ab", "3:cdef"}
+	for i := range want {
+		if bands[i] != want[i] {
+			t.Fatalf("got bands %v, want %v", bands, want)
+		}
+	}
+}
@@ -10,17 +10,19 @@ import (
 )
 
 type CustomClaims struct {
-	UserId   string `json:"user_id"`
-	Metadata string `json:"metadata"`
+	UserId    string `json:"user_id"`
+	SessionId string `json:"sid"`
+	Metadata  string `json:"metadata"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWTToken(key_sign string, UserId string, metadata, issuer string, expired int) (string, error) {
+func GenerateJWTToken(key_sign string, UserId string, sessionId string, metadata, issuer string, expired int) (string, error) {
 	signingKey := []byte(key_sign)
 
 	// Create the claims
 	claims := CustomClaims{
 		UserId,
+		sessionId,
 		metadata,
 		jwt.RegisteredClaims{
 			// A usual scenario is to set the expiration time relative to the current time
@@ -23,6 +23,9 @@ const (
 	SessionTypeChangeEmail       string = "change_email"
 	SessionTypeChangeEmailVerify string = "change_email_verify"
 	SessionTypeChangePhoneVerify string = "change_phone_verify"
+	SessionTypeRefresh           string = "refresh"
+	SessionTypeMFAPending        string = "mfa_pending"
+	SessionTypeInvite            string = "invite"
 )
 
 // GenerateSessionToken generates a session token with the given parameters
@@ -55,6 +58,48 @@ func GenerateSessionToken(keySign string, sessionId, sessionType, issuer string,
 	return res, nil
 }
 
+// GenerateSessionTokenWithID generates a session token carrying tokenId as the jti claim,
+// so a single token can be tracked and consumed server-side
+func GenerateSessionTokenWithID(keySign string, sessionId, tokenId, sessionType, issuer string, expired int) (string, error) {
+	if keySign == "" {
+		return "", errors.New("key is empty")
+	}
+
+	claims := SessionToken{
+		sessionId,
+		sessionType,
+		jwt.RegisteredClaims{
+			ID:        tokenId,
+			ExpiresAt: jwt.NewNumericDate(timer.Now().Add(time.Duration(expired) * time.Second)),
+			Issuer:    issuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+	return token.SignedString([]byte(keySign))
+}
+
+// GenerateRefreshToken generates a refresh token for the given user session.
+// tokenId is stored as the jti claim so each rotation produces a distinct token
+func GenerateRefreshToken(keySign string, sessionId, tokenId, issuer string, expired int) (string, error) {
+	if keySign == "" {
+		return "", errors.New("key is empty")
+	}
+
+	claims := SessionToken{
+		sessionId,
+		SessionTypeRefresh,
+		jwt.RegisteredClaims{
+			ID:        tokenId,
+			ExpiresAt: jwt.NewNumericDate(timer.Now().Add(time.Duration(expired) * time.Second)),
+			Issuer:    issuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+	return token.SignedString([]byte(keySign))
+}
+
 func VerifySessionToken(key, tokenString string) (*SessionToken, error) {
 	if key == "" {
 
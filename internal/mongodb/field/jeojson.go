@@ -10,3 +10,19 @@ const (
 	MultiPolygon       = "MultiPolygon"
 	GeometryCollection = "GeometryCollection"
 )
+
+// Geometry is a GeoJSON geometry object. Coordinates are [longitude, latitude] pairs nested per Type.
+type Geometry struct {
+	Type        string      `json:"type" bson:"type"`
+	Coordinates interface{} `json:"coordinates" bson:"coordinates"`
+}
+
+// NewPoint returns a GeoJSON Point at the given longitude and latitude.
+func NewPoint(lng, lat float64) *Geometry {
+	return &Geometry{Type: Point, Coordinates: []float64{lng, lat}}
+}
+
+// NewPolygon returns a GeoJSON Polygon from its rings, the first ring is the exterior.
+func NewPolygon(rings [][][]float64) *Geometry {
+	return &Geometry{Type: Polygon, Coordinates: rings}
+}
@@ -67,6 +67,21 @@ func SetObjectWithPipe(ctx context.Context, pipe goredislib.Pipeliner, key strin
 	return err
 }
 
+// SetObjectNX Set struct to redis only when the key does not exist, returns false if it already exists
+// EX used: created, err := util.SetObjectNX(ctx, "key", userModel, 86400)
+func SetObjectNX(ctx context.Context, key string, value interface{}, expirationSecond int) (bool, error) {
+	jsonStr, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return client.SetNX(ctx, key, jsonStr, time.Duration(expirationSecond)*time.Second).Result()
+}
+
+// DeleteObject Delete key from redis
+func DeleteObject(ctx context.Context, key string) error {
+	return client.Del(ctx, key).Err()
+}
+
 func IncrObject(ctx context.Context, key string) error {
 	_, err := client.Incr(ctx, key).Result()
 	if err != nil {
@@ -96,6 +111,27 @@ func GetObject(ctx context.Context, key string, refObj interface{}) (bool, error
 	return true, nil
 }
 
+// PopObject Get struct from redis and delete the key atomically (redis >= 6.2)
+// EX used: existsFlag, err := util.PopObject(ctx, "key", &userModel)
+func PopObject(ctx context.Context, key string, refObj interface{}) (bool, error) {
+	bytes, err := client.GetDel(ctx, key).Bytes()
+
+	if err != nil {
+		if err == goredislib.Nil {
+			// Key not exists
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	err = json.Unmarshal(bytes, &refObj)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // GetObjectWithPipe Get struct from redis
 // EX used: existsFlag, err := util.GetObject(ctx, "key", &userModel)
 func GetObjectWithPipe(ctx context.Context, pipe goredislib.Pipeliner, key string, refObj interface{}) (bool, error) {
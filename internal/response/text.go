@@ -4,14 +4,65 @@ import "strings"
 
 var errorTextMap = map[string]int{
 	// Authentication errors
-	"TOKEN_EXPIRED": 401,
+	"TOKEN_EXPIRED":         401,
+	"SESSION_REVOKED":       401,
+	"REFRESH_TOKEN_INVALID": 401,
+	"REFRESH_TOKEN_EXPIRED": 401,
+	"REFRESH_TOKEN_REUSED":  401,
+	"OAUTH_CODE_INVALID":    401,
+	"MFA_TOKEN_INVALID":     401,
+	"MFA_CODE_INVALID":      401,
 	// Validation errors
-	"INVALID_PARAM": 400,
+	"INVALID_PARAM":                  400,
+	"WORK_CONFIRMATION_NOT_PENDING":  400,
+	"WORK_CONFIRMATION_NOT_APPROVED": 400,
+	"WORK_CONFIRMATION_NOT_REJECTED": 400,
+	"MENTION_NOT_ALLOWED":            400,
+	// Permission errors
+	"REGISTRATION_CLOSED":         403,
+	"MFA_REQUIRED_FOR_ROLE":       403,
+	"PERMISSION_DENIED":           403,
+	"SYSTEM_ROLE_READONLY":        403,
+	"COMMENT_EDIT_WINDOW_EXPIRED": 403,
+	// Not found errors
+	"ACCOUNT_NOT_FOUND":           404,
+	"OAUTH_PROVIDER_NOT_FOUND":    404,
+	"TEAM_NOT_FOUND":              404,
+	"INVITATION_NOT_FOUND":        404,
+	"ROLE_NOT_FOUND":              404,
+	"DELEGATION_NOT_FOUND":        404,
+	"WORKFLOW_NOT_FOUND":          404,
+	"COMMENT_NOT_FOUND":           404,
+	"WORK_CONFIRMATION_NOT_FOUND": 404,
+	"HOLIDAY_NOT_FOUND":           404,
+	"WORK_SITE_NOT_FOUND":         404,
 	// Account status errors
 	"ACCOUNT_NOT_VERIFY_PHONE": 404,
 	// Conflict errors - user already exists
-	"ACCOUNT_EXIST": 409,
-	"PHONE_EXIST":   409,
+	"ACCOUNT_EXIST":           409,
+	"PHONE_EXIST":             409,
+	"ACCOUNT_LINK_REQUIRED":   409,
+	"IDENTITY_ALREADY_LINKED": 409,
+	"LAST_LOGIN_METHOD":       409,
+	"ROLE_EXIST":              409,
+	"ROLE_IN_USE":             409,
+	"HOLIDAY_EXISTS":          409,
+	// Conflict errors - work confirmation changed by someone else
+	"WORK_CONFIRMATION_STATE_CHANGED": 409,
+	"WORK_CONFIRMATION_OVERLAP":       409,
+	"WORK_CONFIRMATION_BUSY":          409,
+	"PHOTO_DUPLICATE":                 409,
+	"IDEMPOTENCY_KEY_IN_PROGRESS":     409,
+	// Idempotency-Key reused with a different request
+	"IDEMPOTENCY_KEY_REUSED": 422,
+	// Upload errors
+	"PHOTO_TOO_LARGE":        413,
+	"REQUEST_TOO_LARGE":      413,
+	"PHOTO_TYPE_NOT_ALLOWED": 415,
+	// Rate limit errors
+	"OTP_TOO_MANY_ATTEMPTS":      429,
+	"MFA_LOCKED":                 429,
+	"INVITATION_RESEND_TOO_SOON": 429,
 	// Server errors
 	"SERVER_ERROR": 500,
 	// Service unavailable errors
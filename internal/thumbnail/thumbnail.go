@@ -0,0 +1,208 @@
+// Package thumbnail generates downscaled JPEG or WebP variants of uploaded photos.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	_ "image/png" // register PNG decoder
+	"strings"
+
+	"api/internal/exif"
+
+	"github.com/gen2brain/webp" // also registers the WebP decoder
+)
+
+// Variant sizes served by /images/*path?size=
+const (
+	Thumb  = "thumb"
+	Medium = "medium"
+)
+
+// Variant formats, WebP is served to clients that accept it
+const (
+	JPEG = "jpeg"
+	WebP = "webp"
+)
+
+const (
+	quality = 80
+	// maxPixels guards against decompression bombs
+	maxPixels = 64_000_000
+	// maxSamples limits the source pixels averaged per output pixel along each axis
+	maxSamples = 4
+)
+
+// variantPrefix is the key prefix of stored variants
+const variantPrefix = "variants/"
+
+// maxSides is the longest side in pixels of each variant
+var maxSides = map[string]int{
+	Thumb:  320,
+	Medium: 1280,
+}
+
+// ErrUnknownSize is returned for a size other than Thumb or Medium
+var ErrUnknownSize = errors.New("thumbnail: unknown size")
+
+// ErrTooLarge is returned when the image has too many pixels to decode
+var ErrTooLarge = errors.New("thumbnail: image too large")
+
+// ErrUnknownFormat is returned for a format other than JPEG or WebP
+var ErrUnknownFormat = errors.New("thumbnail: unknown format")
+
+// Valid reports whether size is a known variant
+func Valid(size string) bool {
+	_, ok := maxSides[size]
+	return ok
+}
+
+// URL is the URL of a variant of the photo served at url
+func URL(url, size string) string {
+	return url + "?size=" + size
+}
+
+// ContentType is the MIME type of a variant format
+func ContentType(format string) string {
+	if format == WebP {
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+// Key is the object key of a variant, stored next to the originals in the same bucket
+func Key(objectKey, size, format string) string {
+	ext := ".jpg"
+	if format == WebP {
+		ext = ".webp"
+	}
+	return variantPrefix + size + "/" + objectKey + ext
+}
+
+// IsVariant reports whether objectKey is a stored variant rather than an original
+func IsVariant(objectKey string) bool {
+	return strings.HasPrefix(objectKey, variantPrefix)
+}
+
+// Generate decodes a JPEG, PNG, GIF or WebP image, applies its EXIF orientation and returns a JPEG or WebP
+// whose longest side is at most the variant size. Smaller images are re-encoded without upscaling
+func Generate(data []byte, size, format string) ([]byte, error) {
+	maxSide, ok := maxSides[size]
+	if !ok {
+		return nil, ErrUnknownSize
+	}
+	if format != JPEG && format != WebP {
+		return nil, ErrUnknownFormat
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// The variant has no EXIF, so the rotation is applied to the pixels
+	if metadata, err := exif.Decode(bytes.NewReader(data)); err == nil {
+		img = Orient(img, metadata.Orientation)
+	}
+
+	img = Resize(img, maxSide)
+
+	var buf bytes.Buffer
+	if format == WebP {
+		err = webp.Encode(&buf, img, webp.Options{Quality: quality, Method: webp.DefaultMethod})
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Resize scales img down so that its longest side is at most maxSide, averaging the source pixels
+// covered by each output pixel
+func Resize(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := bounds.Min.Y+y*h/dh, bounds.Min.Y+(y+1)*h/dh
+		stepY := max((y1-y0)/maxSamples, 1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := bounds.Min.X+x*w/dw, bounds.Min.X+(x+1)*w/dw
+			stepX := max((x1-x0)/maxSamples, 1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < max(y1, y0+1); sy += stepY {
+				for sx := x0; sx < max(x1, x0+1); sx += stepX {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+pr>>8, g+pg>>8, b+pb>>8, a+pa>>8
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// Orient rotates and flips img so that it displays upright for the given EXIF orientation (1-8)
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	// Orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise to display
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise to display
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
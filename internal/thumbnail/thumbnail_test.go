@@ -0,0 +1,109 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func samplePNG(t *testing.T) []byte {
+	src := image.NewRGBA(image.Rect(0, 0, 1600, 1200))
+	for y := 0; y < 1200; y++ {
+		for x := 0; x < 1600; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateKeepsAspectRatio(t *testing.T) {
+	src := samplePNG(t)
+
+	data, err := Generate(src, Thumb, JPEG)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("variant is not a JPEG: %v", err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(320, 240) {
+		t.Fatalf("got size %v, want 320x240", got)
+	}
+
+	// Smaller than the variant: not upscaled
+	data, err = Generate(src, Medium, JPEG)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, _ = jpeg.Decode(bytes.NewReader(data))
+	if got := img.Bounds().Size(); got != image.Pt(1280, 960) {
+		t.Fatalf("got size %v, want 1280x960", got)
+	}
+
+	if _, err := Generate(src, "huge", JPEG); err != ErrUnknownSize {
+		t.Fatalf("got error %v, want ErrUnknownSize", err)
+	}
+	if _, err := Generate(src, Thumb, "avif"); err != ErrUnknownFormat {
+		t.Fatalf("got error %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestGenerateWebP(t *testing.T) {
+	data, err := Generate(samplePNG(t), Thumb, WebP)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != "webp" {
+		t.Fatalf("variant is not a WebP: %v %q", err, format)
+	}
+	if config.Width != 320 || config.Height != 240 {
+		t.Fatalf("got size %dx%d, want 320x240", config.Width, config.Height)
+	}
+
+	// A WebP original is decoded as well
+	if _, err := Generate(data, Thumb, JPEG); err != nil {
+		t.Fatalf("cannot generate from a WebP original: %v", err)
+	}
+}
+
+func TestIsVariant(t *testing.T) {
+	key := Key("work-confirmations/u1/1-a.jpg", Thumb, WebP)
+	if !IsVariant(key) {
+		t.Fatalf("%s is not reported as a variant", key)
+	}
+	if IsVariant("work-confirmations/u1/1-a.jpg") {
+		t.Fatal("original is reported as a variant")
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2x1 image: red on the left, blue on the right
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	// Orientation 6: the camera was turned clockwise, the left edge ends up on top
+	rotated := Orient(src, 6)
+	if got := rotated.Bounds().Size(); got != image.Pt(1, 2) {
+		t.Fatalf("got size %v, want 1x2", got)
+	}
+	if got := color.RGBAModel.Convert(rotated.At(0, 0)); got != red {
+		t.Fatalf("got top pixel %v, want red", got)
+	}
+
+	if Orient(src, 1) != image.Image(src) {
+		t.Fatal("orientation 1 must return the image unchanged")
+	}
+}
@@ -0,0 +1,121 @@
+package timespan
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultTimezone là múi giờ dùng khi client không gửi timezone
+const DefaultTimezone = "Asia/Ho_Chi_Minh"
+
+const (
+	DateLayout = "2006-01-02"
+	TimeLayout = "15:04"
+)
+
+// ErrEmptySpan là lỗi khi start_time và end_time trùng nhau
+var ErrEmptySpan = errors.New("end_time must be different from start_time")
+
+// localLayouts là các định dạng thời điểm không kèm offset, được hiểu theo múi giờ của đơn
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// Day là phần của một khoảng thời gian rơi vào một ngày theo giờ địa phương
+type Day struct {
+	Date    string    `json:"date" bson:"date"` // YYYY-MM-DD
+	Start   time.Time `json:"start" bson:"start"`
+	End     time.Time `json:"end" bson:"end"`
+	Minutes int       `json:"minutes" bson:"minutes"`
+}
+
+// LoadLocation trả về múi giờ theo tên IANA, rỗng là DefaultTimezone
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	return time.LoadLocation(name)
+}
+
+// Parse đọc thời điểm dạng RFC3339 (giữ nguyên offset) hoặc không kèm offset (hiểu theo loc)
+func Parse(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("invalid time format, expected RFC3339 or YYYY-MM-DDTHH:MM")
+}
+
+// FromLegacy đổi date + start_time/end_time (HH:MM) sang hai thời điểm theo loc.
+// end_time trước start_time được hiểu là kết thúc vào ngày hôm sau (ca đêm), trùng nhau trả về ErrEmptySpan
+func FromLegacy(date, startTime, endTime string, loc *time.Location) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation(DateLayout, date, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid date format, expected YYYY-MM-DD")
+	}
+
+	startClock, err := time.Parse(TimeLayout, startTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid start_time format, expected HH:MM")
+	}
+
+	endClock, err := time.Parse(TimeLayout, endTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid end_time format, expected HH:MM")
+	}
+
+	if endClock.Equal(startClock) {
+		return time.Time{}, time.Time{}, ErrEmptySpan
+	}
+
+	start := at(day, startClock, loc)
+	end := at(day, endClock, loc)
+	if endClock.Before(startClock) {
+		end = at(day.AddDate(0, 0, 1), endClock, loc)
+	}
+
+	return start, end, nil
+}
+
+// Legacy trả về date, start_time, end_time (theo loc) của khoảng thời gian cho client cũ
+func Legacy(start, end time.Time, loc *time.Location) (string, string, string) {
+	start, end = start.In(loc), end.In(loc)
+	return start.Format(DateLayout), start.Format(TimeLayout), end.Format(TimeLayout)
+}
+
+// SplitDays chia khoảng [start, end) thành từng ngày theo giờ địa phương của loc
+func SplitDays(start, end time.Time, loc *time.Location) []Day {
+	start, end = start.In(loc), end.In(loc)
+
+	days := make([]Day, 0)
+	for cursor := start; cursor.Before(end); {
+		y, m, d := cursor.Date()
+		next := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		if next.After(end) {
+			next = end
+		}
+
+		days = append(days, Day{
+			Date:    cursor.Format(DateLayout),
+			Start:   cursor,
+			End:     next,
+			Minutes: int(next.Sub(cursor) / time.Minute),
+		})
+		cursor = next
+	}
+
+	return days
+}
+
+func at(day time.Time, clock time.Time, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+}
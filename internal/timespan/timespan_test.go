@@ -0,0 +1,77 @@
+package timespan
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFromLegacyOvernight(t *testing.T) {
+	loc, _ := LoadLocation("")
+
+	start, end, err := FromLegacy("2026-03-10", "22:00", "06:00", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := end.Sub(start); got != 8*time.Hour {
+		t.Fatalf("got duration %v, want 8h", got)
+	}
+
+	date, startTime, endTime := Legacy(start, end, loc)
+	if date != "2026-03-10" || startTime != "22:00" || endTime != "06:00" {
+		t.Fatalf("got %s %s-%s, want 2026-03-10 22:00-06:00", date, startTime, endTime)
+	}
+}
+
+func TestFromLegacyEqualTimes(t *testing.T) {
+	loc, _ := LoadLocation("")
+
+	if _, _, err := FromLegacy("2026-03-10", "08:00", "08:00", loc); !errors.Is(err, ErrEmptySpan) {
+		t.Fatalf("got error %v, want ErrEmptySpan", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	loc, _ := LoadLocation("")
+
+	local, err := Parse("2026-03-10T08:30", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	withOffset, err := Parse("2026-03-10T01:30:00Z", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !local.Equal(withOffset) {
+		t.Fatalf("got %v and %v, want the same instant", local, withOffset)
+	}
+
+	if _, err := Parse("10/03/2026", loc); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestSplitDays(t *testing.T) {
+	loc, _ := LoadLocation("")
+	start := time.Date(2026, 3, 10, 22, 0, 0, 0, loc)
+	end := time.Date(2026, 3, 12, 6, 30, 0, 0, loc)
+
+	days := SplitDays(start, end, loc)
+
+	want := []struct {
+		date    string
+		minutes int
+	}{
+		{"2026-03-10", 120},
+		{"2026-03-11", 1440},
+		{"2026-03-12", 390},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("got %d days, want %d", len(days), len(want))
+	}
+	for i, w := range want {
+		if days[i].Date != w.date || days[i].Minutes != w.minutes {
+			t.Errorf("day %d: got %s %d, want %s %d", i, days[i].Date, days[i].Minutes, w.date, w.minutes)
+		}
+	}
+}
@@ -2,14 +2,20 @@ package totp
 
 import (
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/subtle"
 	"encoding/base32"
 	"fmt"
 	"math"
+	"net/url"
 	"strings"
 	"time"
 )
 
+// secretSize is the secret length in bytes (160 bits, as recommended by RFC 4226)
+const secretSize = 20
+
 // TOTP represents a TOTP generator
 type TOTP struct {
 	Secret string
@@ -73,41 +79,42 @@ func (t *TOTP) GenerateCodeForCounter(counter uint64) (string, error) {
 
 // VerifyCode verifies a TOTP code
 func (t *TOTP) VerifyCode(code string) bool {
-	now := time.Now()
-
-	// Check current window
-	if t.verifyCodeAt(code, now) {
-		return true
-	}
+	_, ok := t.VerifyCodeCounter(code, time.Now())
+	return ok
+}
 
-	// Check previous window (for clock skew tolerance)
-	if t.verifyCodeAt(code, now.Add(-time.Duration(t.Period)*time.Second)) {
-		return true
-	}
+// VerifyCodeCounter verifies a code against the window around now (one step of clock
+// skew either way) and returns the matching counter so callers can reject replays
+func (t *TOTP) VerifyCodeCounter(code string, now time.Time) (uint64, bool) {
+	current := uint64(now.Unix()) / uint64(t.Period)
 
-	// Check next window (for clock skew tolerance)
-	if t.verifyCodeAt(code, now.Add(time.Duration(t.Period)*time.Second)) {
-		return true
+	for _, counter := range []uint64{current, current - 1, current + 1} {
+		if t.verifyCodeForCounter(code, counter) {
+			return counter, true
+		}
 	}
 
-	return false
+	return 0, false
 }
 
-// verifyCodeAt verifies a code at a specific time
-func (t *TOTP) verifyCodeAt(code string, timestamp time.Time) bool {
-	generatedCode, err := t.GenerateCodeAt(timestamp)
+// verifyCodeForCounter verifies a code for a specific counter
+func (t *TOTP) verifyCodeForCounter(code string, counter uint64) bool {
+	generatedCode, err := t.GenerateCodeForCounter(counter)
 	if err != nil {
 		return false
 	}
 
-	return generatedCode == code
+	return subtle.ConstantTimeCompare([]byte(generatedCode), []byte(code)) == 1
 }
 
-// GenerateSecret generates a random secret key
+// GenerateSecret generates a random base32 secret key
 func GenerateSecret() (string, error) {
-	// This is a simplified version - in production, use crypto/rand
-	secret := "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
-	return secret, nil
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.EncodeToString(buf), nil
 }
 
 // FormatSecret formats a secret key for display
@@ -130,13 +137,12 @@ func FormatSecret(secret string) string {
 // GenerateQRCodeURL generates a QR code URL for TOTP setup
 func GenerateQRCodeURL(issuer, accountName, secret string) string {
 	// Encode the parameters
-	encodedIssuer := strings.ReplaceAll(issuer, " ", "%20")
-	encodedAccount := strings.ReplaceAll(accountName, " ", "%20")
-	encodedSecret := strings.ReplaceAll(secret, " ", "%20")
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
 
-	// Create the TOTP URL
-	url := fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s",
-		encodedIssuer, encodedAccount, encodedSecret, encodedIssuer)
+	query := url.Values{}
+	query.Set("secret", strings.ReplaceAll(secret, " ", ""))
+	query.Set("issuer", issuer)
 
-	return url
+	// Create the TOTP URL
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
 }
@@ -0,0 +1,73 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B test vectors for SHA1, truncated to 6 digits
+func TestGenerateCodeAt(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	otp := NewTOTP(secret)
+
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range cases {
+		got, err := otp.GenerateCodeAt(time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("at %d: got %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestVerifyCodeCounter(t *testing.T) {
+	otp := NewTOTP(base32.StdEncoding.EncodeToString([]byte("12345678901234567890")))
+
+	now := time.Unix(1700000000, 0)
+	previous, _ := otp.GenerateCodeAt(now.Add(-30 * time.Second))
+
+	counter, ok := otp.VerifyCodeCounter(previous, now)
+	if !ok {
+		t.Fatal("expected code from previous window to be accepted")
+	}
+	if counter != uint64(now.Unix())/30-1 {
+		t.Errorf("unexpected counter %d", counter)
+	}
+
+	stale, _ := otp.GenerateCodeAt(now.Add(-90 * time.Second))
+	if _, ok = otp.VerifyCodeCounter(stale, now); ok {
+		t.Error("expected code outside the window to be rejected")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, _ := GenerateSecret()
+	b, _ := GenerateSecret()
+	if a == b {
+		t.Error("expected different secrets")
+	}
+	if _, err := base32.StdEncoding.DecodeString(a); err != nil {
+		t.Errorf("secret is not valid base32: %v", err)
+	}
+}
+
+func TestGenerateQRCodeURL(t *testing.T) {
+	got := GenerateQRCodeURL("BTC Land", "a@b.com", "ABCD EFGH")
+	if !strings.HasPrefix(got, "otpauth://totp/BTC%20Land:a@b.com?") {
+		t.Errorf("unexpected url %s", got)
+	}
+	if !strings.Contains(got, "secret=ABCDEFGH") {
+		t.Errorf("secret not encoded in %s", got)
+	}
+}
@@ -96,3 +96,20 @@ func RandomString(length int) string {
 	}
 	return string(result)
 }
+
+const digits = "0123456789"
+
+// RandomDigits generates a numeric code using crypto/rand, suitable for OTPs
+func RandomDigits(length int) string {
+	result := make([]byte, length)
+	for i := 0; i < length; i++ {
+		for {
+			num, err := cryptoRand.Int(cryptoRand.Reader, big.NewInt(int64(len(digits))))
+			if err == nil {
+				result[i] = digits[num.Int64()]
+				break
+			}
+		}
+	}
+	return string(result)
+}
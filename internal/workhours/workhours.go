@@ -0,0 +1,135 @@
+package workhours
+
+import (
+	"math"
+	"time"
+
+	"api/internal/timespan"
+)
+
+// Breakdown là số phút làm việc theo từng loại
+type Breakdown struct {
+	Normal   int `json:"normal"`   // Trong giờ hành chính của ngày làm việc
+	Overtime int `json:"overtime"` // Ngoài giờ hành chính của ngày làm việc
+	Weekend  int `json:"weekend"`  // Ngày không làm việc trong tuần
+	Holiday  int `json:"holiday"`  // Ngày lễ
+	Total    int `json:"total"`
+}
+
+// HoursBreakdown là Breakdown đổi sang giờ, làm tròn 2 chữ số
+type HoursBreakdown struct {
+	Normal   float64 `json:"normal"`
+	Overtime float64 `json:"overtime"`
+	Weekend  float64 `json:"weekend"`
+	Holiday  float64 `json:"holiday"`
+	Total    float64 `json:"total"`
+}
+
+// Add cộng dồn số phút của other
+func (b *Breakdown) Add(other Breakdown) {
+	b.Normal += other.Normal
+	b.Overtime += other.Overtime
+	b.Weekend += other.Weekend
+	b.Holiday += other.Holiday
+	b.Total += other.Total
+}
+
+// Hours đổi số phút sang giờ
+func (b Breakdown) Hours() HoursBreakdown {
+	return HoursBreakdown{
+		Normal:   toHours(b.Normal),
+		Overtime: toHours(b.Overtime),
+		Weekend:  toHours(b.Weekend),
+		Holiday:  toHours(b.Holiday),
+		Total:    toHours(b.Total),
+	}
+}
+
+func toHours(minutes int) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
+
+// Calendar là ngày làm việc trong tuần, giờ hành chính và các ngày lễ dùng để phân loại giờ công
+type Calendar struct {
+	workDays map[time.Weekday]bool
+	dayStart string
+	dayEnd   string
+	holidays map[string]string
+}
+
+// New tạo Calendar: workDays 0 = Chủ nhật ... 6 = Thứ bảy, dayStart/dayEnd dạng HH:MM, holidays theo YYYY-MM-DD
+func New(workDays []int, dayStart, dayEnd string, holidays map[string]string) *Calendar {
+	cal := &Calendar{
+		workDays: map[time.Weekday]bool{},
+		dayStart: dayStart,
+		dayEnd:   dayEnd,
+		holidays: holidays,
+	}
+	for _, day := range workDays {
+		cal.workDays[time.Weekday(day)] = true
+	}
+	return cal
+}
+
+// Classify phân loại các phần theo ngày địa phương (timespan.SplitDays) của một khoảng thời gian.
+// Chỉ tính các ngày trong [from, to], from/to rỗng là không giới hạn
+func (cal *Calendar) Classify(days []timespan.Day, loc *time.Location, from, to string) Breakdown {
+	var result Breakdown
+
+	for _, day := range days {
+		if (from != "" && day.Date < from) || (to != "" && day.Date > to) {
+			continue
+		}
+
+		result.Total += day.Minutes
+
+		if _, ok := cal.holidays[day.Date]; ok {
+			result.Holiday += day.Minutes
+			continue
+		}
+
+		date, err := time.ParseInLocation(timespan.DateLayout, day.Date, loc)
+		if err != nil {
+			result.Normal += day.Minutes
+			continue
+		}
+
+		if !cal.workDays[date.Weekday()] {
+			result.Weekend += day.Minutes
+			continue
+		}
+
+		normal := cal.normalMinutes(date, day.Start.In(loc), day.End.In(loc), loc)
+		result.Normal += normal
+		result.Overtime += day.Minutes - normal
+	}
+
+	return result
+}
+
+// normalMinutes là số phút của [start, end) nằm trong giờ hành chính của ngày date
+func (cal *Calendar) normalMinutes(date, start, end time.Time, loc *time.Location) int {
+	dayStart, err := time.Parse(timespan.TimeLayout, cal.dayStart)
+	if err != nil {
+		return 0
+	}
+	dayEnd, err := time.Parse(timespan.TimeLayout, cal.dayEnd)
+	if err != nil {
+		return 0
+	}
+
+	windowStart := time.Date(date.Year(), date.Month(), date.Day(), dayStart.Hour(), dayStart.Minute(), 0, 0, loc)
+	windowEnd := time.Date(date.Year(), date.Month(), date.Day(), dayEnd.Hour(), dayEnd.Minute(), 0, 0, loc)
+
+	if start.Before(windowStart) {
+		start = windowStart
+	}
+	if end.After(windowEnd) {
+		end = windowEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+
+	return int(end.Sub(start) / time.Minute)
+}
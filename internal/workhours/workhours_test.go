@@ -0,0 +1,86 @@
+package workhours
+
+import (
+	"testing"
+	"time"
+
+	"api/internal/timespan"
+)
+
+func TestClassify(t *testing.T) {
+	loc, _ := timespan.LoadLocation("")
+	// Monday to Friday, 08:00-17:00, 2026-03-11 (Wednesday) is a holiday
+	cal := New([]int{1, 2, 3, 4, 5}, "08:00", "17:00", map[string]string{"2026-03-11": "Holiday"})
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		end      time.Time
+		from, to string
+		want     Breakdown
+	}{
+		{
+			name:  "inside office hours",
+			start: at(9, 9, 0),
+			end:   at(9, 17, 0),
+			want:  Breakdown{Normal: 480, Total: 480},
+		},
+		{
+			name:  "before and after office hours",
+			start: at(9, 7, 0),
+			end:   at(9, 19, 30),
+			want:  Breakdown{Normal: 540, Overtime: 210, Total: 750},
+		},
+		{
+			name:  "overnight between work days",
+			start: at(9, 22, 0),
+			end:   at(10, 9, 0),
+			want:  Breakdown{Normal: 60, Overtime: 600, Total: 660},
+		},
+		{
+			name:  "overnight into a holiday",
+			start: at(10, 20, 0),
+			end:   at(11, 4, 0),
+			want:  Breakdown{Overtime: 240, Holiday: 240, Total: 480},
+		},
+		{
+			name:  "overnight into the weekend",
+			start: at(13, 16, 0),
+			end:   at(14, 2, 0),
+			want:  Breakdown{Normal: 60, Overtime: 420, Weekend: 120, Total: 600},
+		},
+		{
+			name:  "weekend",
+			start: at(15, 9, 0),
+			end:   at(15, 12, 0),
+			want:  Breakdown{Weekend: 180, Total: 180},
+		},
+		{
+			name:  "only days inside the period",
+			start: at(9, 22, 0),
+			end:   at(10, 9, 0),
+			to:    "2026-03-09",
+			want:  Breakdown{Overtime: 120, Total: 120},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := timespan.SplitDays(tt.start, tt.end, loc)
+			if got := cal.Classify(days, loc, tt.from, tt.to); got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHours(t *testing.T) {
+	got := Breakdown{Normal: 90, Overtime: 20, Total: 110}.Hours()
+	if got.Normal != 1.5 || got.Overtime != 0.33 || got.Total != 1.83 {
+		t.Fatalf("got %+v, want 1.5 normal, 0.33 overtime, 1.83 total", got)
+	}
+}
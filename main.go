@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"api/business/rbac"
+	workconfirmations "api/business/work-confirmations"
+	"api/business/workflows"
+	"api/config"
 	"api/internal/mongodb"
 	"api/internal/plog"
+	searedis "api/internal/redis"
 	"api/middleware"
 	"api/routers"
+	"api/schema/rolecol"
+	"api/schema/workconfirmationcol"
+	"api/schema/worksitecol"
+	"api/services/mail"
 	"api/services/minio"
+	"api/services/oauth2"
 	"api/services/oauth2/google"
 
 	"github.com/gin-gonic/gin"
@@ -49,6 +61,57 @@ func main() {
 		panic(err)
 	}
 
+	// seed system roles and load custom roles into the permission cache
+	if err = rbac.SeedSystemRoles(context.Background()); err != nil {
+		logger.Error().Msgf("error seeding system roles: %v", err)
+	}
+	if err = rbac.Load(context.Background()); err != nil {
+		logger.Error().Msgf("error loading roles: %v", err)
+	}
+	rbac.StartRefresher(time.Minute)
+
+	// leader stages approve by permission instead of the leader role
+	if err = workflows.MigrateApproverRules(context.Background()); err != nil {
+		logger.Error().Msgf("error migrating workflow approver rules: %v", err)
+	}
+
+	// fill start_at/end_at on work confirmations created before time spans existed
+	if err = workconfirmations.MigrateTimeSpans(context.Background()); err != nil {
+		logger.Error().Msgf("error migrating work confirmation time spans: %v", err)
+	}
+
+	// thumb_url/medium_url for photos uploaded before image variants existed
+	if err = workconfirmations.MigratePhotoVariants(context.Background()); err != nil {
+		logger.Error().Msgf("error migrating photo variant urls: %v", err)
+	}
+
+	// 2dsphere indexes for work site matching and the near site filter
+	if err = worksitecol.EnsureIndexes(context.Background()); err != nil {
+		logger.Error().Msgf("error creating work site indexes: %v", err)
+	}
+	if err = workconfirmationcol.EnsureIndexes(context.Background()); err != nil {
+		logger.Error().Msgf("error creating work confirmation indexes: %v", err)
+	}
+
+	// unique names of roles that are not deleted
+	if err = rolecol.EnsureIndexes(context.Background()); err != nil {
+		logger.Error().Msgf("error creating role indexes: %v", err)
+	}
+
+	// setup redis (oauth state, one-time login codes, mfa tokens)
+	redisConfig := config.LoadRedisConfig()
+	err = searedis.ConnectRedisV1(&searedis.RedisConnectionConfig{
+		Addr:     redisConfig.GetRedisAddr(),
+		Password: redisConfig.Password,
+		Database: redisConfig.DB,
+		PoolSize: redisConfig.PoolSize,
+	})
+	if err != nil {
+		logger.Error().Msgf("error connecting to redis: %v", err)
+	} else {
+		logger.Info().Msg("redis connected successfully")
+	}
+
 	gin.SetMode(gin.DebugMode)
 
 	logger.Info().Msgf("starting server in %s mode", env)
@@ -73,6 +136,23 @@ func main() {
 		logger.Info().Msg("Google OAuth2 client setup successfully")
 	}
 
+	// setup mail sender
+	_, err = mail.Config(&mail.ConfigOptions{
+		Token:     os.Getenv("MAILTRAP_TOKEN"),
+		FromEmail: os.Getenv("MAIL_FROM_EMAIL"),
+		FromName:  os.Getenv("MAIL_FROM_NAME"),
+	})
+	if err != nil {
+		logger.Error().Msgf("error setting up mail sender: %v", err)
+	} else {
+		logger.Info().Msg("mail sender setup successfully")
+	}
+
+	// register generic OIDC providers (discovery runs on first use)
+	for _, err := range oauth2.LoadOIDCProvidersFromEnv() {
+		logger.Error().Msgf("error setting up OIDC provider: %v", err)
+	}
+
 	// Setup minio client
 	minioEndpoint := cleanEndpoint(os.Getenv("MIN_ENDPOINT"))
 	logger.Info().Msgf("connecting to MinIO endpoint: %s", minioEndpoint)
@@ -3,10 +3,13 @@ package middleware
 import (
 	"os"
 	"strings"
+	"time"
 
 	"api/internal/jwt"
 	"api/internal/response"
+	"api/internal/timer"
 	"api/schema/usercol"
+	"api/schema/usersessioncol"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -15,6 +18,9 @@ import (
 
 type contextKey string
 
+// sessionTouchInterval limits how often a session's ActiveAt is written back
+const sessionTouchInterval = time.Minute
+
 // AuthMiddleware which authorizes the external client.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -59,8 +65,30 @@ func validateToken(c *gin.Context, token string) error {
 		return err
 	}
 
+	// tokens issued before sessions were tracked (or non-access tokens) carry no session
+	if claim.UserId == "" || claim.SessionId == "" {
+		return errors.New("SESSION_REVOKED")
+	}
+
+	session, err := usersessioncol.FindWithId(c.Request.Context(), claim.SessionId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errors.New("SESSION_REVOKED")
+		}
+		return err
+	}
+
+	if session.IsDelete || !session.IsEnable || session.UserId != claim.UserId {
+		return errors.New("SESSION_REVOKED")
+	}
+
+	if timer.Now().Sub(session.ActiveAt) > sessionTouchInterval {
+		_ = usersessioncol.Touch(c.Request.Context(), session.GetIDString())
+	}
+
 	// Add claim data to gin context
 	c.Set("user_id", claim.UserId)
+	c.Set("session_id", claim.SessionId)
 	return nil
 }
 
@@ -70,10 +98,11 @@ func CurrentUser(c *gin.Context) (*usercol.User, error) {
 		return nil, nil
 	}
 
-	authData := authParts[1]
-
-	if err := validateToken(c, authData); err != nil {
-		return nil, nil
+	// token is already validated when called from AuthMiddleware
+	if c.GetString("user_id") == "" {
+		if err := validateToken(c, authParts[1]); err != nil {
+			return nil, nil
+		}
 	}
 
 	userId := c.GetString("user_id")
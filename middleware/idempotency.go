@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"api/internal/plog"
+	searedis "api/internal/redis"
+	"api/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyHeader         = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyPrefix      = "idempotency:"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyTTL     = 24 * 60 * 60 // 24 hours
+	idempotencyLockTTL        = 60           // seconds a key stays reserved while the first request is processed
+	maxIdempotencyFormMemory  = 32 << 20     // same limit the multipart handlers use
+	maxIdempotencyBodySize    = 32 << 20     // larger bodies are rejected instead of hashed
+)
+
+// idempotencyRecord là response đầu tiên của một Idempotency-Key, Done=false khi request đầu còn đang xử lý
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// idempotencyWriter ghi lại response body để lưu cho các lần gửi lại
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency lưu response đầu tiên theo (user, header Idempotency-Key) trong Redis và trả lại nguyên response đó
+// cho các lần gửi lại, kèm header Idempotent-Replayed. Dùng lại key với nội dung request khác bị từ chối.
+// Request không có header được xử lý bình thường. Dùng sau AuthMiddleware
+func Idempotency() gin.HandlerFunc {
+	logger := plog.NewBizLogger("[middleware][idempotency]")
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			code := response.ErrorResponse("INVALID_PARAM: Idempotency-Key is too long")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		user, ok := ContextUser(c)
+		if !ok {
+			return
+		}
+
+		if searedis.GetClient() == nil {
+			logger.Warn().Msg("redis is not configured, Idempotency-Key is ignored")
+			c.Next()
+			return
+		}
+
+		fingerprint, err := requestFingerprint(c)
+		if err != nil {
+			message := "INVALID_PARAM: " + err.Error()
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				message = "REQUEST_TOO_LARGE: request body is larger than " + strconv.FormatInt(tooLarge.Limit>>20, 10) + " MB"
+			}
+			code := response.ErrorResponse(message)
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		keyHash := sha256.Sum256([]byte(key))
+		redisKey := idempotencyKeyPrefix + user.GetIDString() + ":" + hex.EncodeToString(keyHash[:])
+
+		// the reservation expires quickly so a crashed request does not block retries for the whole TTL
+		created, err := searedis.SetObjectNX(ctx, redisKey, &idempotencyRecord{Fingerprint: fingerprint}, idempotencyLockTTL)
+		if err != nil {
+			logger.Err(err).Msg("failed to reserve idempotency key")
+			code := response.ErrorResponse("SERVICE_UNAVAILABLE: failed to check Idempotency-Key")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		if !created {
+			replayIdempotent(c, redisKey, fingerprint)
+			return
+		}
+
+		release := func() {
+			if err := searedis.DeleteObject(ctx, redisKey); err != nil {
+				logger.Err(err).Msg("failed to release idempotency key")
+			}
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// server errors are not stored so the client can retry with the same key
+		if writer.Status() >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		record := &idempotencyRecord{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if err := searedis.SetObject(ctx, redisKey, record, idempotencyTTL()); err != nil {
+			logger.Err(err).Msg("failed to store idempotent response")
+		}
+	}
+}
+
+func replayIdempotent(c *gin.Context, redisKey, fingerprint string) {
+	record := &idempotencyRecord{}
+	found, err := searedis.GetObject(c.Request.Context(), redisKey, record)
+	if err != nil || !found {
+		// the first request just failed and released the key
+		code := response.ErrorResponse("IDEMPOTENCY_KEY_IN_PROGRESS: retry the request")
+		c.JSON(code.Code, code)
+		c.Abort()
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		code := response.ErrorResponse("IDEMPOTENCY_KEY_REUSED: Idempotency-Key was already used with a different request")
+		c.JSON(code.Code, code)
+		c.Abort()
+		return
+	}
+
+	if !record.Done {
+		code := response.ErrorResponse("IDEMPOTENCY_KEY_IN_PROGRESS: the original request is still being processed")
+		c.JSON(code.Code, code)
+		c.Abort()
+		return
+	}
+
+	c.Header(idempotencyReplayedHeader, "true")
+	c.Data(record.Status, record.ContentType, record.Body)
+	c.Abort()
+}
+
+// idempotencyTTL trả về thời gian lưu response theo giây (env IDEMPOTENCY_TTL_HOURS, mặc định 24 giờ)
+func idempotencyTTL() int {
+	hours, err := strconv.Atoi(os.Getenv("IDEMPOTENCY_TTL_HOURS"))
+	if err != nil || hours <= 0 {
+		return defaultIdempotencyTTL
+	}
+	return hours * 60 * 60
+}
+
+// requestFingerprint băm method, path và nội dung request. Multipart được băm theo từng field và nội dung file
+// vì boundary thay đổi giữa các lần gửi lại; JSON được chuẩn hoá để không phụ thuộc thứ tự key và khoảng trắng.
+// Body lớn hơn maxIdempotencyBodySize trả về *http.MaxBytesError
+func requestFingerprint(c *gin.Context) (string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotencyBodySize)
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		if err := c.Request.ParseMultipartForm(maxIdempotencyFormMemory); err != nil {
+			return "", err
+		}
+		form := c.Request.MultipartForm
+
+		fields := make([]string, 0, len(form.Value))
+		for name := range form.Value {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		for _, name := range fields {
+			for _, value := range form.Value[name] {
+				hash.Write([]byte("field:" + name + "=" + value + "\n"))
+			}
+		}
+
+		files := make([]string, 0, len(form.File))
+		for name := range form.File {
+			files = append(files, name)
+		}
+		sort.Strings(files)
+		for _, name := range files {
+			for _, fileHeader := range form.File[name] {
+				hash.Write([]byte("file:" + name + "=" + fileHeader.Filename + "\n"))
+				file, err := fileHeader.Open()
+				if err != nil {
+					return "", err
+				}
+				_, err = io.Copy(hash, file)
+				file.Close()
+				if err != nil {
+					return "", err
+				}
+			}
+		}
+
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var parsed interface{}
+	if json.Unmarshal(body, &parsed) == nil {
+		if normalized, err := json.Marshal(parsed); err == nil {
+			body = normalized
+		}
+	}
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
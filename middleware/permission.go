@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"api/business/rbac"
+	"api/internal/response"
+	"api/schema/usercol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Require chỉ cho phép user có ít nhất một trong các quyền, dùng sau AuthMiddleware
+func Require(permissions ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := ContextUser(c)
+		if !ok {
+			return
+		}
+
+		if !rbac.CanAny(user, permissions...) {
+			code := response.ErrorResponse("PERMISSION_DENIED: you do not have permission to perform this action")
+			c.JSON(code.Code, code)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ContextUser lấy user đã xác thực do AuthMiddleware gắn vào context, trả lỗi 401 nếu không có
+func ContextUser(c *gin.Context) (*usercol.User, bool) {
+	userInterface, exists := c.Get("current_user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse("Unauthorized"))
+		c.Abort()
+		return nil, false
+	}
+
+	user, ok := userInterface.(*usercol.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse("Invalid user"))
+		c.Abort()
+		return nil, false
+	}
+
+	return user, true
+}
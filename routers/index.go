@@ -3,12 +3,19 @@ package routers
 import (
 	"api/business/auth"
 	"api/business/dashboard"
+	"api/business/delegations"
 	"api/business/departments"
 	"api/business/healthcheck"
 	"api/business/images"
+	"api/business/invitations"
+	"api/business/notifications"
 	"api/business/profile"
+	"api/business/roles"
 	"api/business/teams"
 	workconfirmations "api/business/work-confirmations"
+	"api/business/workflows"
+	"api/business/worksites"
+	"api/business/worktime"
 
 	"github.com/gin-gonic/gin"
 )
@@ -36,10 +43,38 @@ func InitRouter(r *gin.Engine) {
 	departmentsRouter := r.Group("teams")
 	departments.Router(departmentsRouter)
 
+	// Invitations routes (for leaders and managers)
+	invitationsRouter := r.Group("invitations")
+	invitations.Router(invitationsRouter)
+
+	// Delegations routes (for managers and leaders, visible to delegates)
+	delegationsRouter := r.Group("delegations")
+	delegations.Router(delegationsRouter)
+
+	// Workflows routes (for leaders)
+	workflowsRouter := r.Group("workflows")
+	workflows.Router(workflowsRouter)
+
+	// Work time routes (policy and holidays for leaders, totals for all authenticated users)
+	workTimeRouter := r.Group("work-time")
+	worktime.Router(workTimeRouter)
+
+	// Work sites routes (sites for leaders, list for all authenticated users)
+	workSitesRouter := r.Group("work-sites")
+	worksites.Router(workSitesRouter)
+
+	// Roles routes (for leaders)
+	rolesRouter := r.Group("roles")
+	roles.Router(rolesRouter)
+
 	// Dashboard routes (for leaders)
 	dashboardRouter := r.Group("dashboard")
 	dashboard.Router(dashboardRouter)
 
+	// Notifications routes (for all authenticated users)
+	notificationsRouter := r.Group("notifications")
+	notifications.Router(notificationsRouter)
+
 	// Profile routes (for all authenticated users)
 	profileRouter := r.Group("profile")
 	profile.Router(profileRouter)
@@ -0,0 +1,35 @@
+package commentcol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+)
+
+type Attachment struct {
+	URL        string    `json:"url" bson:"url"`
+	Filename   string    `json:"filename" bson:"filename"`
+	UploadedAt time.Time `json:"uploaded_at" bson:"uploaded_at"`
+}
+
+// Comment là bình luận trong đơn xác nhận công tác giữa người tạo và người duyệt
+type Comment struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	WorkConfirmationID string       `json:"work_confirmation_id" bson:"work_confirmation_id"`
+	AuthorID           string       `json:"author_id" bson:"author_id"`                     // user_id người bình luận
+	Text               string       `json:"text" bson:"text"`                               // Nội dung
+	Attachments        []Attachment `json:"attachments" bson:"attachments"`                 // Hình ảnh đính kèm
+	Mentions           []string     `json:"mentions,omitempty" bson:"mentions,omitempty"`   // user_id những người được nhắc tới
+	EditedAt           time.Time    `json:"edited_at,omitempty" bson:"edited_at,omitempty"` // Lần sửa gần nhất
+
+	// Soft delete
+	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
+	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (Comment) CollectionName() string {
+	return "work_confirmation_comment"
+}
@@ -0,0 +1,136 @@
+package commentcol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create tạo bình luận mới
+func Create(ctx context.Context, data *Comment) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+	data.IsDelete = false
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật bình luận
+func Update(ctx context.Context, data *Comment) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil,
+		bsonutil.ConvertStructToBSONMap(
+			data,
+			&bsonutil.MappingOpts{RemoveID: true},
+		),
+	)
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Comment{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SoftDelete xóa mềm bình luận
+func SoftDelete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	now := timer.Now()
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	update := bsonutil.BsonSetMap(nil, primitive.M{
+		"is_delete":  true,
+		"deleted_at": now,
+		"updated_at": now,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Comment{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindByID tìm bình luận theo ID
+func FindByID(ctx context.Context, id string) (*Comment, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindByWorkConfirmationID lấy bình luận của đơn theo thứ tự thời gian
+func FindByWorkConfirmationID(ctx context.Context, workConfirmationID string, ops *options.FindOptions) ([]*Comment, int64, error) {
+	filter := bsonutil.BsonAdd(nil, "work_confirmation_id", workConfirmationID)
+	if ops == nil {
+		ops = options.Find()
+	}
+	ops.SetSort(primitive.D{{Key: "created_at", Value: 1}})
+
+	return FindWithFilter(ctx, filter, ops)
+}
+
+// FindWithCondition tìm bình luận với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*Comment, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Comment{})
+
+	result := &Comment{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindWithFilter tìm danh sách bình luận với filter và phân trang
+func FindWithFilter(ctx context.Context, filter primitive.D, ops *options.FindOptions) ([]*Comment, int64, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Comment{})
+
+	// Thêm điều kiện không bị xóa
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	var results []*Comment
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := coll.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
@@ -0,0 +1,48 @@
+package delegationcol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+)
+
+// Delegation là uỷ quyền duyệt đơn có thời hạn: người được uỷ quyền duyệt/từ chối thay người uỷ quyền
+type Delegation struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	// Thông tin uỷ quyền
+	DelegatorID string   `json:"delegator_id" bson:"delegator_id"`             // user_id người uỷ quyền (quản lý/lãnh đạo vắng mặt)
+	DelegateID  string   `json:"delegate_id" bson:"delegate_id"`               // user_id người được uỷ quyền
+	TeamIDs     []string `json:"team_ids,omitempty" bson:"team_ids,omitempty"` // Giới hạn theo team, rỗng là tất cả
+	StartDate   string   `json:"start_date" bson:"start_date"`                 // YYYY-MM-DD
+	EndDate     string   `json:"end_date" bson:"end_date"`                     // YYYY-MM-DD (bao gồm ngày này)
+	Reason      string   `json:"reason" bson:"reason"`                         // Lý do (nghỉ phép, công tác, ...)
+	CreatedBy   string   `json:"created_by" bson:"created_by"`                 // user_id người tạo (người uỷ quyền hoặc lãnh đạo)
+
+	// Thu hồi
+	RevokedAt time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RevokedBy string    `json:"revoked_by,omitempty" bson:"revoked_by,omitempty"`
+
+	// Soft delete
+	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
+	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (Delegation) CollectionName() string {
+	return "delegation"
+}
+
+// CoversTeam kiểm tra uỷ quyền có áp dụng cho team không
+func (d *Delegation) CoversTeam(teamID string) bool {
+	if len(d.TeamIDs) == 0 {
+		return true
+	}
+	for _, id := range d.TeamIDs {
+		if id == teamID {
+			return true
+		}
+	}
+	return false
+}
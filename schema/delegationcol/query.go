@@ -0,0 +1,120 @@
+package delegationcol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create tạo uỷ quyền mới
+func Create(ctx context.Context, data *Delegation) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+	data.IsDelete = false
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật uỷ quyền
+func Update(ctx context.Context, data *Delegation) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil,
+		bsonutil.ConvertStructToBSONMap(
+			data,
+			&bsonutil.MappingOpts{RemoveID: true},
+		),
+	)
+	// team_ids is omitted when empty, clear it so the delegation covers all teams again
+	if len(data.TeamIDs) == 0 {
+		update = bsonutil.BsonUnSet(update, "team_ids", "")
+	}
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Delegation{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindByID tìm uỷ quyền theo ID
+func FindByID(ctx context.Context, id string) (*Delegation, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindActiveForDelegate lấy các uỷ quyền đang có hiệu lực vào ngày date của người được uỷ quyền
+func FindActiveForDelegate(ctx context.Context, delegateID string, date string) ([]*Delegation, error) {
+	filter := bsonutil.BsonAdd(nil, "delegate_id", delegateID)
+	filter = bsonutil.BsonAdd(filter, "revoked_at", primitive.M{"$exists": false})
+	filter = bsonutil.BsonLessThanEqual(filter, "start_date", date)
+	filter = bsonutil.BsonGreaterThanEqual(filter, "end_date", date)
+
+	results, _, err := FindWithFilter(ctx, filter, options.Find().SetSort(primitive.D{{Key: "created_at", Value: 1}}))
+	return results, err
+}
+
+// FindWithCondition tìm uỷ quyền với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*Delegation, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Delegation{})
+
+	result := &Delegation{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindWithFilter tìm danh sách uỷ quyền với filter và phân trang
+func FindWithFilter(ctx context.Context, filter primitive.D, ops *options.FindOptions) ([]*Delegation, int64, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Delegation{})
+
+	// Thêm điều kiện không bị xóa
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	var results []*Delegation
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := coll.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
@@ -0,0 +1,26 @@
+package holidaycol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+)
+
+// Holiday là một ngày nghỉ lễ, làm việc vào ngày này được tính là làm ngày lễ
+type Holiday struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	Date      string `json:"date" bson:"date"`             // YYYY-MM-DD
+	Name      string `json:"name" bson:"name"`             // Tên ngày lễ, vd: "Quốc khánh"
+	CreatedBy string `json:"created_by" bson:"created_by"` // user_id người tạo
+
+	// Soft delete
+	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
+	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (Holiday) CollectionName() string {
+	return "holiday"
+}
@@ -0,0 +1,140 @@
+package holidaycol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create tạo ngày lễ mới
+func Create(ctx context.Context, data *Holiday) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+	data.IsDelete = false
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật ngày lễ
+func Update(ctx context.Context, data *Holiday) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"date":       data.Date,
+		"name":       data.Name,
+		"updated_at": data.UpdatedAt,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Holiday{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SoftDelete xoá mềm ngày lễ
+func SoftDelete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_delete":  true,
+		"deleted_at": timer.Now(),
+		"updated_at": timer.Now(),
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Holiday{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindByID tìm ngày lễ theo ID
+func FindByID(ctx context.Context, id string) (*Holiday, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindByDate tìm ngày lễ theo ngày
+func FindByDate(ctx context.Context, date string) (*Holiday, error) {
+	filter := bsonutil.BsonAdd(nil, "date", date)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindInRange lấy các ngày lễ trong khoảng [from, to] (YYYY-MM-DD), sắp xếp theo ngày
+func FindInRange(ctx context.Context, from string, to string) ([]*Holiday, error) {
+	filter := bsonutil.BsonAdd(nil, "date", bson.M{"$gte": from, "$lte": to})
+
+	results, _, err := FindWithFilter(ctx, filter, options.Find().SetSort(primitive.D{{Key: "date", Value: 1}}))
+	return results, err
+}
+
+// FindWithCondition tìm ngày lễ với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*Holiday, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Holiday{})
+
+	result := &Holiday{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindWithFilter tìm danh sách ngày lễ với filter và phân trang
+func FindWithFilter(ctx context.Context, filter primitive.D, ops *options.FindOptions) ([]*Holiday, int64, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Holiday{})
+
+	// Thêm điều kiện không bị xóa
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	var results []*Holiday
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := coll.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
@@ -0,0 +1,51 @@
+package invitationcol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+	"api/schema/usercol"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+	StatusRevoked  = "revoked"
+	StatusExpired  = "expired" // chỉ dùng khi hiển thị/lọc, không lưu vào DB
+)
+
+type Invitation struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	// Người được mời
+	Email     string       `json:"email" bson:"email"`                               // Email người được mời
+	Role      usercol.Role `json:"role" bson:"role"`                                 // Vai trò áp dụng khi nhận lời mời
+	TeamID    string       `json:"team_id,omitempty" bson:"team_id,omitempty"`       // Team sẽ tham gia (có thể trống)
+	ManagerID string       `json:"manager_id,omitempty" bson:"manager_id,omitempty"` // Quản lý trực tiếp khi vai trò là nhân viên
+
+	// Người mời
+	InvitedBy string `json:"invited_by" bson:"invited_by"` // user_id người mời
+
+	// Token
+	TokenHash string    `json:"-" bson:"token_hash"`          // sha256 của token gửi qua email hiện hành
+	ExpireAt  time.Time `json:"expire_at" bson:"expire_at"`   // Hết hạn lời mời
+	SentAt    time.Time `json:"sent_at" bson:"sent_at"`       // Lần gửi email gần nhất
+	SendCount int       `json:"send_count" bson:"send_count"` // Số lần đã gửi email
+
+	// Trạng thái
+	Status     string    `json:"status" bson:"status"` // pending, accepted, revoked
+	AcceptedBy string    `json:"accepted_by,omitempty" bson:"accepted_by,omitempty"`
+	AcceptedAt time.Time `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	RevokedAt  time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// IsUsable kiểm tra lời mời còn chờ và chưa hết hạn
+func (i *Invitation) IsUsable(now time.Time) bool {
+	return i.Status == StatusPending && now.Before(i.ExpireAt)
+}
+
+func (Invitation) CollectionName() string {
+	return "invitation"
+}
@@ -0,0 +1,154 @@
+package invitationcol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create tạo lời mời mới
+func Create(ctx context.Context, data *Invitation) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật lời mời
+func Update(ctx context.Context, data *Invitation) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil,
+		bsonutil.ConvertStructToBSONMap(
+			data,
+			&bsonutil.MappingOpts{RemoveID: true},
+		),
+	)
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Invitation{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindByID tìm lời mời theo id
+func FindByID(ctx context.Context, id string) (*Invitation, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindPendingByEmail tìm lời mời mới nhất còn hiệu lực của email
+func FindPendingByEmail(ctx context.Context, email string) (*Invitation, error) {
+	filter := bsonutil.BsonAdd(nil, "email", email)
+	filter = bsonutil.BsonAdd(filter, "status", StatusPending)
+	filter = bsonutil.BsonGreaterThan(filter, "expire_at", timer.Now())
+
+	findOptions := options.FindOne().SetSort(bsonutil.BsonAdd(nil, "created_at", -1))
+
+	return FindWithCondition(ctx, filter, findOptions)
+}
+
+// MarkAccepted chuyển lời mời sang accepted, chỉ thành công với lời mời đang chờ
+func MarkAccepted(ctx context.Context, id, userId string) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "status", StatusPending)
+
+	now := timer.Now()
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"status":      StatusAccepted,
+		"accepted_by": userId,
+		"accepted_at": now,
+		"updated_at":  now,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Invitation{})
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// RevokePendingByEmail thu hồi các lời mời đang chờ của email (khi mời lại)
+func RevokePendingByEmail(ctx context.Context, email string) error {
+	filter := bsonutil.BsonAdd(nil, "email", email)
+	filter = bsonutil.BsonAdd(filter, "status", StatusPending)
+
+	now := timer.Now()
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"status":     StatusRevoked,
+		"revoked_at": now,
+		"updated_at": now,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Invitation{})
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// FindWithCondition tìm với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*Invitation, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Invitation{})
+
+	result := &Invitation{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindWithFilter tìm danh sách lời mời kèm tổng số
+func FindWithFilter(ctx context.Context, filter primitive.D, ops *options.FindOptions) ([]*Invitation, int64, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Invitation{})
+
+	var results []*Invitation
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := coll.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
@@ -0,0 +1,41 @@
+package notificationcol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+)
+
+type NotificationType string
+
+const (
+	TypeComment NotificationType = "comment" // Có bình luận mới trong đơn mình tham gia
+	TypeMention NotificationType = "mention" // Được nhắc tới trong bình luận
+)
+
+// Notification là thông báo trong ứng dụng gửi tới một user
+type Notification struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	UserID  string           `json:"user_id" bson:"user_id"`   // user_id người nhận
+	Type    NotificationType `json:"type" bson:"type"`         // comment, mention
+	Title   string           `json:"title" bson:"title"`       // Tiêu đề
+	Body    string           `json:"body" bson:"body"`         // Nội dung
+	ActorID string           `json:"actor_id" bson:"actor_id"` // user_id người tạo ra thông báo
+
+	// Đối tượng liên quan
+	WorkConfirmationID string `json:"work_confirmation_id,omitempty" bson:"work_confirmation_id,omitempty"`
+	CommentID          string `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+
+	ReadAt time.Time `json:"read_at,omitempty" bson:"read_at,omitempty"`
+
+	// Soft delete
+	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
+	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (Notification) CollectionName() string {
+	return "notification"
+}
@@ -0,0 +1,102 @@
+package notificationcol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateMany tạo nhiều thông báo cùng lúc
+func CreateMany(ctx context.Context, data []*Notification) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Notification{})
+
+	now := timer.Now()
+	documents := make([]interface{}, 0, len(data))
+	for _, n := range data {
+		n.CreatedAt = now
+		n.UpdatedAt = now
+		n.IsDelete = false
+		documents = append(documents, n)
+	}
+
+	return coll.CreateManyWithCtx(ctx, documents)
+}
+
+// MarkRead đánh dấu đã đọc các thông báo của user, ids rỗng là tất cả thông báo chưa đọc
+func MarkRead(ctx context.Context, userID string, ids []string) (int64, error) {
+	filter := bsonutil.BsonAdd(nil, "user_id", userID)
+	filter = bsonutil.BsonAdd(filter, "read_at", primitive.M{"$exists": false})
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	if len(ids) > 0 {
+		objIDs := make([]primitive.ObjectID, 0, len(ids))
+		for _, id := range ids {
+			objID, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				continue
+			}
+			objIDs = append(objIDs, objID)
+		}
+		if len(objIDs) == 0 {
+			return 0, nil
+		}
+		filter = bsonutil.BsonIn(filter, "_id", objIDs)
+	}
+
+	now := timer.Now()
+	update := bsonutil.BsonSetMap(nil, primitive.M{
+		"read_at":    now,
+		"updated_at": now,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Notification{})
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// CountUnread đếm thông báo chưa đọc của user
+func CountUnread(ctx context.Context, userID string) (int64, error) {
+	filter := bsonutil.BsonAdd(nil, "user_id", userID)
+	filter = bsonutil.BsonAdd(filter, "read_at", primitive.M{"$exists": false})
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Notification{})
+	return coll.CountWithCtx(ctx, filter)
+}
+
+// FindWithFilter tìm danh sách thông báo với filter và phân trang
+func FindWithFilter(ctx context.Context, filter primitive.D, ops *options.FindOptions) ([]*Notification, int64, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Notification{})
+
+	// Thêm điều kiện không bị xóa
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	var results []*Notification
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := coll.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"os"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -99,6 +100,53 @@ func FindOTP(ctx context.Context, id string) (*OTP, error) {
 	return FindWithCondition(ctx, filter)
 }
 
+// MarkVerified đánh dấu OTP đã dùng trong một thao tác, chỉ khi mã đúng, chưa dùng, còn hạn và chưa quá số lần thử.
+// Trả về mongo.ErrNoDocuments nếu không thoả điều kiện (ví dụ request khác đã dùng mã trước)
+func MarkVerified(ctx context.Context, id, code string, maxAttempts int) (*OTP, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "code", code)
+	filter = bsonutil.BsonAdd(filter, "is_verified", bson.M{"$ne": true})
+	filter = bsonutil.BsonAdd(filter, "attempts", bson.M{"$not": bson.M{"$gte": maxAttempts}})
+	filter = bsonutil.BsonGreaterThan(filter, "expire_at", timer.Now())
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_verified": true,
+		"updated_at":  timer.Now(),
+	})
+
+	result := &OTP{}
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &OTP{})
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err = coll.FirstAndUpdateWithCtx(ctx, filter, update, result, opts); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// IncrAttempts tăng số lần nhập sai của OTP
+func IncrAttempts(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	update := bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"updated_at": timer.Now()},
+	}
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &OTP{})
+	_, err = coll.UpdateOne(ctx, filter, update)
+	return err
+}
+
 // FindWithCondition find common
 func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*OTP, error) {
 	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &OTP{})
@@ -0,0 +1,28 @@
+package rolecol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+)
+
+type Role struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	// Thông tin vai trò
+	Name        string   `json:"name" bson:"name"`               // Mã vai trò, lưu ở user.role (vd: employee, hr)
+	TextVi      string   `json:"text_vi" bson:"text_vi"`         // Tên hiển thị tiếng Việt
+	TextEn      string   `json:"text_en" bson:"text_en"`         // Tên hiển thị tiếng Anh
+	Permissions []string `json:"permissions" bson:"permissions"` // Danh sách quyền (vd: work_confirmation.approve)
+	IsSystem    bool     `json:"is_system" bson:"is_system"`     // Vai trò hệ thống: không xoá, quyền do code quản lý
+
+	// Soft delete
+	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
+	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (Role) CollectionName() string {
+	return "role"
+}
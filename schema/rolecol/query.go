@@ -0,0 +1,199 @@
+package rolecol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes tạo index unique cho tên vai trò chưa bị xoá, vai trò đã xoá mềm không chặn việc tạo lại cùng tên
+func EnsureIndexes(ctx context.Context) error {
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Role{})
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"is_delete": false}),
+	})
+	return err
+}
+
+// Create tạo mới vai trò
+func Create(ctx context.Context, data *Role) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+	data.IsDelete = false
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật vai trò
+func Update(ctx context.Context, data *Role) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil,
+		bsonutil.ConvertStructToBSONMap(
+			data,
+			&bsonutil.MappingOpts{RemoveID: true},
+		),
+	)
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Role{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpsertSystem tạo hoặc đồng bộ vai trò hệ thống theo tên. Tên hiển thị chỉ được ghi lần đầu,
+// danh sách quyền luôn lấy theo code
+func UpsertSystem(ctx context.Context, data *Role) error {
+	filter := bsonutil.BsonAdd(nil, "name", data.Name)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	now := timer.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"permissions": data.Permissions,
+			"is_system":   true,
+			"updated_at":  now,
+		},
+		"$setOnInsert": bson.M{
+			"name":       data.Name,
+			"text_vi":    data.TextVi,
+			"text_en":    data.TextEn,
+			"is_delete":  false,
+			"created_at": now,
+		},
+	}
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Role{})
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// FindByID tìm vai trò theo ID
+func FindByID(ctx context.Context, id string) (*Role, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindByName tìm vai trò theo mã
+func FindByName(ctx context.Context, name string) (*Role, error) {
+	filter := bsonutil.BsonAdd(nil, "name", name)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindAll lấy tất cả vai trò chưa bị xoá
+func FindAll(ctx context.Context) ([]*Role, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Role{})
+
+	filter := bsonutil.BsonAdd(nil, "is_delete", false)
+	findOptions := options.Find().SetSort(primitive.D{
+		{Key: "is_system", Value: -1},
+		{Key: "created_at", Value: 1},
+	})
+
+	var results []*Role
+	cursor, err := coll.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SoftDelete xoá mềm vai trò, vai trò hệ thống không bị ảnh hưởng
+func SoftDelete(ctx context.Context, id string) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_system", false)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	now := timer.Now()
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_delete":  true,
+		"deleted_at": now,
+		"updated_at": now,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Role{})
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// Restore bỏ xoá mềm vai trò, dùng khi việc xoá phải huỷ vì vai trò vẫn còn được dùng
+func Restore(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", true)
+
+	update := bson.M{
+		"$set":   bson.M{"is_delete": false, "updated_at": timer.Now()},
+		"$unset": bson.M{"deleted_at": ""},
+	}
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Role{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindWithCondition tìm vai trò với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*Role, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Role{})
+
+	result := &Role{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -22,15 +22,17 @@ type User struct {
 	Role Role `json:"role,omitempty" bson:"role,omitempty"` // employee, manager, leader, assistant_director
 
 	// Authentication
-	Password      string `json:"password" bson:"password"`
+	Password      string `json:"-" bson:"password"`
 	IsSetPassword bool   `json:"is_set_password" bson:"is_set_password"`
+	IsMFAEnabled  bool   `json:"is_mfa_enabled" bson:"is_mfa_enabled"` // Đã bật xác thực 2 lớp (TOTP)
 
 	// Verification
 	IsVerifyPhone bool `json:"is_verify_phone" bson:"is_verify_phone"`
 	IsVerifyEmail bool `json:"is_verify_email" bson:"is_verify_email"`
 
 	// OAuth
-	OAuthProvider *OAuthProvider `json:"oauth_provider,omitempty" bson:"oauth_provider,omitempty"` // Thông tin nhà cung cấp OAuth (Google, etc.)
+	OAuthProvider *OAuthProvider `json:"oauth_provider,omitempty" bson:"oauth_provider,omitempty"` // Deprecated: chuyển sang Identities ở lần đăng nhập kế tiếp
+	Identities    []Identity     `json:"identities,omitempty" bson:"identities,omitempty"`         // Các tài khoản OAuth/OIDC đã liên kết, khớp theo (provider, subject)
 
 	// Soft delete
 	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
@@ -42,6 +44,24 @@ type OAuthProvider struct {
 	ProviderID   string          `json:"provider_id" bson:"provider_id"`     // ID người dùng từ nhà cung cấp OAuth
 }
 
+// Identity là một tài khoản bên ngoài đã liên kết với user
+type Identity struct {
+	Provider oauth2.Provider `json:"provider" bson:"provider"` // Tên provider (google, entra, ...)
+	Subject  string          `json:"subject" bson:"subject"`   // ID user phía provider (claim sub)
+	Email    string          `json:"email" bson:"email"`       // Email provider trả về lúc liên kết
+	LinkedAt time.Time       `json:"linked_at" bson:"linked_at"`
+}
+
+// HasIdentity kiểm tra user đã liên kết (provider, subject) chưa
+func (u *User) HasIdentity(provider oauth2.Provider, subject string) bool {
+	for _, identity := range u.Identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return true
+		}
+	}
+	return false
+}
+
 func (User) CollectionName() string {
 	return "user"
 }
@@ -4,6 +4,7 @@ import (
 	"api/internal/mongodb"
 	bsonutil "api/internal/mongodb/utils"
 	"api/internal/timer"
+	"api/services/oauth2"
 	"context"
 	"os"
 
@@ -139,6 +140,126 @@ func FindUserByPhone(ctx context.Context, phone string, isVerify bool) (*User, e
 	return FindWithCondition(ctx, filter)
 }
 
+// FindWithIdentity tìm user đã liên kết tài khoản (provider, subject)
+func FindWithIdentity(ctx context.Context, provider oauth2.Provider, subject string) (*User, error) {
+	filter := bsonutil.BsonAdd(nil, "identities", bson.M{
+		"$elemMatch": bson.M{"provider": provider, "subject": subject},
+	})
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindWithLegacyProvider tìm user theo trường oauth_provider cũ
+func FindWithLegacyProvider(ctx context.Context, provider oauth2.Provider, providerId string) (*User, error) {
+	filter := bsonutil.BsonAdd(nil, "oauth_provider.provider_name", provider)
+	filter = bsonutil.BsonAdd(filter, "oauth_provider.provider_id", providerId)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// AddIdentity liên kết identity vào user, bỏ qua nếu (provider, subject) đã có.
+// Trường oauth_provider cũ được xoá để không còn được dùng để khớp user
+func AddIdentity(ctx context.Context, userId string, identity Identity) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "identities", bson.M{
+		"$not": bson.M{"$elemMatch": bson.M{"provider": identity.Provider, "subject": identity.Subject}},
+	})
+
+	update := bson.M{
+		"$push":  bson.M{"identities": identity},
+		"$set":   bson.M{"updated_at": timer.Now()},
+		"$unset": bson.M{"oauth_provider": ""},
+	}
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &User{})
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// RemoveIdentity huỷ liên kết identity (provider, subject) khỏi user
+func RemoveIdentity(ctx context.Context, userId string, provider oauth2.Provider, subject string) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "identities", bson.M{
+		"$elemMatch": bson.M{"provider": provider, "subject": subject},
+	})
+
+	update := bson.M{
+		"$pull": bson.M{"identities": bson.M{"provider": provider, "subject": subject}},
+		"$set":  bson.M{"updated_at": timer.Now()},
+	}
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &User{})
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// CountByRole đếm số user chưa bị xoá đang giữ vai trò
+// FindByIDs lấy các user theo danh sách ID trong một truy vấn, ID không hợp lệ bị bỏ qua
+func FindByIDs(ctx context.Context, userIds []string) ([]*User, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(userIds))
+	for _, id := range userIds {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	if len(objIDs) == 0 {
+		return []*User{}, nil
+	}
+
+	filter := bsonutil.BsonIn(nil, "_id", objIDs)
+
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &User{})
+	var results []*User
+	cursor, err := coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func CountByRole(ctx context.Context, role Role) (int64, error) {
+	filter := bsonutil.BsonAdd(nil, "role", role)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &User{})
+	return coll.CountWithCtx(ctx, filter)
+}
+
+// CountByRoles đếm số user chưa bị xoá đang giữ một trong các vai trò
+func CountByRoles(ctx context.Context, roles []Role) (int64, error) {
+	if len(roles) == 0 {
+		return 0, nil
+	}
+
+	filter := bsonutil.BsonIn(nil, "role", roles)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &User{})
+	return coll.CountWithCtx(ctx, filter)
+}
+
 // FindWithCondition find common
 func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*User, error) {
 	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &User{})
@@ -5,6 +5,7 @@ import (
 	bsonutil "api/internal/mongodb/utils"
 	"api/internal/timer"
 	"context"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"os"
@@ -42,6 +43,23 @@ func DisableAllDevice(ctx context.Context, userId string) error {
 	return nil
 }
 
+// Disable tắt thiết bị của user và xoá device token để thiết bị không còn nhận thông báo đẩy
+func Disable(ctx context.Context, userId, deviceId string) error {
+	filter := bsonutil.BsonAdd(nil, "user_id", userId)
+	filter = bsonutil.BsonAdd(filter, "device_id", deviceId)
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_enable":    false,
+		"is_current":   false,
+		"device_token": "",
+		"updated_at":   timer.Now(),
+	})
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &UserDevice{})
+	_, err := coll.UpdateMany(ctx, filter, update)
+	return err
+}
+
 func FindWithDeviceId(ctx context.Context, userId, deviceId string) (*UserDevice, error) {
 
 	// filter by project id
@@ -52,6 +70,16 @@ func FindWithDeviceId(ctx context.Context, userId, deviceId string) (*UserDevice
 	return FindWithCondition(ctx, filter)
 }
 
+// FindEnabledByUser lấy các thiết bị đang hoạt động của user, đăng nhập gần nhất trước
+func FindEnabledByUser(ctx context.Context, userId string) ([]*UserDevice, error) {
+	filter := bsonutil.BsonAdd(nil, "user_id", userId)
+	filter = bsonutil.BsonAdd(filter, "is_enable", true)
+
+	ops := options.Find().SetSort(primitive.D{{Key: "last_login_at", Value: -1}})
+
+	return FindWithFilter(ctx, filter, ops)
+}
+
 // FindWithCondition find common
 func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*UserDevice, error) {
 	coll := mongodb.CollRead(mongodb.GetDatabaseName(), &UserDevice{})
@@ -0,0 +1,40 @@
+package usermfacol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+)
+
+type UserMFA struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	UserId string `json:"user_id" bson:"user_id"`
+
+	// TOTP secret đã được mã hoá AES-GCM, không bao giờ lưu dạng plaintext
+	SecretEncrypted string `json:"-" bson:"secret_encrypted"`
+
+	IsConfirmed bool      `json:"is_confirmed" bson:"is_confirmed"`
+	ConfirmedAt time.Time `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty"`
+
+	// Counter TOTP đã dùng gần nhất, chống dùng lại cùng một mã
+	LastUsedCounter int64 `json:"-" bson:"last_used_counter"`
+
+	// Chống brute force ở bước xác thực thứ hai
+	FailedAttempts int       `json:"-" bson:"failed_attempts"`
+	LockedUntil    time.Time `json:"-" bson:"locked_until,omitempty"`
+
+	RecoveryCodes []RecoveryCode `json:"-" bson:"recovery_codes"`
+}
+
+// RecoveryCode mã khôi phục dùng một lần, chỉ lưu hash
+type RecoveryCode struct {
+	Hash   string    `json:"-" bson:"hash"`
+	UsedAt time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
+
+func (UserMFA) CollectionName() string {
+	return "user_mfa"
+}
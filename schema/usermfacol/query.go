@@ -0,0 +1,81 @@
+package usermfacol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create tạo mới cấu hình MFA cho user
+func Create(ctx context.Context, data *UserMFA) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật cấu hình MFA
+func Update(ctx context.Context, data *UserMFA) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil,
+		bsonutil.ConvertStructToBSONMap(
+			data,
+			&bsonutil.MappingOpts{RemoveID: true},
+		),
+	)
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &UserMFA{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindByUserID tìm cấu hình MFA của user
+func FindByUserID(ctx context.Context, userID string) (*UserMFA, error) {
+	filter := bsonutil.BsonAdd(nil, "user_id", userID)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// DeleteByUserID xoá cấu hình MFA của user
+func DeleteByUserID(ctx context.Context, userID string) error {
+	filter := bsonutil.BsonAdd(nil, "user_id", userID)
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &UserMFA{})
+	_, err := collection.DeleteMany(ctx, filter)
+	return err
+}
+
+// FindWithCondition tìm với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*UserMFA, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &UserMFA{})
+
+	result := &UserMFA{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
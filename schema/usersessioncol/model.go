@@ -21,6 +21,11 @@ type UserSession struct {
 	BrowserName string `json:"browser_name" bson:"browser_name"`
 	Platform    string `json:"platform" bson:"platform"`
 	IsEnable    bool   `json:"is_enable" bson:"is_enable"`
+
+	// Refresh token
+	RefreshTokenHash string    `json:"-" bson:"refresh_token_hash,omitempty"` // sha256 của jti refresh token hiện hành
+	RefreshExpireAt  time.Time `json:"refresh_expire_at" bson:"refresh_expire_at,omitempty"`
+	RevokeReason     string    `json:"revoke_reason,omitempty" bson:"revoke_reason,omitempty"`
 }
 
 func (UserSession) CollectionName() string {
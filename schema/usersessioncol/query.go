@@ -5,6 +5,9 @@ import (
 	bsonutil "api/internal/mongodb/utils"
 	"api/internal/timer"
 	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"os"
@@ -66,6 +69,109 @@ func RemoveAllSession(ctx context.Context, userId string) error {
 	return nil
 }
 
+// RotateRefreshToken thay refresh token của phiên nếu hash hiện tại khớp oldHash.
+// Trả về false khi phiên đã bị thu hồi hoặc token đã được dùng (xoay vòng trước đó)
+func RotateRefreshToken(ctx context.Context, id, oldHash, newHash string, expireAt time.Time) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "refresh_token_hash", oldHash)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+	filter = bsonutil.BsonAdd(filter, "is_enable", true)
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"refresh_token_hash": newHash,
+		"refresh_expire_at":  expireAt,
+		"active_at":          timer.Now(),
+	})
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &UserSession{})
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// Revoke thu hồi một phiên đăng nhập, phiên đã thu hồi không còn nhận thông báo đẩy
+func Revoke(ctx context.Context, id, reason string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_delete":     true,
+		"deleted_at":    timer.Now(),
+		"revoke_reason": reason,
+		"device_token":  "",
+	})
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &UserSession{})
+	_, err = coll.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindActiveByUser lấy các phiên còn hiệu lực (chưa thu hồi, refresh token chưa hết hạn) của user, mới hoạt động nhất trước
+func FindActiveByUser(ctx context.Context, userId string) ([]*UserSession, error) {
+	filter := bsonutil.BsonAdd(nil, "user_id", userId)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+	filter = bsonutil.BsonAdd(filter, "is_enable", true)
+	filter = bsonutil.BsonGreaterThan(filter, "refresh_expire_at", timer.Now())
+
+	ops := options.Find().SetSort(primitive.D{{Key: "active_at", Value: -1}})
+
+	return FindWithFilter(ctx, filter, ops)
+}
+
+// RevokeAllExcept thu hồi mọi phiên của user trừ phiên keepId
+func RevokeAllExcept(ctx context.Context, userId, keepId, reason string) (int64, error) {
+	filter := bsonutil.BsonAdd(nil, "user_id", userId)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+	if keepId != "" {
+		objID, err := primitive.ObjectIDFromHex(keepId)
+		if err != nil {
+			return 0, err
+		}
+		filter = bsonutil.BsonNotEqual(filter, "_id", objID)
+	}
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_delete":     true,
+		"deleted_at":    timer.Now(),
+		"revoke_reason": reason,
+		"device_token":  "",
+	})
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &UserSession{})
+	result, err := coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Touch cập nhật thời điểm hoạt động gần nhất của phiên
+func Touch(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	update := bsonutil.BsonSet(nil, "active_at", timer.Now())
+
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &UserSession{})
+	_, err = coll.UpdateOne(ctx, filter, update)
+	return err
+}
+
 // FindWithCondition find common
 func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*UserSession, error) {
 	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &UserSession{})
@@ -1,6 +1,18 @@
 package workconfirmationcol
 
+import "strings"
+
 func (s WorkConfirmationStatus) String() string {
 	return string(s)
 }
 
+// IsPending kiểm tra đơn có đang chờ duyệt ở một bước nào đó không
+func (s WorkConfirmationStatus) IsPending() bool {
+	return strings.HasPrefix(string(s), StatusPendingPrefix)
+}
+
+// PendingStatus trả về status của đơn khi đang chờ duyệt ở bước key
+func PendingStatus(key string) WorkConfirmationStatus {
+	return WorkConfirmationStatus(StatusPendingPrefix + key)
+}
+
@@ -1,7 +1,10 @@
 package workconfirmationcol
 
 import (
+	"api/internal/mongodb/field"
+	"api/internal/timespan"
 	"api/schema/usercol"
+	"api/schema/workflowcol"
 	"time"
 
 	"api/internal/mongodb"
@@ -14,26 +17,161 @@ const (
 	StatusPendingLeader  WorkConfirmationStatus = "pending_leader"
 	StatusApproved       WorkConfirmationStatus = "approved"
 	StatusRejected       WorkConfirmationStatus = "rejected"
+	StatusCancelled      WorkConfirmationStatus = "cancelled" // Người tạo rút đơn hoặc lãnh đạo huỷ đơn đã duyệt
+
+	// StatusPendingPrefix là tiền tố status khi đơn đang chờ duyệt ở một bước: pending_<stage key>
+	StatusPendingPrefix = "pending_"
+)
+
+type StageStatus string
+
+const (
+	StageWaiting  StageStatus = "waiting"  // Chưa tới bước này
+	StagePending  StageStatus = "pending"  // Đang chờ duyệt
+	StageApproved StageStatus = "approved" // Đã duyệt
+	StageRejected StageStatus = "rejected" // Đã từ chối
+	StageSkipped  StageStatus = "skipped"  // Bỏ qua theo điều kiện
 )
 
+// StageRecord là bản sao một bước của quy trình tại thời điểm tạo đơn cùng kết quả duyệt bước đó
+type StageRecord struct {
+	Key      string                    `json:"key" bson:"key"`
+	Name     string                    `json:"name" bson:"name"`
+	Approver workflowcol.ApproverRule  `json:"approver" bson:"approver"`
+	Skip     workflowcol.SkipCondition `json:"skip" bson:"skip"`
+
+	Status     StageStatus `json:"status" bson:"status"`
+	ActedBy    string      `json:"acted_by,omitempty" bson:"acted_by,omitempty"`         // user_id người duyệt/từ chối
+	OnBehalfOf string      `json:"on_behalf_of,omitempty" bson:"on_behalf_of,omitempty"` // user_id người uỷ quyền (khi duyệt thay)
+	ActedAt    time.Time   `json:"acted_at,omitempty" bson:"acted_at,omitempty"`
+	Comment    string      `json:"comment,omitempty" bson:"comment,omitempty"`
+}
+
 type Photo struct {
-	URL        string    `json:"url" bson:"url"`
-	Filename   string    `json:"filename" bson:"filename"`
-	UploadedAt time.Time `json:"uploaded_at" bson:"uploaded_at"`
+	URL         string     `json:"url" bson:"url"`
+	ThumbURL    string     `json:"thumb_url,omitempty" bson:"thumb_url,omitempty"`   // Ảnh thu nhỏ cho danh sách, tạo khi truy cập lần đầu
+	MediumURL   string     `json:"medium_url,omitempty" bson:"medium_url,omitempty"` // Ảnh cỡ vừa cho trang chi tiết
+	Filename    string     `json:"filename" bson:"filename"`
+	UploadedAt  time.Time  `json:"uploaded_at" bson:"uploaded_at"`
+	ContentType string     `json:"content_type,omitempty" bson:"content_type,omitempty"` // Xác định từ nội dung file, không theo header của client
+	Size        int64      `json:"size,omitempty" bson:"size,omitempty"`                 // Bytes
+	Exif        *PhotoExif `json:"exif,omitempty" bson:"exif,omitempty"`                 // Rỗng khi ảnh không có EXIF
+
+	// Phát hiện ảnh dùng lại ở đơn khác
+	Hash        string   `json:"hash,omitempty" bson:"hash,omitempty"`                 // dHash 64 bit dạng hex, rỗng khi không giải mã được ảnh (HEIC, WebP)
+	HashBands   []string `json:"-" bson:"hash_bands,omitempty"`                        // Các đoạn 16 bit của hash, có index để tìm ảnh gần giống
+	SHA256      string   `json:"sha256,omitempty" bson:"sha256,omitempty"`             // Dùng so khớp chính xác khi không có hash
+	DuplicateOf string   `json:"duplicate_of,omitempty" bson:"duplicate_of,omitempty"` // ID đơn khác có ảnh trùng lúc upload
+}
+
+// PhotoExif là thông tin EXIF đọc từ ảnh lúc upload
+type PhotoExif struct {
+	TakenAt  time.Time       `json:"taken_at,omitempty" bson:"taken_at,omitempty"` // Thời điểm chụp, theo múi giờ của đơn khi ảnh không ghi múi giờ
+	Location *field.Geometry `json:"location,omitempty" bson:"location,omitempty"` // GeoJSON Point [longitude, latitude]
+	Make     string          `json:"make,omitempty" bson:"make,omitempty"`
+	Model    string          `json:"model,omitempty" bson:"model,omitempty"`
+}
+
+type PhotoWarningCode string
+
+const (
+	PhotoTakenOutsideSpan PhotoWarningCode = "taken_outside_span" // Ảnh chụp ngoài thời gian công tác
+	PhotoFarFromCheckIn   PhotoWarningCode = "far_from_check_in"  // Vị trí chụp xa vị trí check-in
+	PhotoDuplicate        PhotoWarningCode = "duplicate"          // Ảnh trùng với ảnh của đơn khác
+)
+
+// PhotoWarning là cảnh báo về ảnh minh chứng để người duyệt xem xét
+type PhotoWarning struct {
+	Code        PhotoWarningCode `json:"code" bson:"code"`
+	Filename    string           `json:"filename" bson:"filename"`
+	Message     string           `json:"message" bson:"message"`
+	DuplicateOf string           `json:"duplicate_of,omitempty" bson:"duplicate_of,omitempty"` // ID đơn có ảnh trùng
 }
 
 type ApprovalInfo struct {
-	ApprovedBy string    `json:"approved_by" bson:"approved_by"` // user_id
+	ApprovedBy string    `json:"approved_by" bson:"approved_by"`                       // user_id người thực sự duyệt
+	OnBehalfOf string    `json:"on_behalf_of,omitempty" bson:"on_behalf_of,omitempty"` // user_id người uỷ quyền (khi duyệt thay)
 	ApprovedAt time.Time `json:"approved_at" bson:"approved_at"`
 	Comment    string    `json:"comment" bson:"comment"`
 }
 
 type RejectionInfo struct {
-	RejectedBy string    `json:"rejected_by" bson:"rejected_by"` // user_id
+	RejectedBy string    `json:"rejected_by" bson:"rejected_by"`                       // user_id người thực sự từ chối
+	OnBehalfOf string    `json:"on_behalf_of,omitempty" bson:"on_behalf_of,omitempty"` // user_id người uỷ quyền (khi từ chối thay)
 	RejectedAt time.Time `json:"rejected_at" bson:"rejected_at"`
 	Reason     string    `json:"reason" bson:"reason"`
 }
 
+// CancellationInfo là thông tin huỷ đơn: người tạo rút đơn đang chờ duyệt, hoặc lãnh đạo huỷ đơn đã duyệt
+type CancellationInfo struct {
+	CancelledBy string    `json:"cancelled_by" bson:"cancelled_by"` // user_id
+	CancelledAt time.Time `json:"cancelled_at" bson:"cancelled_at"`
+	Reason      string    `json:"reason" bson:"reason"`
+	Withdrawn   bool      `json:"withdrawn" bson:"withdrawn"` // true khi người tạo tự rút đơn
+}
+
+// ResubmissionInfo là thông tin lần gửi lại gần nhất của đơn bị từ chối
+type ResubmissionInfo struct {
+	Count             int           `json:"count" bson:"count"` // Số lần đã gửi lại
+	ResubmittedAt     time.Time     `json:"resubmitted_at" bson:"resubmitted_at"`
+	Note              string        `json:"note,omitempty" bson:"note,omitempty"`
+	PreviousRejection RejectionInfo `json:"previous_rejection" bson:"previous_rejection"` // Lý do từ chối trước khi gửi lại
+}
+
+type SiteStatus string
+
+const (
+	SiteInside  SiteStatus = "inside"  // Vị trí check-in nằm trong một địa điểm làm việc
+	SiteOutside SiteStatus = "outside" // Vị trí check-in không thuộc địa điểm nào
+)
+
+// CheckIn là vị trí GPS người tạo gửi kèm khi tạo đơn và địa điểm làm việc khớp với vị trí đó
+type CheckIn struct {
+	Location       *field.Geometry `json:"location" bson:"location"`                                   // GeoJSON Point [longitude, latitude]
+	AccuracyMeters float64         `json:"accuracy_meters,omitempty" bson:"accuracy_meters,omitempty"` // Độ chính xác GPS do thiết bị báo
+	SiteStatus     SiteStatus      `json:"site_status,omitempty" bson:"site_status,omitempty"`         // Rỗng khi chưa đối chiếu được địa điểm
+	SiteID         string          `json:"site_id,omitempty" bson:"site_id,omitempty"`
+	SiteName       string          `json:"site_name,omitempty" bson:"site_name,omitempty"`
+}
+
+type EventType string
+
+const (
+	EventCreated     EventType = "created"     // Tạo đơn
+	EventUpdated     EventType = "updated"     // Người tạo sửa nội dung đơn
+	EventApproved    EventType = "approved"    // Duyệt một bước
+	EventRejected    EventType = "rejected"    // Từ chối
+	EventWithdrawn   EventType = "withdrawn"   // Người tạo rút đơn đang chờ duyệt
+	EventResubmitted EventType = "resubmitted" // Người tạo sửa đơn bị từ chối và gửi lại
+	EventCancelled   EventType = "cancelled"   // Lãnh đạo huỷ đơn đã duyệt
+)
+
+// FieldChange là giá trị trước/sau của một trường bị sửa
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// Event là một mục trong lịch sử đơn. Lịch sử chỉ được thêm vào cùng lúc với thay đổi, không sửa/xoá
+type Event struct {
+	Type       EventType              `json:"type" bson:"type"`
+	ActorID    string                 `json:"actor_id" bson:"actor_id"`                             // user_id người thực hiện
+	OnBehalfOf string                 `json:"on_behalf_of,omitempty" bson:"on_behalf_of,omitempty"` // user_id người uỷ quyền (khi duyệt thay)
+	At         time.Time              `json:"at" bson:"at"`
+	StageKey   string                 `json:"stage_key,omitempty" bson:"stage_key,omitempty"` // Bước duyệt liên quan
+	FromStatus WorkConfirmationStatus `json:"from_status,omitempty" bson:"from_status,omitempty"`
+	ToStatus   WorkConfirmationStatus `json:"to_status,omitempty" bson:"to_status,omitempty"`
+	Comment    string                 `json:"comment,omitempty" bson:"comment,omitempty"`
+	Changes    []FieldChange          `json:"changes,omitempty" bson:"changes,omitempty"` // Các trường bị sửa
+	BatchID    string                 `json:"batch_id,omitempty" bson:"batch_id,omitempty"` // Mã lô khi duyệt/từ chối hàng loạt
+
+	// Nguồn thao tác
+	IP        string `json:"ip" bson:"ip"`
+	UserAgent string `json:"user_agent" bson:"user_agent"`
+	SessionID string `json:"session_id,omitempty" bson:"session_id,omitempty"`
+}
+
 type WorkConfirmation struct {
 	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
 	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
@@ -43,16 +181,41 @@ type WorkConfirmation struct {
 	CreatedBy  string      `json:"created_by" bson:"created_by"`   // user_id
 	CreatorRole usercol.Role `json:"creator_role" bson:"creator_role"` // employee, manager, leader, assistant_director
 
-	// Thông tin đơn
-	Date      string  `json:"date" bson:"date"`             // YYYY-MM-DD
+	// Thời gian công tác, có thể qua đêm hoặc kéo dài nhiều ngày
+	StartAt  time.Time      `json:"start_at" bson:"start_at"`
+	EndAt    time.Time      `json:"end_at" bson:"end_at"`
+	Timezone string         `json:"timezone" bson:"timezone"` // Múi giờ IANA, mặc định Asia/Ho_Chi_Minh
+	Days     []timespan.Day `json:"days" bson:"days"`         // Thời gian chia theo từng ngày địa phương, dùng cho báo cáo
+
+	// Thông tin đơn. date/start_time/end_time được tính từ start_at/end_at theo timezone, giữ cho client cũ
+	Date      string  `json:"date" bson:"date"`             // YYYY-MM-DD, ngày bắt đầu
+	EndDate   string  `json:"end_date" bson:"end_date"`     // YYYY-MM-DD, ngày kết thúc
 	StartTime string  `json:"start_time" bson:"start_time"` // HH:MM (24-hour format)
-	EndTime   string  `json:"end_time" bson:"end_time"`     // HH:MM (24-hour format)
+	EndTime   string  `json:"end_time" bson:"end_time"`     // HH:MM (24-hour format), có thể nhỏ hơn start_time khi qua đêm
 	Content   string  `json:"content" bson:"content"`       // Nội dung công tác
 	Photos    []Photo `json:"photos" bson:"photos"`         // Danh sách hình ảnh
 
+	// Vị trí check-in khi tạo đơn (không bắt buộc)
+	CheckIn *CheckIn `json:"check_in,omitempty" bson:"check_in,omitempty"`
+
+	// Cảnh báo từ EXIF của ảnh, tính lại khi sửa đơn
+	PhotoWarnings []PhotoWarning `json:"photo_warnings,omitempty" bson:"photo_warnings,omitempty"`
+
 	// Trạng thái
 	Status WorkConfirmationStatus `json:"status" bson:"status"`
 
+	// Phiên bản, tăng sau mỗi lần thay đổi. Mọi cập nhật đều kèm status và version mong đợi,
+	// cập nhật thất bại khi đơn đã bị người khác thay đổi trước. Đơn cũ chưa có version được xem là 0
+	Version int `json:"version" bson:"version"`
+
+	// Quy trình duyệt
+	WorkflowID string        `json:"workflow_id,omitempty" bson:"workflow_id,omitempty"` // Rỗng khi dùng quy trình mặc định có sẵn
+	StageIndex int           `json:"stage_index" bson:"stage_index"`                     // Vị trí bước hiện tại trong Stages
+	Stages     []StageRecord `json:"stages,omitempty" bson:"stages,omitempty"`           // Các bước duyệt và kết quả từng bước
+
+	// Lịch sử thao tác, xem qua GET /work-confirmations/:id/history
+	History []Event `json:"-" bson:"history,omitempty"`
+
 	// Xác nhận từ quản lý (chỉ khi đơn từ nhân viên)
 	ManagerApproval *ApprovalInfo `json:"manager_approval,omitempty" bson:"manager_approval,omitempty"`
 
@@ -62,6 +225,12 @@ type WorkConfirmation struct {
 	// Từ chối
 	Rejection *RejectionInfo `json:"rejection,omitempty" bson:"rejection,omitempty"`
 
+	// Gửi lại sau khi bị từ chối
+	Resubmission *ResubmissionInfo `json:"resubmission,omitempty" bson:"resubmission,omitempty"`
+
+	// Rút đơn/huỷ đơn
+	Cancellation *CancellationInfo `json:"cancellation,omitempty" bson:"cancellation,omitempty"`
+
 	// Soft delete
 	IsDelete bool      `json:"is_delete,omitempty" bson:"is_delete"`
 	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
@@ -4,13 +4,30 @@ import (
 	"api/internal/mongodb"
 	bsonutil "api/internal/mongodb/utils"
 	"api/internal/timer"
+	"api/schema/workflowcol"
 	"context"
 	"os"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// EnsureIndexes tạo index 2dsphere cho vị trí check-in (lọc đơn theo địa điểm)
+// và index cho hash của ảnh (tìm ảnh dùng lại ở đơn khác)
+func EnsureIndexes(ctx context.Context) error {
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "check_in.location", Value: "2dsphere"}}},
+		{Keys: bson.D{{Key: "photos.hash_bands", Value: 1}}},
+		{Keys: bson.D{{Key: "photos.sha256", Value: 1}}},
+		{Keys: bson.D{{Key: "created_by", Value: 1}, {Key: "start_at", Value: 1}, {Key: "end_at", Value: 1}}},
+	})
+	return err
+}
+
 // Create tạo mới đơn xác nhận công tác
 func Create(ctx context.Context, data *WorkConfirmation) (interface{}, error) {
 	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
@@ -18,6 +35,7 @@ func Create(ctx context.Context, data *WorkConfirmation) (interface{}, error) {
 	data.CreatedAt = timer.Now()
 	data.UpdatedAt = timer.Now()
 	data.IsDelete = false
+	data.Version = 1
 
 	id, err := coll.CreateWithCtx(ctx, data)
 	if err != nil {
@@ -27,31 +45,99 @@ func Create(ctx context.Context, data *WorkConfirmation) (interface{}, error) {
 	return id, nil
 }
 
-// Update cập nhật đơn xác nhận công tác
+// Update cập nhật đơn xác nhận công tác khi đơn vẫn ở status và version đã đọc,
+// trả về false nếu đơn đã bị người khác thay đổi trước
 func Update(ctx context.Context, data *WorkConfirmation) (bool, error) {
-	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	filter, err := expectedState(data, data.Status)
 	if err != nil {
 		return false, err
 	}
 
-	filter := bsonutil.BsonAdd(nil, "_id", objID)
-	filter = bsonutil.BsonAdd(filter, "is_delete", false)
-
 	data.UpdatedAt = timer.Now()
 
-	update := bsonutil.BsonSetMap(nil,
-		bsonutil.ConvertStructToBSONMap(
-			data,
-			&bsonutil.MappingOpts{RemoveID: true},
-		),
+	fields := bsonutil.ConvertStructToBSONMap(
+		data,
+		&bsonutil.MappingOpts{RemoveID: true},
 	)
+	// history is append-only, see UpdateContent and UpdateWorkflowState
+	delete(fields, "history")
+	delete(fields, "version")
 
-	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
-	_, err = collection.UpdateOne(ctx, filter, update)
+	update := bsonutil.BsonSetMap(nil, fields)
+
+	return applyUpdate(ctx, data, filter, update)
+}
+
+// UpdateContent lưu nội dung đơn do người tạo sửa và ghi lịch sử trong cùng một lần cập nhật.
+// Chỉ cập nhật khi đơn còn chờ duyệt ở status và version đã đọc, trả về false nếu đơn đã bị thay đổi
+func UpdateContent(ctx context.Context, data *WorkConfirmation, event *Event) (bool, error) {
+	if !data.Status.IsPending() {
+		return false, nil
+	}
+
+	filter, err := expectedState(data, data.Status)
 	if err != nil {
 		return false, err
 	}
-	return true, nil
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"start_at":       data.StartAt,
+		"end_at":         data.EndAt,
+		"timezone":       data.Timezone,
+		"days":           data.Days,
+		"date":           data.Date,
+		"end_date":       data.EndDate,
+		"start_time":     data.StartTime,
+		"end_time":       data.EndTime,
+		"content":        data.Content,
+		"photos":         data.Photos,
+		"photo_warnings": data.PhotoWarnings,
+		"updated_at":     data.UpdatedAt,
+	})
+	update = bsonutil.BsonPush(update, "history", event)
+
+	return applyUpdate(ctx, data, filter, update)
+}
+
+// Resubmit lưu nội dung sửa và quy trình mới của đơn bị từ chối, xoá kết quả duyệt/từ chối cũ
+// và ghi lịch sử trong cùng một lần cập nhật. Trả về false nếu đơn không còn bị từ chối ở version đã đọc
+func Resubmit(ctx context.Context, data *WorkConfirmation, event *Event) (bool, error) {
+	filter, err := expectedState(data, StatusRejected)
+	if err != nil {
+		return false, err
+	}
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"start_at":       data.StartAt,
+		"end_at":         data.EndAt,
+		"timezone":       data.Timezone,
+		"days":           data.Days,
+		"date":           data.Date,
+		"end_date":       data.EndDate,
+		"start_time":     data.StartTime,
+		"end_time":       data.EndTime,
+		"content":        data.Content,
+		"photos":         data.Photos,
+		"photo_warnings": data.PhotoWarnings,
+		"status":         data.Status,
+		"workflow_id":    data.WorkflowID,
+		"stage_index":    data.StageIndex,
+		"stages":         data.Stages,
+		"resubmission":   data.Resubmission,
+		"updated_at":     data.UpdatedAt,
+	})
+	update = append(update, bson.E{Key: "$unset", Value: bson.M{
+		"manager_approval": "",
+		"leader_approval":  "",
+		"rejection":        "",
+	}})
+	update = bsonutil.BsonPush(update, "history", event)
+
+	return applyUpdate(ctx, data, filter, update)
 }
 
 // FindByID tìm đơn theo ID
@@ -116,106 +202,78 @@ func FindByStatus(ctx context.Context, status WorkConfirmationStatus, ops *optio
 	return FindWithFilter(ctx, filter, ops)
 }
 
-// UpdateStatus cập nhật trạng thái đơn
-func UpdateStatus(ctx context.Context, id string, status WorkConfirmationStatus) error {
-	objID, err := primitive.ObjectIDFromHex(id)
+// UpdateStatus cập nhật trạng thái đơn khi đơn vẫn ở status và version đã đọc,
+// trả về false nếu đơn đã bị người khác thay đổi trước
+func UpdateStatus(ctx context.Context, data *WorkConfirmation, status WorkConfirmationStatus) (bool, error) {
+	filter, err := expectedState(data, data.Status)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	filter := bsonutil.BsonAdd(nil, "_id", objID)
-	update := bsonutil.BsonSet(nil, "status", status)
-	update = bsonutil.BsonSet(update, "updated_at", timer.Now())
-
-	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
-	_, err = collection.UpdateOne(ctx, filter, update)
-	return err
+	data.UpdatedAt = timer.Now()
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"status":     status,
+		"updated_at": data.UpdatedAt,
+	})
+
+	updated, err := applyUpdate(ctx, data, filter, update)
+	if updated {
+		data.Status = status
+	}
+	return updated, err
 }
 
-// ApproveByManager xác nhận bởi quản lý
-func ApproveByManager(ctx context.Context, id string, managerID string, comment string) error {
-	objID, err := primitive.ObjectIDFromHex(id)
+// UpdateWorkflowState lưu kết quả chuyển bước duyệt và ghi lịch sử trong cùng một lần cập nhật.
+// Chỉ cập nhật khi đơn vẫn ở status fromStatus và version đã đọc, trả về false nếu đơn đã được người khác xử lý trước
+func UpdateWorkflowState(ctx context.Context, data *WorkConfirmation, fromStatus WorkConfirmationStatus, event *Event) (bool, error) {
+	filter, err := expectedState(data, fromStatus)
 	if err != nil {
-		return err
-	}
-
-	approval := ApprovalInfo{
-		ApprovedBy: managerID,
-		ApprovedAt: timer.Now(),
-		Comment:    comment,
+		return false, err
 	}
 
-	filter := bsonutil.BsonAdd(nil, "_id", objID)
-	update := bsonutil.BsonSet(nil, "manager_approval", approval)
-	update = bsonutil.BsonSet(update, "status", StatusPendingLeader)
-	update = bsonutil.BsonSet(update, "updated_at", timer.Now())
-
-	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
-	_, err = collection.UpdateOne(ctx, filter, update)
-	return err
-}
+	data.UpdatedAt = timer.Now()
 
-// ApproveByLeader xác nhận bởi lãnh đạo
-func ApproveByLeader(ctx context.Context, id string, leaderID string, comment string) error {
-	objID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
+	fields := bson.M{
+		"status":      data.Status,
+		"workflow_id": data.WorkflowID,
+		"stage_index": data.StageIndex,
+		"stages":      data.Stages,
+		"updated_at":  data.UpdatedAt,
 	}
-
-	approval := ApprovalInfo{
-		ApprovedBy: leaderID,
-		ApprovedAt: timer.Now(),
-		Comment:    comment,
+	if data.ManagerApproval != nil {
+		fields["manager_approval"] = data.ManagerApproval
 	}
-
-	filter := bsonutil.BsonAdd(nil, "_id", objID)
-	update := bsonutil.BsonSet(nil, "leader_approval", approval)
-	update = bsonutil.BsonSet(update, "status", StatusApproved)
-	update = bsonutil.BsonSet(update, "updated_at", timer.Now())
-
-	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
-	_, err = collection.UpdateOne(ctx, filter, update)
-	return err
-}
-
-// Reject từ chối đơn
-func Reject(ctx context.Context, id string, rejectedBy string, reason string) error {
-	objID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
+	if data.LeaderApproval != nil {
+		fields["leader_approval"] = data.LeaderApproval
 	}
-
-	rejection := RejectionInfo{
-		RejectedBy: rejectedBy,
-		RejectedAt: timer.Now(),
-		Reason:     reason,
+	if data.Rejection != nil {
+		fields["rejection"] = data.Rejection
 	}
+	if data.Cancellation != nil {
+		fields["cancellation"] = data.Cancellation
+	}
+	update := bsonutil.BsonSetMap(nil, fields)
+	update = bsonutil.BsonPush(update, "history", event)
 
-	filter := bsonutil.BsonAdd(nil, "_id", objID)
-	update := bsonutil.BsonSet(nil, "rejection", rejection)
-	update = bsonutil.BsonSet(update, "status", StatusRejected)
-	update = bsonutil.BsonSet(update, "updated_at", timer.Now())
-
-	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
-	_, err = collection.UpdateOne(ctx, filter, update)
-	return err
+	return applyUpdate(ctx, data, filter, update)
 }
 
-// SoftDelete xóa mềm đơn
-func SoftDelete(ctx context.Context, id string) error {
-	objID, err := primitive.ObjectIDFromHex(id)
+// SoftDelete xóa mềm đơn khi đơn vẫn ở status và version đã đọc,
+// trả về false nếu đơn đã bị người khác thay đổi trước
+func SoftDelete(ctx context.Context, data *WorkConfirmation) (bool, error) {
+	filter, err := expectedState(data, data.Status)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	filter := bsonutil.BsonAdd(nil, "_id", objID)
-	update := bsonutil.BsonSet(nil, "is_delete", true)
-	update = bsonutil.BsonSet(update, "deleted_at", timer.Now())
-	update = bsonutil.BsonSet(update, "updated_at", timer.Now())
+	now := timer.Now()
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_delete":  true,
+		"deleted_at": now,
+		"updated_at": now,
+	})
 
-	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
-	_, err = collection.UpdateOne(ctx, filter, update)
-	return err
+	return applyUpdate(ctx, data, filter, update)
 }
 
 // FindByIDs tìm nhiều đơn theo danh sách ID
@@ -252,6 +310,217 @@ func FindByIDs(ctx context.Context, ids []string) ([]*WorkConfirmation, error) {
 	return results, nil
 }
 
+// inactiveStatuses là các status không còn hiệu lực, bỏ qua khi kiểm tra trùng giờ
+var inactiveStatuses = bson.A{StatusRejected, StatusCancelled}
+
+// FindActiveByCreatorInRange tìm các đơn còn hiệu lực (chưa bị từ chối/huỷ) của người tạo
+// có thời gian giao với khoảng [start, end)
+func FindActiveByCreatorInRange(ctx context.Context, createdBy string, start time.Time, end time.Time) ([]*WorkConfirmation, error) {
+	filter := bsonutil.BsonAdd(nil, "created_by", createdBy)
+	filter = bsonutil.BsonLessThan(filter, "start_at", end)
+	filter = bsonutil.BsonGreaterThan(filter, "end_at", start)
+	filter = bsonutil.BsonNotIn(filter, "status", inactiveStatuses)
+
+	results, _, err := FindWithFilter(ctx, filter, options.Find().SetProjection(bson.M{"history": 0}))
+	return results, err
+}
+
+// FindActiveInRange tìm các đơn còn hiệu lực có thời gian giao với khoảng [start, end),
+// sắp xếp theo người tạo và thời điểm bắt đầu
+func FindActiveInRange(ctx context.Context, start time.Time, end time.Time) ([]*WorkConfirmation, error) {
+	filter := bsonutil.BsonLessThan(nil, "start_at", end)
+	filter = bsonutil.BsonGreaterThan(filter, "end_at", start)
+	filter = bsonutil.BsonNotIn(filter, "status", inactiveStatuses)
+
+	ops := options.Find().
+		SetProjection(bson.M{"history": 0}).
+		SetSort(bson.D{{Key: "created_by", Value: 1}, {Key: "start_at", Value: 1}})
+
+	results, _, err := FindWithFilter(ctx, filter, ops)
+	return results, err
+}
+
+// FindByPhotoHashes tìm các đơn còn hiệu lực khác excludeID có ảnh cùng một đoạn hash hoặc cùng sha256,
+// đơn tạo trước xếp trước
+func FindByPhotoHashes(ctx context.Context, bands []string, sums []string, excludeID string) ([]*WorkConfirmation, error) {
+	filter := bsonutil.BsonAdd(nil, "$or", bson.A{
+		bson.M{"photos.hash_bands": bson.M{"$in": bands}},
+		bson.M{"photos.sha256": bson.M{"$in": sums}},
+	})
+	if excludeID != "" {
+		objID, err := primitive.ObjectIDFromHex(excludeID)
+		if err != nil {
+			return nil, err
+		}
+		filter = bsonutil.BsonNotEqual(filter, "_id", objID)
+	}
+	filter = bsonutil.BsonNotIn(filter, "status", inactiveStatuses)
+
+	ops := options.Find().
+		SetProjection(bson.M{"created_by": 1, "created_at": 1, "status": 1, "photos": 1}).
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetLimit(100)
+
+	results, _, err := FindWithFilter(ctx, filter, ops)
+	return results, err
+}
+
+// FindForWorkTime tìm các đơn có thời gian giao với khoảng [start, end) để tính giờ công.
+// userIDs rỗng là tất cả người tạo. approvedOnly chỉ lấy đơn đã duyệt, ngược lại lấy mọi đơn còn hiệu lực
+func FindForWorkTime(ctx context.Context, start time.Time, end time.Time, userIDs []string, approvedOnly bool) ([]*WorkConfirmation, error) {
+	filter := bsonutil.BsonLessThan(nil, "start_at", end)
+	filter = bsonutil.BsonGreaterThan(filter, "end_at", start)
+	if userIDs != nil {
+		filter = bsonutil.BsonIn(filter, "created_by", userIDs)
+	}
+	if approvedOnly {
+		filter = bsonutil.BsonAdd(filter, "status", StatusApproved)
+	} else {
+		filter = bsonutil.BsonNotIn(filter, "status", inactiveStatuses)
+	}
+
+	ops := options.Find().SetProjection(bson.M{"history": 0, "photos": 0, "stages": 0})
+
+	results, _, err := FindWithFilter(ctx, filter, ops)
+	return results, err
+}
+
+// FindWithoutTimeSpan tìm tối đa limit đơn tạo trước khi có start_at/end_at, kể cả đơn đã xoá,
+// có _id lớn hơn afterID (rỗng là từ đầu), sắp xếp theo _id
+func FindWithoutTimeSpan(ctx context.Context, afterID string, limit int64) ([]*WorkConfirmation, error) {
+	filter := bsonutil.BsonAdd(nil, "start_at", bson.M{"$exists": false})
+	if afterID != "" {
+		objID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, err
+		}
+		filter = bsonutil.BsonGreaterThan(filter, "_id", objID)
+	}
+
+	ops := options.Find().
+		SetProjection(bson.M{"history": 0}).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(limit)
+
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
+	var results []*WorkConfirmation
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SetTimeSpan ghi start_at/end_at/timezone/days cho đơn cũ chưa có, không đổi version vì nội dung đơn không đổi
+func SetTimeSpan(ctx context.Context, data *WorkConfirmation) error {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "start_at", bson.M{"$exists": false})
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"start_at": data.StartAt,
+		"end_at":   data.EndAt,
+		"timezone": data.Timezone,
+		"days":     data.Days,
+		"end_date": data.EndDate,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// ReplaceStageApprover thay rule người duyệt from bằng to trong các bước đã lưu của mọi đơn, kể cả đơn đã xoá.
+// Không đổi version vì nội dung đơn không đổi
+func ReplaceStageApprover(ctx context.Context, from workflowcol.ApproverRule, to workflowcol.ApproverRule) (int64, error) {
+	match := bson.M{"approver.type": from.Type, "approver.role": from.Role}
+	filter := bsonutil.BsonAdd(nil, "stages", bson.M{"$elemMatch": match})
+
+	update := bsonutil.BsonSetMap(nil, bson.M{"stages.$[stage].approver": to})
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"stage.approver.type": from.Type, "stage.approver.role": from.Role}},
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
+	result, err := collection.UpdateMany(ctx, filter, update, opts)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// FillPhotoVariantURLs gán thumb_url/medium_url là url nối thêm thumbSuffix/mediumSuffix cho ảnh upload trước khi
+// có ảnh thu nhỏ, kể cả đơn đã xoá. Không đổi version vì nội dung đơn không đổi
+func FillPhotoVariantURLs(ctx context.Context, thumbSuffix string, mediumSuffix string) (int64, error) {
+	filter := bsonutil.BsonAdd(nil, "photos", bson.M{"$elemMatch": bson.M{"thumb_url": bson.M{"$exists": false}}})
+
+	// Update dạng pipeline để tính từ url của từng ảnh, giá trị đã có của ảnh được giữ nguyên
+	update := bson.A{bson.M{"$set": bson.M{"photos": bson.M{"$map": bson.M{
+		"input": "$photos",
+		"as":    "photo",
+		"in": bson.M{"$mergeObjects": bson.A{
+			bson.M{
+				"thumb_url":  bson.M{"$concat": bson.A{"$$photo.url", thumbSuffix}},
+				"medium_url": bson.M{"$concat": bson.A{"$$photo.url", mediumSuffix}},
+			},
+			"$$photo",
+		}},
+	}}}}}
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// expectedState tạo filter cập nhật có điều kiện: đúng đơn, chưa bị xoá, đang ở status và version đã đọc
+func expectedState(data *WorkConfirmation, status WorkConfirmationStatus) (primitive.D, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "status", status)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+	if data.Version == 0 {
+		// documents created before versioning have no version field
+		filter = bsonutil.BsonIn(filter, "version", bson.A{0, nil})
+	} else {
+		filter = bsonutil.BsonAdd(filter, "version", data.Version)
+	}
+
+	return filter, nil
+}
+
+// applyUpdate chạy cập nhật có điều kiện và tăng version, trả về false nếu không khớp đơn nào
+func applyUpdate(ctx context.Context, data *WorkConfirmation, filter primitive.D, update primitive.D) (bool, error) {
+	update = bsonutil.BsonIncrease(update, "version", 1)
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, nil
+	}
+
+	data.Version++
+	return true, nil
+}
+
 // Collection trả về collection
 func Collection() *mongodb.Collection {
 	return mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &WorkConfirmation{})
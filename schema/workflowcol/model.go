@@ -0,0 +1,62 @@
+package workflowcol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+	"api/schema/usercol"
+)
+
+type ApproverType string
+
+const (
+	ApproverDirectManager ApproverType = "direct_manager" // Quản lý trực tiếp của người tạo đơn
+	ApproverTeamLeader    ApproverType = "team_leader"    // Quản lý của team người tạo đơn thuộc về
+	ApproverRole          ApproverType = "role"           // Bất kỳ ai có vai trò chỉ định
+	ApproverPermission    ApproverType = "permission"     // Bất kỳ ai có vai trò mang quyền chỉ định
+	ApproverUser          ApproverType = "user"           // Một user cụ thể
+)
+
+// ApproverRule xác định ai được duyệt ở một bước
+type ApproverRule struct {
+	Type       ApproverType `json:"type" bson:"type"`
+	Role       usercol.Role `json:"role,omitempty" bson:"role,omitempty"`             // Khi type = role
+	Permission string       `json:"permission,omitempty" bson:"permission,omitempty"` // Khi type = permission
+	UserID     string       `json:"user_id,omitempty" bson:"user_id,omitempty"`       // Khi type = user
+}
+
+// SkipCondition xác định khi nào bỏ qua một bước
+type SkipCondition struct {
+	CreatorRoles []usercol.Role `json:"creator_roles,omitempty" bson:"creator_roles,omitempty"` // Bỏ qua khi người tạo có một trong các vai trò
+	NoApprover   bool           `json:"no_approver,omitempty" bson:"no_approver,omitempty"`     // Bỏ qua khi không xác định được người duyệt
+}
+
+// Stage là một bước duyệt. Đơn ở bước này có status pending_<key>
+type Stage struct {
+	Key      string        `json:"key" bson:"key"`   // Mã bước, vd: manager, hr, leader
+	Name     string        `json:"name" bson:"name"` // Tên hiển thị, vd: "Quản lý"
+	Approver ApproverRule  `json:"approver" bson:"approver"`
+	Skip     SkipCondition `json:"skip" bson:"skip"`
+}
+
+type Workflow struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	// Thông tin quy trình
+	Name        string   `json:"name" bson:"name"`
+	Description string   `json:"description" bson:"description"`
+	Stages      []Stage  `json:"stages" bson:"stages"`         // Các bước duyệt theo thứ tự
+	TeamIDs     []string `json:"team_ids" bson:"team_ids"`     // Team áp dụng, mỗi team chỉ thuộc một quy trình
+	IsDefault   bool     `json:"is_default" bson:"is_default"` // Áp dụng cho team chưa được gán quy trình
+	CreatedBy   string   `json:"created_by" bson:"created_by"`
+
+	// Soft delete
+	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
+	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (Workflow) CollectionName() string {
+	return "workflow"
+}
@@ -0,0 +1,206 @@
+package workflowcol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Create tạo quy trình mới
+func Create(ctx context.Context, data *Workflow) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+	data.IsDelete = false
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật quy trình
+func Update(ctx context.Context, data *Workflow) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil,
+		bsonutil.ConvertStructToBSONMap(
+			data,
+			&bsonutil.MappingOpts{RemoveID: true},
+		),
+	)
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Workflow{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindByID tìm quy trình theo ID
+func FindByID(ctx context.Context, id string) (*Workflow, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindByTeamID tìm quy trình được gán cho team
+func FindByTeamID(ctx context.Context, teamID string) (*Workflow, error) {
+	filter := bsonutil.BsonAdd(nil, "team_ids", teamID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindDefault tìm quy trình mặc định
+func FindDefault(ctx context.Context) (*Workflow, error) {
+	filter := bsonutil.BsonAdd(nil, "is_default", true)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// ReleaseTeams gỡ các team khỏi mọi quy trình khác exceptID, để mỗi team chỉ thuộc một quy trình
+func ReleaseTeams(ctx context.Context, exceptID string, teamIDs []string) error {
+	if len(teamIDs) == 0 {
+		return nil
+	}
+
+	objID, err := primitive.ObjectIDFromHex(exceptID)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonNotEqual(nil, "_id", objID)
+	filter = bsonutil.BsonIn(filter, "team_ids", teamIDs)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	update := bson.D{
+		{Key: "$pull", Value: bson.M{"team_ids": bson.M{"$in": teamIDs}}},
+		{Key: "$set", Value: bson.M{"updated_at": timer.Now()}},
+	}
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Workflow{})
+	_, err = collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// ClearDefault bỏ cờ mặc định của các quy trình khác exceptID
+func ClearDefault(ctx context.Context, exceptID string) error {
+	objID, err := primitive.ObjectIDFromHex(exceptID)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonNotEqual(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_default", true)
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_default": false,
+		"updated_at": timer.Now(),
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Workflow{})
+	_, err = collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// ReplaceApprover thay rule người duyệt from bằng to trong các bước của mọi quy trình
+func ReplaceApprover(ctx context.Context, from ApproverRule, to ApproverRule) (int64, error) {
+	match := bson.M{"approver.type": from.Type, "approver.role": from.Role}
+	filter := bsonutil.BsonAdd(nil, "stages", bson.M{"$elemMatch": match})
+
+	update := bsonutil.BsonSetMap(nil, bson.M{"stages.$[stage].approver": to})
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"stage.approver.type": from.Type, "stage.approver.role": from.Role}},
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Workflow{})
+	result, err := collection.UpdateMany(ctx, filter, update, opts)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// SoftDelete xoá mềm quy trình
+func SoftDelete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_delete":  true,
+		"is_default": false,
+		"team_ids":   []string{},
+		"deleted_at": timer.Now(),
+		"updated_at": timer.Now(),
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &Workflow{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindWithCondition tìm quy trình với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*Workflow, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Workflow{})
+
+	result := &Workflow{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindWithFilter tìm danh sách quy trình với filter và phân trang
+func FindWithFilter(ctx context.Context, filter primitive.D, ops *options.FindOptions) ([]*Workflow, int64, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &Workflow{})
+
+	// Thêm điều kiện không bị xóa
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	var results []*Workflow
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := coll.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
@@ -0,0 +1,27 @@
+package workpolicycol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+)
+
+// DefaultKey là khoá của chính sách giờ làm việc áp dụng cho toàn tổ chức
+const DefaultKey = "default"
+
+// WorkPolicy là chính sách giờ làm việc dùng để phân loại giờ công: trong giờ hành chính, tăng ca, cuối tuần
+type WorkPolicy struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	Key       string `json:"key" bson:"key"`               // Khoá chính sách, hiện chỉ có DefaultKey
+	WorkDays  []int  `json:"work_days" bson:"work_days"`   // Ngày làm việc trong tuần, 0 = Chủ nhật ... 6 = Thứ bảy
+	DayStart  string `json:"day_start" bson:"day_start"`   // HH:MM bắt đầu giờ hành chính
+	DayEnd    string `json:"day_end" bson:"day_end"`       // HH:MM kết thúc giờ hành chính
+	UpdatedBy string `json:"updated_by" bson:"updated_by"` // user_id người sửa gần nhất
+}
+
+func (WorkPolicy) CollectionName() string {
+	return "work_policy"
+}
@@ -0,0 +1,51 @@
+package workpolicycol
+
+import (
+	"api/internal/mongodb"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindByKey tìm chính sách giờ làm việc theo khoá
+func FindByKey(ctx context.Context, key string) (*WorkPolicy, error) {
+	filter := bsonutil.BsonAdd(nil, "key", key)
+
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &WorkPolicy{})
+
+	result := &WorkPolicy{}
+	if err := coll.FirstWithCtx(ctx, filter, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Upsert tạo hoặc cập nhật chính sách giờ làm việc theo khoá
+func Upsert(ctx context.Context, data *WorkPolicy) error {
+	filter := bsonutil.BsonAdd(nil, "key", data.Key)
+
+	now := timer.Now()
+	data.UpdatedAt = now
+	update := bson.M{
+		"$set": bson.M{
+			"work_days":  data.WorkDays,
+			"day_start":  data.DayStart,
+			"day_end":    data.DayEnd,
+			"updated_by": data.UpdatedBy,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"key":        data.Key,
+			"created_at": now,
+		},
+	}
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkPolicy{})
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,30 @@
+package worksitecol
+
+import (
+	"time"
+
+	"api/internal/mongodb"
+	"api/internal/mongodb/field"
+)
+
+// WorkSite là địa điểm làm việc dùng để đối chiếu vị trí check-in của đơn.
+// Vùng là GeoJSON Polygon, hoặc GeoJSON Point kèm bán kính
+type WorkSite struct {
+	mongodb.DefaultModel `json:",inline" bson:",inline,omitnested"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+
+	Name         string         `json:"name" bson:"name"`
+	Address      string         `json:"address" bson:"address"`
+	Area         field.Geometry `json:"area" bson:"area"`                                       // Point hoặc Polygon, toạ độ [longitude, latitude]
+	RadiusMeters float64        `json:"radius_meters,omitempty" bson:"radius_meters,omitempty"` // Bán kính (mét) khi area là Point
+	CreatedBy    string         `json:"created_by" bson:"created_by"`                           // user_id người tạo
+
+	// Soft delete
+	IsDelete  bool      `json:"is_delete,omitempty" bson:"is_delete"`
+	DeletedAt time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (WorkSite) CollectionName() string {
+	return "work_site"
+}
@@ -0,0 +1,180 @@
+package worksitecol
+
+import (
+	"api/internal/mongodb"
+	"api/internal/mongodb/field"
+	bsonutil "api/internal/mongodb/utils"
+	"api/internal/timer"
+	"context"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes tạo index 2dsphere cho vùng của địa điểm
+func EnsureIndexes(ctx context.Context) error {
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkSite{})
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "area", Value: "2dsphere"}},
+	})
+	return err
+}
+
+// Create tạo địa điểm mới
+func Create(ctx context.Context, data *WorkSite) (interface{}, error) {
+	coll := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), data)
+
+	data.CreatedAt = timer.Now()
+	data.UpdatedAt = timer.Now()
+	data.IsDelete = false
+
+	id, err := coll.CreateWithCtx(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// Update cập nhật địa điểm
+func Update(ctx context.Context, data *WorkSite) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(data.GetIDString())
+	if err != nil {
+		return false, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	data.UpdatedAt = timer.Now()
+
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"name":          data.Name,
+		"address":       data.Address,
+		"area":          data.Area,
+		"radius_meters": data.RadiusMeters,
+		"updated_at":    data.UpdatedAt,
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkSite{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SoftDelete xoá mềm địa điểm
+func SoftDelete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	update := bsonutil.BsonSetMap(nil, bson.M{
+		"is_delete":  true,
+		"deleted_at": timer.Now(),
+		"updated_at": timer.Now(),
+	})
+
+	collection := mongodb.Coll(os.Getenv("MONGODB_DATABASE"), &WorkSite{})
+	_, err = collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindByID tìm địa điểm theo ID
+func FindByID(ctx context.Context, id string) (*WorkSite, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bsonutil.BsonAdd(nil, "_id", objID)
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	return FindWithCondition(ctx, filter)
+}
+
+// FindContaining tìm các địa điểm dạng Polygon chứa điểm
+func FindContaining(ctx context.Context, point *field.Geometry) ([]*WorkSite, error) {
+	filter := bsonutil.BsonAdd(nil, "area", bson.M{"$geoIntersects": bson.M{"$geometry": point}})
+	filter = bsonutil.BsonAdd(filter, "area.type", field.Polygon)
+
+	results, _, err := FindWithFilter(ctx, filter, options.Find().SetSort(primitive.D{{Key: "name", Value: 1}}))
+	return results, err
+}
+
+// SiteDistance là địa điểm kèm khoảng cách (mét) tới một điểm
+type SiteDistance struct {
+	WorkSite `bson:",inline"`
+	Distance float64 `json:"distance" bson:"distance"`
+}
+
+// FindCovering tìm các địa điểm dạng Point có bán kính bao trùm điểm, gần nhất trước.
+// maxDistance (mét) giới hạn phạm vi tìm, nên là bán kính lớn nhất cho phép
+func FindCovering(ctx context.Context, point *field.Geometry, maxDistance float64) ([]*SiteDistance, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          point,
+			"distanceField": "distance",
+			"maxDistance":   maxDistance,
+			"spherical":     true,
+			"query":         bson.M{"area.type": field.Point, "is_delete": false},
+		}}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$lte": bson.A{"$distance", "$radius_meters"}}}}},
+	}
+
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &WorkSite{})
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*SiteDistance
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindWithCondition tìm địa điểm với điều kiện
+func FindWithCondition(ctx context.Context, filter interface{}, findOptions ...*options.FindOneOptions) (*WorkSite, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &WorkSite{})
+
+	result := &WorkSite{}
+	if err := coll.FirstWithCtx(ctx, filter, result, findOptions...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindWithFilter tìm danh sách địa điểm với filter và phân trang
+func FindWithFilter(ctx context.Context, filter primitive.D, ops *options.FindOptions) ([]*WorkSite, int64, error) {
+	coll := mongodb.CollRead(os.Getenv("MONGODB_DATABASE"), &WorkSite{})
+
+	// Thêm điều kiện không bị xóa
+	filter = bsonutil.BsonAdd(filter, "is_delete", false)
+
+	var results []*WorkSite
+	cursor, err := coll.Find(ctx, filter, ops)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := coll.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
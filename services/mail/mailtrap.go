@@ -0,0 +1,103 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const mailtrapSendURL = "https://send.api.mailtrap.io/api/send"
+
+type Sender struct {
+	Token     string
+	FromEmail string
+	FromName  string
+	client    *http.Client
+}
+
+type ConfigOptions struct {
+	Token     string
+	FromEmail string
+	FromName  string
+}
+
+type address struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendRequest struct {
+	From     address   `json:"from"`
+	To       []address `json:"to"`
+	Subject  string    `json:"subject"`
+	Text     string    `json:"text"`
+	Category string    `json:"category,omitempty"`
+}
+
+var MailSender *Sender
+
+func Config(config *ConfigOptions) (*Sender, error) {
+	if MailSender != nil {
+		return MailSender, nil
+	}
+
+	if config.Token == "" {
+		return nil, errors.New("token is required")
+	}
+
+	if config.FromEmail == "" {
+		return nil, errors.New("from_email is required")
+	}
+
+	MailSender = &Sender{
+		Token:     config.Token,
+		FromEmail: config.FromEmail,
+		FromName:  config.FromName,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+
+	return MailSender, nil
+}
+
+// Send gửi email dạng text qua Mailtrap
+func Send(ctx context.Context, to, subject, text, category string) error {
+	if MailSender == nil {
+		return errors.New("mail sender is not configured")
+	}
+
+	body, err := json.Marshal(sendRequest{
+		From:     address{Email: MailSender.FromEmail, Name: MailSender.FromName},
+		To:       []address{{Email: to}},
+		Subject:  subject,
+		Text:     text,
+		Category: category,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, mailtrapSendURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+MailSender.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := MailSender.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("mailtrap returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
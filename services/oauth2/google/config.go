@@ -10,7 +10,6 @@ import (
 type OAuthProvider struct {
 	*oauth2.Config
 	ClientRedirectURL string
-	OAuthStateString  string
 }
 
 type ConfigOptions struct {
@@ -18,7 +17,6 @@ type ConfigOptions struct {
 	ClientSecret      string
 	RedirectURL       string
 	ClientRedirectURL string
-	OAuthStateString  string
 }
 
 var OAuthConfig *OAuthProvider
@@ -56,7 +54,6 @@ func Config(config *ConfigOptions) (*OAuthProvider, error) {
 			Endpoint: google.Endpoint,
 		},
 		ClientRedirectURL: config.ClientRedirectURL,
-		OAuthStateString:  config.OAuthStateString,
 	}
 
 	return OAuthConfig, nil
@@ -0,0 +1,263 @@
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	xoauth2 "golang.org/x/oauth2"
+)
+
+const wellKnownSuffix = "/.well-known/openid-configuration"
+
+// ClaimMapping chỉ định tên claim trong ID token tương ứng với từng thông tin user
+type ClaimMapping struct {
+	Subject       string
+	Email         string
+	EmailVerified string
+	Name          string
+	Picture       string
+}
+
+// OIDCOptions cấu hình một provider OpenID Connect
+type OIDCOptions struct {
+	Name              Provider
+	DiscoveryURL      string
+	ClientID          string
+	ClientSecret      string
+	RedirectURL       string
+	ClientRedirectURL string
+	Scopes            []string
+	Claims            ClaimMapping
+}
+
+// OIDCProvider là provider đã được khởi tạo từ discovery document
+type OIDCProvider struct {
+	Name              Provider
+	ClientRedirectURL string
+	Config            *xoauth2.Config
+
+	claims   ClaimMapping
+	verifier *oidc.IDTokenVerifier
+}
+
+// Identity là thông tin user đã được xác thực bởi provider
+type Identity struct {
+	Provider      Provider
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+type registryEntry struct {
+	options  *OIDCOptions
+	provider *OIDCProvider
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[Provider]*registryEntry{}
+)
+
+// RegisterOIDC thêm provider vào registry. Discovery chạy lần đầu provider được dùng
+// để server vẫn khởi động được khi provider tạm thời không truy cập được
+func RegisterOIDC(options *OIDCOptions) error {
+	if options.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if options.DiscoveryURL == "" {
+		return errors.New("discovery_url is required")
+	}
+
+	if options.ClientID == "" {
+		return errors.New("client_id is required")
+	}
+
+	if options.RedirectURL == "" {
+		return errors.New("redirect_url is required")
+	}
+
+	if options.ClientRedirectURL == "" {
+		return errors.New("client_redirect_url is required")
+	}
+
+	if len(options.Scopes) == 0 {
+		options.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+
+	options.Claims = withDefaultClaims(options.Claims)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[options.Name] = &registryEntry{options: options}
+
+	return nil
+}
+
+// GetOIDCProvider lấy provider theo tên, thực hiện discovery nếu chưa có
+func GetOIDCProvider(ctx context.Context, name string) (*OIDCProvider, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	entry, ok := registry[Provider(name)]
+	if !ok {
+		return nil, errors.New("OAUTH_PROVIDER_NOT_FOUND")
+	}
+
+	if entry.provider != nil {
+		return entry.provider, nil
+	}
+
+	opts := entry.options
+	issuer := strings.TrimSuffix(strings.TrimRight(opts.DiscoveryURL, "/"), wellKnownSuffix)
+
+	discovered, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("SERVICE_UNAVAILABLE: oidc discovery failed for %s: %w", name, err)
+	}
+
+	entry.provider = &OIDCProvider{
+		Name:              opts.Name,
+		ClientRedirectURL: opts.ClientRedirectURL,
+		Config: &xoauth2.Config{
+			ClientID:     opts.ClientID,
+			ClientSecret: opts.ClientSecret,
+			RedirectURL:  opts.RedirectURL,
+			Scopes:       opts.Scopes,
+			Endpoint:     discovered.Endpoint(),
+		},
+		claims:   opts.Claims,
+		verifier: discovered.Verifier(&oidc.Config{ClientID: opts.ClientID}),
+	}
+
+	return entry.provider, nil
+}
+
+// Exchange đổi authorization code lấy ID token, xác thực chữ ký qua JWKS, nonce và map claim sang Identity
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	token, err := p.Config.Exchange(ctx, code, xoauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange token: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("id_token is missing from token response")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err = idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+
+	identity := &Identity{
+		Provider:      p.Name,
+		Subject:       claimString(claims, p.claims.Subject),
+		Email:         strings.ToLower(claimString(claims, p.claims.Email)),
+		EmailVerified: claimBool(claims, p.claims.EmailVerified),
+		Name:          claimString(claims, p.claims.Name),
+		Picture:       claimString(claims, p.claims.Picture),
+	}
+
+	if identity.Subject == "" {
+		identity.Subject = idToken.Subject
+	}
+
+	return identity, nil
+}
+
+// LoadOIDCProvidersFromEnv đăng ký các provider liệt kê trong OIDC_PROVIDERS (vd: "entra,keycloak").
+// Mỗi provider đọc cấu hình từ OIDC_<NAME>_*
+func LoadOIDCProvidersFromEnv() []error {
+	var errs []error
+
+	for _, name := range splitList(os.Getenv("OIDC_PROVIDERS")) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		err := RegisterOIDC(&OIDCOptions{
+			Name:              Provider(name),
+			DiscoveryURL:      os.Getenv(prefix + "DISCOVERY_URL"),
+			ClientID:          os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret:      os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:       os.Getenv(prefix + "REDIRECT_URL"),
+			ClientRedirectURL: os.Getenv(prefix + "CLIENT_REDIRECT_URL"),
+			Scopes:            splitList(os.Getenv(prefix + "SCOPES")),
+			Claims: ClaimMapping{
+				Subject:       os.Getenv(prefix + "CLAIM_SUBJECT"),
+				Email:         os.Getenv(prefix + "CLAIM_EMAIL"),
+				EmailVerified: os.Getenv(prefix + "CLAIM_EMAIL_VERIFIED"),
+				Name:          os.Getenv(prefix + "CLAIM_NAME"),
+				Picture:       os.Getenv(prefix + "CLAIM_PICTURE"),
+			},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("oidc provider %s: %w", name, err))
+		}
+	}
+
+	return errs
+}
+
+func withDefaultClaims(c ClaimMapping) ClaimMapping {
+	if c.Subject == "" {
+		c.Subject = "sub"
+	}
+	if c.Email == "" {
+		c.Email = "email"
+	}
+	if c.EmailVerified == "" {
+		c.EmailVerified = "email_verified"
+	}
+	if c.Name == "" {
+		c.Name = "name"
+	}
+	if c.Picture == "" {
+		c.Picture = "picture"
+	}
+	return c
+}
+
+func claimString(claims map[string]interface{}, key string) string {
+	if v, ok := claims[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// claimBool chấp nhận cả bool và chuỗi "true" (một số provider trả về dạng chuỗi)
+func claimBool(claims map[string]interface{}, key string) bool {
+	switch v := claims[key].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}